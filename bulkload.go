@@ -0,0 +1,169 @@
+package odoorpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultLoadChunkSize is the number of rows per load call when
+// BulkLoadOptions.ChunkSize is not set.
+const DefaultLoadChunkSize = 1000
+
+// BulkLoadOptions configure BulkLoad.
+type BulkLoadOptions struct {
+	// ChunkSize is the number of rows per load call. Defaults to
+	// DefaultLoadChunkSize. Rows that make up a single record, such as the
+	// lines of a one2many, must not be split across chunks.
+	ChunkSize int
+	// Concurrency is the maximum number of load calls in flight. Defaults
+	// to 1.
+	Concurrency int
+	// Progress, when set, is called after each chunk completes. Calls are
+	// serialized but may come from different goroutines.
+	Progress func(LoadProgress)
+}
+
+// LoadProgress reports the progress of a BulkLoad.
+type LoadProgress struct {
+	// Rows is the number of rows processed so far, saved or not.
+	Rows int
+	// Total is the number of rows to load.
+	Total int
+	// Errors is the number of error messages reported so far.
+	Errors int
+	// Elapsed is the time since the load started.
+	Elapsed time.Duration
+	// ETA is the estimated time until the remaining rows are processed, at
+	// the rate observed so far.
+	ETA time.Duration
+}
+
+// BulkLoad loads values into model in chunks of rows, running up to
+// opts.Concurrency load calls at a time. Each chunk is saved or rejected on
+// its own: the result holds the ids of the saved chunks in input order and
+// the messages of all chunks, with rows numbered from the start of values.
+// The ids of rejected chunks are missing, so IDs[i] is not the record of
+// row i once a chunk is rejected; neither is it when records span several
+// rows. When the server stops early within a chunk, as reported by
+// LoadResult.NextRow, the rest of the chunk is loaded again; the rows saved
+// before the rest is rejected stay saved.
+//
+// When any chunk is rejected, the error is a *LoadError with all the
+// messages. A failed call stops the load and returns its error along with
+// what was saved so far.
+func BulkLoad(ctx context.Context, o Odoo, model string, header []string, values [][]any, opts BulkLoadOptions) (LoadResult, error) {
+	size := opts.ChunkSize
+	if size <= 0 {
+		size = DefaultLoadChunkSize
+	}
+	workers := max(opts.Concurrency, 1)
+	n := (len(values) + size - 1) / size
+	results := make([]LoadResult, n)
+	rejected := make([]bool, n)
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		callErr  error
+		progress = LoadProgress{Total: len(values)}
+		start    = time.Now()
+		wg       sync.WaitGroup
+	)
+	chunks := make(chan int)
+	for range min(workers, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range chunks {
+				from, to := i*size, min((i+1)*size, len(values))
+				res, err := loadChunk(ctx, o, model, header, values[from:to], from)
+				var loadErr *LoadError
+				if err != nil && !errors.As(err, &loadErr) {
+					mu.Lock()
+					if callErr == nil {
+						callErr = err
+					}
+					mu.Unlock()
+					cancel()
+					continue
+				}
+				results[i], rejected[i] = res, loadErr != nil
+
+				mu.Lock()
+				progress.Rows += to - from
+				for _, m := range res.Messages {
+					if m.Type == "error" {
+						progress.Errors++
+					}
+				}
+				progress.Elapsed = time.Since(start)
+				progress.ETA = progress.Elapsed * time.Duration(progress.Total-progress.Rows) / time.Duration(progress.Rows)
+				if opts.Progress != nil {
+					opts.Progress(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for i := range n {
+		select {
+		case chunks <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(chunks)
+	wg.Wait()
+
+	result := LoadResult{IDs: []int{}}
+	var failed bool
+	for i, res := range results {
+		failed = failed || rejected[i]
+		result.IDs = append(result.IDs, res.IDs...)
+		result.Messages = append(result.Messages, res.Messages...)
+	}
+	switch {
+	case callErr != nil:
+		return result, callErr
+	case parent.Err() != nil:
+		return result, fmt.Errorf("bulk load failed: %w", parent.Err())
+	case failed:
+		return result, &LoadError{Messages: result.Messages}
+	}
+	return result, nil
+}
+
+// loadChunk loads the rows of a chunk starting at row from of the input. When
+// the server stops early, the rows from NextRow on are submitted again.
+// Message rows are numbered from the start of the input.
+func loadChunk(ctx context.Context, o Odoo, model string, header []string, rows [][]any, from int) (LoadResult, error) {
+	var result LoadResult
+	for done := 0; done < len(rows); {
+		res, err := o.Load(ctx, model, header, rows[done:])
+		for j := range res.Messages {
+			if res.Messages[j].Row >= 0 {
+				res.Messages[j].Row += from + done
+			}
+		}
+		result.IDs = append(result.IDs, res.IDs...)
+		result.Messages = append(result.Messages, res.Messages...)
+		var loadErr *LoadError
+		switch {
+		case errors.As(err, &loadErr):
+			return result, &LoadError{Messages: result.Messages}
+		case err != nil:
+			return result, fmt.Errorf("bulk load failed at row %d: %w", from+done, err)
+		case res.NextRow <= 0 || res.NextRow >= len(rows)-done:
+			return result, nil
+		}
+		done += res.NextRow
+	}
+	return result, nil
+}
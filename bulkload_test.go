@@ -0,0 +1,156 @@
+package odoorpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+func TestBulkLoad(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name": {Type: "char", Required: true},
+		"age":  {Type: "integer"},
+	})
+
+	values := make([][]any, 25)
+	for i := range values {
+		values[i] = []any{fmt.Sprintf("P%02d", i), fmt.Sprint(i)}
+	}
+	values[10][1] = "ten"
+
+	for name, o := range interceptedClients(t, srv) {
+		var calls []odoorpc.LoadProgress
+		res, err := odoorpc.BulkLoad(ctx, o, "res.partner", []string{"name", "age"}, values, odoorpc.BulkLoadOptions{
+			ChunkSize:   4,
+			Concurrency: 3,
+			Progress:    func(p odoorpc.LoadProgress) { calls = append(calls, p) },
+		})
+		var loadErr *odoorpc.LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("%s: expected a *LoadError, got %v", name, err)
+		}
+		if len(res.Messages) != 1 || res.Messages[0].Row != 10 || res.Messages[0].Field != "age" {
+			t.Errorf("%s: messages %+v", name, res.Messages)
+		}
+
+		// The chunk of rows 8 to 11 is rejected; the others are saved.
+		if len(res.IDs) != 21 {
+			t.Fatalf("%s: got %d ids, want 21", name, len(res.IDs))
+		}
+		recs, err := o.Read(ctx, "res.partner", res.IDs, "name")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want []string
+		for i, v := range values {
+			if i < 8 || i > 11 {
+				want = append(want, v[0].(string))
+			}
+		}
+		for i, rec := range recs {
+			if rec["name"] != want[i] {
+				t.Fatalf("%s: record %d is %v, want %s", name, i, rec["name"], want[i])
+			}
+		}
+
+		if len(calls) != 7 {
+			t.Fatalf("%s: got %d progress calls, want 7", name, len(calls))
+		}
+		last := calls[len(calls)-1]
+		if last.Rows != 25 || last.Total != 25 || last.Errors != 1 || last.ETA != 0 {
+			t.Errorf("%s: last progress %+v", name, last)
+		}
+		for i := 1; i < len(calls); i++ {
+			if calls[i].Rows <= calls[i-1].Rows {
+				t.Errorf("%s: progress not increasing: %+v", name, calls)
+			}
+		}
+	}
+}
+
+func TestBulkLoadCanceled(t *testing.T) {
+	t.Parallel()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	o := interceptedClients(t, srv)[odoorpc.TransportJSONRPC]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	values := [][]any{{"A"}, {"B"}, {"C"}, {"D"}}
+	res, err := odoorpc.BulkLoad(ctx, o, "res.partner", []string{"name"}, values, odoorpc.BulkLoadOptions{
+		ChunkSize: 1,
+		Progress: func(p odoorpc.LoadProgress) {
+			if p.Rows == 2 {
+				cancel()
+			}
+		},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(res.IDs) != 2 {
+		t.Errorf("got ids %v, want the first 2 rows saved", res.IDs)
+	}
+}
+
+// stoppingLoader is a client whose server stops load after limit rows, like
+// Odoo with an import limit.
+type stoppingLoader struct {
+	odoorpc.Odoo
+	limit int
+}
+
+func (l stoppingLoader) Load(ctx context.Context, model string, header []string, values [][]any) (odoorpc.LoadResult, error) {
+	if len(values) <= l.limit {
+		return l.Odoo.Load(ctx, model, header, values)
+	}
+	res, err := l.Odoo.Load(ctx, model, header, values[:l.limit])
+	res.NextRow = l.limit
+	return res, err
+}
+
+func TestBulkLoadNextRow(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name": {Type: "char", Required: true},
+		"age":  {Type: "integer"},
+	})
+
+	values := make([][]any, 10)
+	for i := range values {
+		values[i] = []any{fmt.Sprintf("P%02d", i), fmt.Sprint(i)}
+	}
+	values[7][1] = "seven"
+
+	o := stoppingLoader{Odoo: interceptedClients(t, srv)[odoorpc.TransportJSONRPC], limit: 2}
+	res, err := odoorpc.BulkLoad(ctx, o, "res.partner", []string{"name", "age"}, values, odoorpc.BulkLoadOptions{ChunkSize: 5})
+	var loadErr *odoorpc.LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected a *LoadError, got %v", err)
+	}
+	if len(res.Messages) != 1 || res.Messages[0].Row != 7 {
+		t.Errorf("messages %+v", res.Messages)
+	}
+	// The second chunk stops at row 7, after saving rows 5 and 6.
+	recs, err := o.Read(ctx, "res.partner", res.IDs, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []any
+	for _, rec := range recs {
+		names = append(names, rec["name"])
+	}
+	if want := []any{"P00", "P01", "P02", "P03", "P04", "P05", "P06"}; fmt.Sprint(names) != fmt.Sprint(want) {
+		t.Errorf("saved %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,401 @@
+// Package cassette provides an http.RoundTripper that records Odoo RPC
+// traffic to a file and replays it later, so tests can run deterministically
+// against traffic captured once from a real server.
+//
+// The recorder understands the JSON-RPC, XML-RPC and JSON-2 protocols and
+// stores each call by service, model, method and arguments. Credentials
+// (passwords in login and object calls, Authorization headers and API keys)
+// are never written to the cassette. In replay mode requests are matched on
+// the same key, and a request that was not recorded fails with an error that
+// names the call.
+//
+//	rec, err := cassette.New("testdata/partners.json", cassette.ModeRecord, nil)
+//	...
+//	o := odoojrpc.NewOdoo().WithTransport(rec)
+//	...
+//	err = rec.Save()
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ppreeper/odoorpc/xmlrpc"
+)
+
+// Mode selects whether a Recorder records or replays.
+type Mode int
+
+const (
+	// ModeRecord forwards requests to the real server and records them.
+	ModeRecord Mode = iota
+	// ModeReplay serves responses from the cassette without any network
+	// access.
+	ModeReplay
+)
+
+// redacted replaces credentials in recorded requests.
+const redacted = "[REDACTED]"
+
+// maxBodyBytes bounds the size of request and response bodies the recorder
+// reads; larger bodies fail the request rather than being recorded cut.
+const maxBodyBytes = 32 << 20 // 32 MiB
+
+// defaultRedactKeys are argument map keys whose values are always redacted.
+var defaultRedactKeys = []string{"password", "new_password", "api_key", "apikey"}
+
+// ErrNoInteraction is returned, wrapped, when a replayed request has no
+// matching recorded interaction.
+var ErrNoInteraction = errors.New("cassette: no matching interaction")
+
+// Cassette is the on-disk format of a recording.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request identifies a recorded call. Credentials are stripped from Args.
+type Request struct {
+	Transport string          `json:"transport"`
+	Service   string          `json:"service,omitempty"`
+	Model     string          `json:"model,omitempty"`
+	Method    string          `json:"method"`
+	Args      json.RawMessage `json:"args,omitempty"`
+}
+
+// String returns a short description of the request for error messages.
+func (r Request) String() string {
+	target := r.Method
+	if r.Model != "" {
+		target = r.Model + "." + r.Method
+	}
+	if r.Service != "" {
+		target = r.Service + "/" + target
+	}
+	return fmt.Sprintf("%s %s args=%s", r.Transport, target, r.Args)
+}
+
+// key returns the value requests are matched on.
+func (r Request) key() string {
+	return r.Transport + "\x00" + r.Service + "\x00" + r.Model + "\x00" + r.Method + "\x00" + string(r.Args)
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that records or replays Odoo RPC traffic.
+// It is safe for concurrent use.
+type Recorder struct {
+	mode       Mode
+	path       string
+	next       http.RoundTripper
+	redactKeys []string
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New returns a Recorder for the cassette at path. In ModeRecord requests are
+// sent through next (http.DefaultTransport when nil) and the cassette is
+// written by Save. In ModeReplay the cassette is loaded from path.
+func New(path string, mode Mode, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{
+		mode:       mode,
+		path:       path,
+		next:       next,
+		redactKeys: defaultRedactKeys,
+	}
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("cassette: decode %s: %w", path, err)
+		}
+		// Save indents the arguments; compact them again so they compare
+		// equal to the keys computed from live requests.
+		for i := range r.cassette.Interactions {
+			req := &r.cassette.Interactions[i].Request
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, req.Args); err == nil {
+				req.Args = buf.Bytes()
+			}
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// WithRedactKeys adds argument map keys whose values are redacted, in
+// addition to the built-in password and API key names. Keys match
+// regardless of case. It must be called before the first request and
+// applies to both recording and matching.
+func (r *Recorder) WithRedactKeys(keys ...string) *Recorder {
+	r.redactKeys = slices.Clone(r.redactKeys)
+	for _, k := range keys {
+		r.redactKeys = append(r.redactKeys, strings.ToLower(k))
+	}
+	return r
+}
+
+// Save writes the recorded interactions to the cassette path.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("cassette: encode: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	return nil
+}
+
+// Unused returns the recorded interactions that were not replayed, which
+// tests can use to assert that every recorded call was made.
+func (r *Recorder) Unused() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Request
+	for i, used := range r.used {
+		if !used {
+			out = append(out, r.cassette.Interactions[i].Request)
+		}
+	}
+	return out
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = readBody(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cassette: read request: %w", err)
+		}
+	}
+	call, err := r.describe(req, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, call)
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	resp, err := r.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: read response: %w", err)
+	}
+
+	rec := Response{
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(respBody),
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{Request: call, Response: rec})
+	r.mu.Unlock()
+
+	return newResponse(req, rec), nil
+}
+
+// readBody reads a body of at most maxBodyBytes.
+func readBody(r io.Reader) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxBodyBytes+1))
+	if err == nil && len(body) > maxBodyBytes {
+		err = fmt.Errorf("body larger than %d bytes", maxBodyBytes)
+	}
+	return body, err
+}
+
+// replay returns the first unused interaction matching call.
+func (r *Recorder) replay(req *http.Request, call Request) (*http.Response, error) {
+	key := call.key()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := 0
+	for i, it := range r.cassette.Interactions {
+		if it.Request.key() != key {
+			continue
+		}
+		matched++
+		if r.used[i] {
+			continue
+		}
+		r.used[i] = true
+		return newResponse(req, it.Response), nil
+	}
+	if matched > 0 {
+		return nil, fmt.Errorf("%w for %s: all %d recorded interactions already replayed", ErrNoInteraction, call, matched)
+	}
+	return nil, fmt.Errorf("%w for %s", ErrNoInteraction, call)
+}
+
+// newResponse builds an *http.Response from a recorded response.
+func newResponse(req *http.Request, rec Response) *http.Response {
+	header := http.Header{}
+	if rec.ContentType != "" {
+		header.Set("Content-Type", rec.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}
+}
+
+// describe extracts the matching key of an RPC request, with credentials
+// removed.
+func (r *Recorder) describe(req *http.Request, body []byte) (Request, error) {
+	path := req.URL.Path
+	switch {
+	case strings.Contains(path, "/json/2/"):
+		_, rest, _ := strings.Cut(path, "/json/2/")
+		model, method, _ := strings.Cut(strings.Trim(rest, "/"), "/")
+		var kwargs map[string]any
+		if len(bytes.TrimSpace(body)) > 0 {
+			if err := json.Unmarshal(body, &kwargs); err != nil {
+				return Request{}, fmt.Errorf("cassette: decode JSON-2 request: %w", err)
+			}
+		}
+		return r.request("json2", "", model, method, kwargs)
+
+	case strings.Contains(path, "/xmlrpc/2/"):
+		_, service, _ := strings.Cut(path, "/xmlrpc/2/")
+		method, params, err := xmlrpc.DecodeMethodCall(body)
+		if err != nil {
+			return Request{}, fmt.Errorf("cassette: decode XML-RPC request: %w", err)
+		}
+		return r.serviceRequest("xmlrpc", strings.Trim(service, "/"), method, params)
+
+	case strings.HasSuffix(strings.TrimSuffix(path, "/"), "/jsonrpc"):
+		var rpc struct {
+			Params struct {
+				Service string `json:"service"`
+				Method  string `json:"method"`
+				Args    []any  `json:"args"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(body, &rpc); err != nil {
+			return Request{}, fmt.Errorf("cassette: decode JSON-RPC request: %w", err)
+		}
+		return r.serviceRequest("jsonrpc", rpc.Params.Service, rpc.Params.Method, rpc.Params.Args)
+	}
+	return Request{}, fmt.Errorf("cassette: unrecognised Odoo endpoint %s", path)
+}
+
+// serviceRequest describes a JSON-RPC or XML-RPC service call. Object calls
+// drop the leading (db, uid, password) triple and are keyed by model and
+// method; login calls keep the database and login but not the password, and
+// database management calls drop the master password and the passwords they
+// set.
+func (r *Recorder) serviceRequest(transport, service, method string, params []any) (Request, error) {
+	if service == "object" && (method == "execute" || method == "execute_kw") && len(params) >= 5 {
+		model, _ := params[3].(string)
+		name, _ := params[4].(string)
+		return r.request(transport, service, model, name, params[5:])
+	}
+	var secrets []int
+	switch {
+	case service == "common" && (method == "login" || method == "authenticate"):
+		secrets = []int{2}
+	case service == "db" && method == "create_database":
+		// master_pwd, db_name, demo, lang, user_password, ...
+		secrets = []int{0, 4}
+	case service == "db" && method == "change_admin_password":
+		secrets = []int{0, 1}
+	case service == "db" && slices.Contains(masterPasswordMethods, method):
+		secrets = []int{0}
+	}
+	if len(secrets) > 0 {
+		params = slices.Clone(params)
+	}
+	for _, i := range secrets {
+		if i < len(params) {
+			params[i] = redacted
+		}
+	}
+	return r.request(transport, service, "", method, params)
+}
+
+// masterPasswordMethods are the methods of the db service taking the master
+// password as their first argument, besides create_database and
+// change_admin_password.
+var masterPasswordMethods = []string{
+	"duplicate_database", "drop", "dump", "restore", "rename", "migrate_databases",
+}
+
+// request builds a Request with redacted, canonically encoded arguments.
+func (r *Recorder) request(transport, service, model, method string, args any) (Request, error) {
+	data, err := json.Marshal(r.redact(args))
+	if err != nil {
+		return Request{}, fmt.Errorf("cassette: encode args: %w", err)
+	}
+	return Request{Transport: transport, Service: service, Model: model, Method: method, Args: data}, nil
+}
+
+// redact replaces the values of credential keys anywhere in v.
+func (r *Recorder) redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, x := range v {
+			if slices.Contains(r.redactKeys, strings.ToLower(k)) {
+				out[k] = redacted
+				continue
+			}
+			out[k] = r.redact(x)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, x := range v {
+			out[i] = r.redact(x)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,251 @@
+package cassette_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/cassette"
+	"github.com/ppreeper/odoorpc/odoojrpc"
+	"github.com/ppreeper/odoorpc/odoojson"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+	"github.com/ppreeper/odoorpc/odooxmlrpc"
+)
+
+// newClients returns one client per transport using rt, pointed at host:port.
+func newClients(srv *odoorpctest.Server, host string, port int, rt http.RoundTripper) map[string]odoorpc.Odoo {
+	return map[string]odoorpc.Odoo{
+		"jsonrpc": odoojrpc.NewOdoo().WithHostname(host).WithPort(port).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+			WithTransport(rt),
+		"xmlrpc": odooxmlrpc.NewOdoo().WithHostname(host).WithPort(port).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+			WithTransport(rt),
+		"json2": odoojson.NewOdoo().WithHostname(host).WithPort(port).
+			WithDatabase(srv.Database).WithAPIKey(srv.APIKey).
+			WithTransport(rt),
+	}
+}
+
+// scenario runs the calls recorded and replayed by the tests.
+func scenario(ctx context.Context, o odoorpc.Odoo) ([]map[string]any, error) {
+	if err := o.Login(ctx); err != nil {
+		return nil, err
+	}
+	return o.SearchRead(ctx, "res.partner", 0, 0, []string{"name"}, []any{[]any{"name", "ilike", "a"}})
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	srv := odoorpctest.NewServer()
+	srv.Password = "s3cret-password"
+	srv.APIKey = "s3cret-api-key"
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "Alice"}, map[string]any{"name": "Bob"}); err != nil {
+		t.Fatal(err)
+	}
+	host, port := srv.Hostname(), srv.Port()
+
+	rec, err := cassette.New(path, cassette.ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, o := range newClients(srv, host, port, rec) {
+		recs, err := scenario(ctx, o)
+		if err != nil || len(recs) != 1 {
+			t.Fatalf("%s record: got %v, %v", name, recs, err)
+		}
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{srv.Password, srv.APIKey} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains secret %q", secret)
+		}
+	}
+
+	replay, err := cassette.New(path, cassette.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, o := range newClients(srv, host, port, replay) {
+		recs, err := scenario(ctx, o)
+		if err != nil {
+			t.Fatalf("%s replay: %v", name, err)
+		}
+		if len(recs) != 1 || recs[0]["name"] != "Alice" {
+			t.Errorf("%s replay: got %v", name, recs)
+		}
+	}
+	if unused := replay.Unused(); len(unused) != 0 {
+		t.Errorf("expected every interaction to be replayed, unused: %v", unused)
+	}
+
+	// A call that was never recorded fails clearly.
+	o := newClients(srv, host, port, replay)["json2"]
+	_, err = o.Search(ctx, "res.users")
+	if !errors.Is(err, cassette.ErrNoInteraction) {
+		t.Fatalf("expected ErrNoInteraction, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "res.users.search") {
+		t.Errorf("error should name the call, got %v", err)
+	}
+
+	// Replaying the same call again exhausts the recording.
+	_, err = scenario(ctx, newClients(srv, host, port, replay)["jsonrpc"])
+	if !errors.Is(err, cassette.ErrNoInteraction) || !strings.Contains(err.Error(), "already replayed") {
+		t.Errorf("expected exhausted-interaction error, got %v", err)
+	}
+}
+
+func TestRedactKeys(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+
+	rec, err := cassette.New(path, cassette.ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.WithRedactKeys("Signup_Token")
+	o := newClients(srv, srv.Hostname(), srv.Port(), rec)["jsonrpc"]
+	if err := o.Login(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Create(ctx, "res.users", map[string]any{
+		"login": "bob", "password": "hunter2", "signup_token": "tok-123",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"hunter2", "tok-123"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains secret %q:\n%s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), `"bob"`) {
+		t.Errorf("non-secret arguments should be kept:\n%s", data)
+	}
+}
+
+// roundTripFunc is an http.RoundTripper calling itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// jsonrpcResponse returns a response with body to req.
+func jsonrpcResponse(req *http.Request, body io.Reader) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(body),
+		Request:    req,
+	}
+}
+
+func TestRedactMasterPassword(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return jsonrpcResponse(req, strings.NewReader(`{"jsonrpc":"2.0","id":1,"result":true}`)), nil
+	})
+	rec, err := cassette.New(path, cassette.ModeRecord, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: rec}
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","method":"call","params":{"service":"db","method":"create_database","args":["master-1","new","false","en_US","admin-1","admin"]}}`,
+		`{"jsonrpc":"2.0","method":"call","params":{"service":"db","method":"drop","args":["master-2","old"]}}`,
+		`{"jsonrpc":"2.0","method":"call","params":{"service":"db","method":"change_admin_password","args":["master-3","master-4"]}}`,
+	} {
+		resp, err := client.Post("http://odoo.test/jsonrpc", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"master-1", "admin-1", "master-2", "master-3", "master-4"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains secret %q:\n%s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), `"old"`) {
+		t.Errorf("database names should be kept:\n%s", data)
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	t.Parallel()
+	const size = 32<<20 + 1
+	var sent int
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		n, _ := io.Copy(io.Discard, req.Body)
+		sent = int(n)
+		return jsonrpcResponse(req, io.LimitReader(zeros{}, size)), nil
+	})
+	rec, err := cassette.New(filepath.Join(t.TempDir(), "cassette.json"), cassette.ModeRecord, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: rec}
+
+	// A request body over the limit is not sent cut.
+	_, err = client.Post("http://odoo.test/json/2/ir.attachment/create", "application/json", io.LimitReader(zeros{}, size))
+	if err == nil || !strings.Contains(err.Error(), "larger than") || sent != 0 {
+		t.Errorf("large request: %v, %d bytes sent", err, sent)
+	}
+	// Nor is a response body over the limit recorded cut.
+	_, err = client.Post("http://odoo.test/json/2/ir.attachment/read", "application/json", strings.NewReader(`{"ids":[1]}`))
+	if err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("large response: %v", err)
+	}
+}
+
+// zeros reads an endless stream of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestReplayMissingFile(t *testing.T) {
+	t.Parallel()
+	if _, err := cassette.New(filepath.Join(t.TempDir(), "missing.json"), cassette.ModeReplay, nil); err == nil {
+		t.Fatal("expected error for missing cassette")
+	}
+}
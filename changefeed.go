@@ -0,0 +1,377 @@
+package odoorpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultChangeFeedBatchSize is the number of records a ChangeFeed reads per
+// call.
+const DefaultChangeFeedBatchSize = 500
+
+// Checkpoint is the position of a change feed: the write_date of the last
+// record it returned, in whole seconds as Odoo reads it, and the greatest id
+// it returned in that second. The records modified in a later second, or in
+// that second with a greater id, are yet to be returned. The zero Checkpoint
+// is before all records.
+type Checkpoint struct {
+	WriteDate string `json:"write_date"`
+	ID        int    `json:"id"`
+}
+
+// IsZero reports whether c is the zero Checkpoint.
+func (c Checkpoint) IsZero() bool {
+	return c == Checkpoint{}
+}
+
+// CheckpointStore persists the checkpoints of change feeds by name.
+type CheckpointStore interface {
+	// LoadCheckpoint returns the checkpoint of the feed name, or the zero
+	// Checkpoint if none was saved.
+	LoadCheckpoint(ctx context.Context, name string) (Checkpoint, error)
+	SaveCheckpoint(ctx context.Context, name string, c Checkpoint) error
+}
+
+// MemoryCheckpoints is a CheckpointStore in memory, lost with the process. It
+// is safe for concurrent use.
+type MemoryCheckpoints struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewMemoryCheckpoints returns an empty MemoryCheckpoints.
+func NewMemoryCheckpoints() *MemoryCheckpoints {
+	return &MemoryCheckpoints{checkpoints: make(map[string]Checkpoint)}
+}
+
+func (s *MemoryCheckpoints) LoadCheckpoint(_ context.Context, name string) (Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.checkpoints[name], nil
+}
+
+func (s *MemoryCheckpoints) SaveCheckpoint(_ context.Context, name string, c Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[name] = c
+	return nil
+}
+
+// FileCheckpoints is a CheckpointStore keeping the checkpoints of all feeds
+// in a JSON file, replaced atomically on save. It is safe for concurrent use
+// within a process.
+type FileCheckpoints struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileCheckpoints returns a store of checkpoints in the file at path,
+// created on the first save.
+func NewFileCheckpoints(path string) *FileCheckpoints {
+	return &FileCheckpoints{path: path}
+}
+
+func (s *FileCheckpoints) LoadCheckpoint(_ context.Context, name string) (Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoints, err := s.read()
+	return checkpoints[name], err
+}
+
+func (s *FileCheckpoints) SaveCheckpoint(_ context.Context, name string, c Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoints, err := s.read()
+	if err != nil {
+		return err
+	}
+	checkpoints[name] = c
+	data, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(s.path, data)
+	}
+	if err != nil {
+		return fmt.Errorf("checkpoint store: %w", err)
+	}
+	return nil
+}
+
+// read returns the checkpoints in the file, none if it does not exist.
+func (s *FileCheckpoints) read() (map[string]Checkpoint, error) {
+	checkpoints := map[string]Checkpoint{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return checkpoints, nil
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &checkpoints)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint store: %w", err)
+	}
+	return checkpoints, nil
+}
+
+// ChangeFeed returns the records of a model created or modified since its
+// checkpoint, in order of write_date and of id within a second, so that
+// records sharing a write_date are neither skipped nor returned twice across
+// batches. Its
+// checkpoint is kept in a CheckpointStore under the feed name, the model
+// name unless set. Archived records are returned too, archiving being a
+// change: add an active term to the domain to leave them out. A ChangeFeed is
+// not safe for concurrent use.
+type ChangeFeed struct {
+	o         Odoo
+	model     string
+	name      string
+	fields    []string
+	domain    []any
+	batchSize int
+	lag       time.Duration
+	store     CheckpointStore
+
+	loaded     bool
+	checkpoint Checkpoint
+}
+
+// NewChangeFeed returns a feed of the changes to the records of model,
+// reading all their fields, with its checkpoint in memory.
+func NewChangeFeed(o Odoo, model string) *ChangeFeed {
+	return &ChangeFeed{
+		o:         o,
+		model:     model,
+		name:      model,
+		batchSize: DefaultChangeFeedBatchSize,
+		store:     NewMemoryCheckpoints(),
+	}
+}
+
+// WithName sets the name the checkpoint is stored under, for several feeds
+// of a model sharing a store.
+func (f *ChangeFeed) WithName(name string) *ChangeFeed {
+	f.name = name
+	return f
+}
+
+// WithFields sets the fields read; write_date and id are always read.
+func (f *ChangeFeed) WithFields(fields ...string) *ChangeFeed {
+	f.fields = fields
+	return f
+}
+
+// WithDomain restricts the feed to the records matching domain.
+func (f *ChangeFeed) WithDomain(domain []any) *ChangeFeed {
+	f.domain = domain
+	return f
+}
+
+// WithBatchSize sets the number of records read per call.
+func (f *ChangeFeed) WithBatchSize(n int) *ChangeFeed {
+	f.batchSize = n
+	return f
+}
+
+// WithLag holds back the records modified within lag of the local clock.
+// Odoo stamps write_date when a transaction starts: a transaction committing
+// after the feed read past its write_date would otherwise be missed. The lag
+// should cover the longest transactions and the skew between the clocks.
+func (f *ChangeFeed) WithLag(lag time.Duration) *ChangeFeed {
+	f.lag = lag
+	return f
+}
+
+// WithStore sets the store of the checkpoint.
+func (f *ChangeFeed) WithStore(store CheckpointStore) *ChangeFeed {
+	f.store = store
+	f.loaded = false
+	return f
+}
+
+// Checkpoint returns the checkpoint of the feed, loaded from its store.
+func (f *ChangeFeed) Checkpoint(ctx context.Context) (Checkpoint, error) {
+	if !f.loaded {
+		c, err := f.store.LoadCheckpoint(ctx, f.name)
+		if err != nil {
+			return Checkpoint{}, fmt.Errorf("change feed %s failed: %w", f.name, err)
+		}
+		f.checkpoint, f.loaded = c, true
+	}
+	return f.checkpoint, nil
+}
+
+// Next returns the next batch of changed records after the checkpoint and the
+// checkpoint following them, without saving it: Commit it once the records
+// are processed. No records are returned once the feed is caught up.
+func (f *ChangeFeed) Next(ctx context.Context) (records []map[string]any, next Checkpoint, err error) {
+	c, err := f.Checkpoint(ctx)
+	if err != nil {
+		return nil, Checkpoint{}, err
+	}
+	for {
+		var after string
+		if !c.IsZero() {
+			t, err := time.Parse(DatetimeFormat, c.WriteDate)
+			if err != nil {
+				return nil, Checkpoint{}, fmt.Errorf("change feed %s failed: invalid checkpoint: %w", f.name, err)
+			}
+			after = t.Add(time.Second).Format(DatetimeFormat)
+			// The rest of the second of the checkpoint, in id order.
+			records, err := f.read(ctx, "id asc",
+				[]any{"write_date", ">=", c.WriteDate}, []any{"write_date", "<", after}, []any{"id", ">", c.ID})
+			if err != nil {
+				return nil, Checkpoint{}, err
+			}
+			if len(records) > 0 {
+				return records, advance(c, records), nil
+			}
+		}
+		var terms []any
+		if after != "" {
+			terms = append(terms, []any{"write_date", ">=", after})
+		}
+		records, err := f.read(ctx, "write_date asc, id asc", terms...)
+		if err != nil {
+			return nil, Checkpoint{}, err
+		}
+		if f.batchSize <= 0 || len(records) < f.batchSize {
+			return records, advance(c, records), nil
+		}
+		// The server sorts on the microseconds of write_date, which read
+		// drops: the records of the last second of a full batch are not in
+		// id order, and more may follow. They are read again by id.
+		last := records[len(records)-1]["write_date"]
+		n := len(records)
+		for n > 0 && records[n-1]["write_date"] == last {
+			n--
+		}
+		if n > 0 {
+			return records[:n], advance(c, records[:n]), nil
+		}
+		c = Checkpoint{WriteDate: last.(string)}
+	}
+}
+
+// read returns a batch of the records of the feed matching terms, in order.
+func (f *ChangeFeed) read(ctx context.Context, order string, terms ...any) ([]map[string]any, error) {
+	// The terms of a domain are implicitly and-ed.
+	domain := append(append([]any{}, f.domain...), terms...)
+	if f.lag > 0 {
+		domain = append(domain, []any{"write_date", "<=", time.Now().Add(-f.lag).UTC().Format(DatetimeFormat)})
+	}
+	kwargs := map[string]any{
+		"domain":  domain,
+		"order":   order,
+		"context": map[string]any{"active_test": false},
+	}
+	if len(f.fields) > 0 {
+		kwargs["fields"] = append(append([]string{}, f.fields...), "write_date")
+	}
+	if f.batchSize > 0 {
+		kwargs["limit"] = f.batchSize
+	}
+	result, err := f.o.CallMethod(ctx, f.model, "search_read", nil, kwargs)
+	if err != nil {
+		return nil, fmt.Errorf("change feed %s failed: %w", f.name, err)
+	}
+	list, ok := result.([]any)
+	if !ok {
+		return nil, fmt.Errorf("change feed %s failed: unexpected response type %T", f.name, result)
+	}
+	records := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		rec, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("change feed %s failed: unexpected record type %T in response", f.name, item)
+		}
+		writeDate, _ := rec["write_date"].(string)
+		id, _ := toFloat(rec["id"])
+		if writeDate == "" || id == 0 {
+			return nil, fmt.Errorf("change feed %s failed: record without write_date or id", f.name)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// advance returns the checkpoint following records, read after c in order of
+// write_date: the last second and the greatest id returned in it.
+func advance(c Checkpoint, records []map[string]any) Checkpoint {
+	for _, rec := range records {
+		writeDate := rec["write_date"].(string)
+		id, _ := toFloat(rec["id"])
+		if writeDate != c.WriteDate {
+			c = Checkpoint{WriteDate: writeDate}
+		}
+		c.ID = max(c.ID, int(id))
+	}
+	return c
+}
+
+// Commit saves c as the checkpoint of the feed.
+func (f *ChangeFeed) Commit(ctx context.Context, c Checkpoint) error {
+	if err := f.store.SaveCheckpoint(ctx, f.name, c); err != nil {
+		return fmt.Errorf("change feed %s failed: %w", f.name, err)
+	}
+	f.checkpoint, f.loaded = c, true
+	return nil
+}
+
+// Poll passes the records changed since the checkpoint to fn batch by batch,
+// committing the checkpoint after each batch fn accepts, until the feed is
+// caught up, and returns the number of records accepted. A batch that fn fails is
+// returned again by the next Poll: records are delivered at least once.
+func (f *ChangeFeed) Poll(ctx context.Context, fn func(records []map[string]any) error) (n int, err error) {
+	for {
+		records, next, err := f.Next(ctx)
+		if err != nil || len(records) == 0 {
+			return n, err
+		}
+		if err := fn(records); err != nil {
+			return n, err
+		}
+		if err := f.Commit(ctx, next); err != nil {
+			return n, err
+		}
+		n += len(records)
+	}
+}
+
+// Deleted returns the ids of known, the records the caller holds, that no
+// longer exist. Archived records exist, and so do records no longer matching
+// the domain of the feed. The ids are looked up in batches.
+func (f *ChangeFeed) Deleted(ctx context.Context, known []int) (deleted []int, err error) {
+	size := f.batchSize
+	if size <= 0 {
+		size = len(known)
+	}
+	for start := 0; start < len(known); start += size {
+		chunk := known[start:min(start+size, len(known))]
+		result, err := f.o.CallMethod(ctx, f.model, "search", nil, map[string]any{
+			"domain":  []any{[]any{"id", "in", chunk}},
+			"context": map[string]any{"active_test": false},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("change feed %s failed: %w", f.name, err)
+		}
+		found, err := parseIDList(result)
+		if err != nil {
+			return nil, fmt.Errorf("change feed %s failed: %w", f.name, err)
+		}
+		exists := make(map[int]bool, len(found))
+		for _, id := range found {
+			exists[id] = true
+		}
+		for _, id := range chunk {
+			if !exists[id] {
+				deleted = append(deleted, id)
+			}
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,240 @@
+package odoorpc_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+// pollIDs polls feed and returns the ids of the records in the order they
+// were passed, and the sizes of the batches.
+func pollIDs(t *testing.T, feed *odoorpc.ChangeFeed) (ids []int, batches []int) {
+	t.Helper()
+	n, err := feed.Poll(context.Background(), func(records []map[string]any) error {
+		batches = append(batches, len(records))
+		for _, rec := range records {
+			id, _ := rec["id"].(float64)
+			if i, ok := rec["id"].(int64); ok {
+				id = float64(i)
+			}
+			ids = append(ids, int(id))
+		}
+		return nil
+	})
+	if err != nil || n != len(ids) {
+		t.Fatalf("Poll = %d, %v; got %d records", n, err, len(ids))
+	}
+	return ids, batches
+}
+
+func TestChangeFeed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{"name": {Type: "char"}})
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	srv.SetClock(func() time.Time { return now })
+	// All the records share a write_date.
+	if _, err := srv.Seed("res.partner",
+		map[string]any{"name": "A"}, map[string]any{"name": "B"}, map[string]any{"name": "C"},
+		map[string]any{"name": "D"}, map[string]any{"name": "E"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, o := range interceptedClients(t, srv) {
+		store := odoorpc.NewFileCheckpoints(filepath.Join(t.TempDir(), "checkpoints.json"))
+		feed := odoorpc.NewChangeFeed(o, "res.partner").WithFields("name").WithBatchSize(2).WithStore(store)
+		ids, batches := pollIDs(t, feed)
+		if !reflect.DeepEqual(ids, []int{1, 2, 3, 4, 5}) || !reflect.DeepEqual(batches, []int{2, 2, 1}) {
+			t.Errorf("%s: first poll = %v in batches %v", name, ids, batches)
+		}
+		if ids, _ := pollIDs(t, feed); len(ids) != 0 {
+			t.Errorf("%s: caught up poll = %v", name, ids)
+		}
+		want := odoorpc.Checkpoint{WriteDate: "2024-05-01 10:00:00", ID: 5}
+		if c, err := feed.Checkpoint(ctx); err != nil || c != want {
+			t.Errorf("%s: Checkpoint = %+v, %v", name, c, err)
+		}
+
+		// A feed resuming from the store.
+		resumed := odoorpc.NewChangeFeed(o, "res.partner").WithStore(store)
+		if c, err := resumed.Checkpoint(ctx); err != nil || c != want {
+			t.Errorf("%s: stored Checkpoint = %+v, %v", name, c, err)
+		}
+		if ids, _ := pollIDs(t, resumed); len(ids) != 0 {
+			t.Errorf("%s: resumed poll = %v", name, ids)
+		}
+
+		// A failed batch is returned again.
+		records, next, err := feed.Next(ctx)
+		if err != nil || len(records) != 0 || next != want {
+			t.Errorf("%s: Next = %v, %+v, %v", name, records, next, err)
+		}
+		if err := feed.Commit(ctx, odoorpc.Checkpoint{WriteDate: want.WriteDate, ID: 3}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := feed.Poll(ctx, func([]map[string]any) error { return context.Canceled }); err != context.Canceled {
+			t.Errorf("%s: Poll error = %v", name, err)
+		}
+		if ids, _ := pollIDs(t, feed); !reflect.DeepEqual(ids, []int{4, 5}) {
+			t.Errorf("%s: poll after a failure = %v", name, ids)
+		}
+	}
+
+	for name, o := range interceptedClients(t, srv) {
+		feed := odoorpc.NewChangeFeed(o, "res.partner")
+		pollIDs(t, feed)
+		now = now.Add(time.Minute)
+		for _, id := range []int{4, 2} {
+			if _, err := o.Write(ctx, "res.partner", id, map[string]any{"name": name}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if ids, _ := pollIDs(t, feed); !reflect.DeepEqual(ids, []int{2, 4}) {
+			t.Errorf("%s: poll after writes = %v", name, ids)
+		}
+	}
+
+	// Records modified within the lag are held back.
+	now = time.Now()
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "F"}); err != nil {
+		t.Fatal(err)
+	}
+	for name, o := range interceptedClients(t, srv) {
+		ids, _ := pollIDs(t, odoorpc.NewChangeFeed(o, "res.partner").WithLag(time.Hour))
+		if len(ids) != 5 {
+			t.Errorf("%s: poll with a lag = %v", name, ids)
+		}
+		ids, _ = pollIDs(t, odoorpc.NewChangeFeed(o, "res.partner").WithDomain([]any{[]any{"name", "=", "F"}}))
+		if !reflect.DeepEqual(ids, []int{6}) {
+			t.Errorf("%s: poll with a domain = %v", name, ids)
+		}
+	}
+
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "G"}); err != nil {
+		t.Fatal(err)
+	}
+	o := interceptedClients(t, srv)[odoorpc.TransportJSONRPC]
+	if _, err := o.Unlink(ctx, "res.partner", []int{3, 7}); err != nil {
+		t.Fatal(err)
+	}
+	for name, o := range interceptedClients(t, srv) {
+		deleted, err := odoorpc.NewChangeFeed(o, "res.partner").WithBatchSize(2).Deleted(ctx, []int{1, 2, 3, 4, 5, 6, 7, 8})
+		if err != nil || !reflect.DeepEqual(deleted, []int{3, 7, 8}) {
+			t.Errorf("%s: Deleted = %v, %v", name, deleted, err)
+		}
+	}
+}
+
+func TestChangeFeedArchived(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name":   {Type: "char"},
+		"active": {Type: "boolean"},
+	})
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	srv.SetClock(func() time.Time { return now })
+	if _, err := srv.Seed("res.partner",
+		map[string]any{"name": "A", "active": true}, map[string]any{"name": "B", "active": false}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, o := range interceptedClients(t, srv) {
+		feed := odoorpc.NewChangeFeed(o, "res.partner").WithFields("active")
+		if ids, _ := pollIDs(t, feed); len(ids) != 2 {
+			t.Errorf("%s: first poll = %v, want the archived record too", name, ids)
+		}
+		active := odoorpc.NewChangeFeed(o, "res.partner").WithDomain([]any{[]any{"active", "=", true}})
+		pollIDs(t, active)
+
+		// Archiving a record is a change.
+		now = now.Add(time.Minute)
+		if _, err := o.Write(ctx, "res.partner", 1, map[string]any{"active": false}); err != nil {
+			t.Fatal(err)
+		}
+		records, _, err := feed.Next(ctx)
+		if err != nil || len(records) != 1 || records[0]["active"] != false {
+			t.Errorf("%s: Next after archiving = %v, %v", name, records, err)
+		}
+		if ids, _ := pollIDs(t, active); len(ids) != 0 {
+			t.Errorf("%s: poll of active records = %v", name, ids)
+		}
+		if _, err := o.Write(ctx, "res.partner", 1, map[string]any{"active": true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestChangeFeedSubsecond(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{"name": {Type: "char"}})
+	now := time.Date(2024, 5, 1, 10, 0, 0, 500_000_000, time.UTC)
+	srv.SetClock(func() time.Time { return now })
+	// One transaction stamps more records than a batch with one write_date.
+	if _, err := srv.Seed("res.partner",
+		map[string]any{"name": "A"}, map[string]any{"name": "B"}, map[string]any{"name": "C"},
+		map[string]any{"name": "D"}, map[string]any{"name": "E"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// nextIDs returns the ids of the next records, committing each batch,
+	// and fails if the feed does not catch up.
+	nextIDs := func(feed *odoorpc.ChangeFeed) (ids []int) {
+		t.Helper()
+		for range 10 {
+			records, next, err := feed.Next(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) == 0 {
+				return ids
+			}
+			for _, rec := range records {
+				id, _ := rec["id"].(float64)
+				if i, ok := rec["id"].(int64); ok {
+					id = float64(i)
+				}
+				ids = append(ids, int(id))
+			}
+			if err := feed.Commit(ctx, next); err != nil {
+				t.Fatal(err)
+			}
+		}
+		t.Fatalf("feed not caught up after %v", ids)
+		return nil
+	}
+
+	for name, o := range interceptedClients(t, srv) {
+		feed := odoorpc.NewChangeFeed(o, "res.partner").WithBatchSize(2)
+		if ids := nextIDs(feed); !reflect.DeepEqual(ids, []int{1, 2, 3, 4, 5}) {
+			t.Errorf("%s: first poll = %v", name, ids)
+		}
+
+		// Records modified within a second in the reverse order of their ids.
+		now = now.Truncate(time.Second).Add(time.Second)
+		for _, id := range []int{5, 4, 3, 2, 1} {
+			now = now.Add(50 * time.Millisecond)
+			if _, err := o.Write(ctx, "res.partner", id, map[string]any{"name": name}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if ids := nextIDs(feed); !reflect.DeepEqual(ids, []int{1, 2, 3, 4, 5}) {
+			t.Errorf("%s: poll after writes = %v", name, ids)
+		}
+		if c, err := feed.Checkpoint(ctx); err != nil || c.WriteDate != now.Format(odoorpc.DatetimeFormat) || c.ID != 5 {
+			t.Errorf("%s: Checkpoint = %+v, %v", name, c, err)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/ppreeper/odoorpc"
+)
+
+// modelSpec is a model to generate and the name of its struct.
+type modelSpec struct {
+	model string
+	name  string
+}
+
+// parseModelSpec parses a model argument, "res.partner" or
+// "res.partner=Partner". The struct name defaults to the model name in
+// camel case, e.g. ResPartner.
+func parseModelSpec(arg string) (modelSpec, error) {
+	model, name, ok := strings.Cut(arg, "=")
+	if model == "" || ok && !token.IsIdentifier(name) {
+		return modelSpec{}, fmt.Errorf("invalid model %q: use model or model=Name", arg)
+	}
+	if !ok {
+		name = goName(model)
+	}
+	if !token.IsExported(name) {
+		return modelSpec{}, fmt.Errorf("invalid model %q: %s is not an exported Go name", arg, name)
+	}
+	return modelSpec{model: model, name: name}, nil
+}
+
+// genModel is the generated code of a model.
+type genModel struct {
+	Model      string
+	Name       string
+	Plural     string
+	Fields     []genField
+	Selections []genSelection
+}
+
+// genField is a struct field.
+type genField struct {
+	Name    string
+	Field   string
+	Type    string
+	Tag     string
+	Comment string
+}
+
+// genSelection is the type of a selection field and its values.
+type genSelection struct {
+	Type   string
+	Field  string
+	Values []genConst
+}
+
+// genConst is a selection value.
+type genConst struct {
+	Name  string
+	Value string
+	Label string
+}
+
+// generate returns the source of package pkg declaring the models, given
+// the fields of each.
+func generate(pkg string, specs []modelSpec, fields map[string]map[string]odoorpc.FieldInfo) ([]byte, error) {
+	used := map[string]string{}
+	declare := func(name, what string) error {
+		if prev, ok := used[name]; ok {
+			return fmt.Errorf("%s and %s are both named %s: rename a model with model=Name", prev, what, name)
+		}
+		used[name] = what
+		return nil
+	}
+	var models []genModel
+	usesTime := false
+	for _, spec := range specs {
+		m := genModel{Model: spec.model, Name: spec.name, Plural: plural(spec.name)}
+		for _, name := range []string{m.Name, m.Plural, m.Name + "Fields", m.Name + "Model"} {
+			if err := declare(name, spec.model); err != nil {
+				return nil, err
+			}
+		}
+		names := make([]string, 0, len(fields[spec.model]))
+		for name := range fields[spec.model] {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			// id comes first.
+			return names[i] == "id" || names[j] != "id" && names[i] < names[j]
+		})
+		goNames := map[string]bool{}
+		for _, name := range names {
+			desc := fields[spec.model][name]
+			f := genField{Name: goName(name), Field: name}
+			for goNames[f.Name] {
+				f.Name += "_"
+			}
+			goNames[f.Name] = true
+			var opts []string
+			switch desc.Type {
+			case "char", "text", "html", "binary", "reference":
+				f.Type = "string"
+			case "integer", "many2one_reference":
+				f.Type = "int"
+			case "float", "monetary":
+				f.Type = "float64"
+			case "boolean":
+				f.Type = "bool"
+			case "many2one":
+				f.Type = "odoorpc.Many2One"
+			case "one2many", "many2many":
+				f.Type = "[]int"
+			case "date":
+				f.Type = "time.Time"
+				opts = append(opts, "date")
+			case "datetime":
+				f.Type = "time.Time"
+			case "selection":
+				sel := genSelection{Type: m.Name + f.Name, Field: name}
+				if err := declare(sel.Type, spec.model+"."+name); err != nil {
+					return nil, err
+				}
+				for i, pair := range desc.Selection {
+					c := genConst{Value: pair[0], Label: comment(pair[1])}
+					suffix := goName(c.Value)
+					if suffix == "" {
+						suffix = fmt.Sprint("Value", i)
+					}
+					c.Name = sel.Type + suffix
+					if err := declare(c.Name, fmt.Sprintf("%s.%s value %q", spec.model, name, c.Value)); err != nil {
+						return nil, err
+					}
+					sel.Values = append(sel.Values, c)
+				}
+				m.Selections = append(m.Selections, sel)
+				f.Type = sel.Type
+			default:
+				f.Type = "any"
+			}
+			if desc.Readonly {
+				opts = append(opts, "readonly")
+			}
+			f.Tag = fmt.Sprintf("`odoo:%q`", strings.Join(append([]string{name}, opts...), ","))
+			usesTime = usesTime || f.Type == "time.Time"
+
+			f.Comment = comment(desc.String)
+			if desc.Relation != "" {
+				f.Comment += " (" + desc.Relation + ")"
+			}
+			if desc.Required {
+				f.Comment += ", required"
+			}
+			m.Fields = append(m.Fields, f)
+		}
+		models = append(models, m)
+	}
+
+	var buf bytes.Buffer
+	err := sourceTemplate.Execute(&buf, struct {
+		Package  string
+		UsesTime bool
+		Models   []genModel
+	}{pkg, usesTime, models})
+	if err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid code: %w", err)
+	}
+	return src, nil
+}
+
+// initialisms are the words written in upper case in Go names.
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "id": true, "ip": true,
+	"json": true, "uid": true, "url": true, "uuid": true, "vat": true, "xml": true,
+}
+
+// goName returns the exported Go name of an Odoo name, e.g. PartnerID for
+// partner_id and SaleOrderLine for sale.order.line. Names that do not start
+// with a letter are prefixed with X.
+func goName(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		switch {
+		case word == "ids":
+			b.WriteString("IDs")
+		case initialisms[strings.ToLower(word)]:
+			b.WriteString(strings.ToUpper(word))
+		default:
+			r := []rune(word)
+			b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+		}
+	}
+	name := b.String()
+	if name != "" && !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// plural returns the plural of the English noun at the end of name.
+func plural(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
+
+// comment returns s on a single line for use in a line comment.
+func comment(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+var sourceTemplate = template.Must(template.New("source").Parse(`// Code generated by odoogen; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	{{- if .UsesTime}}
+	"time"
+	{{- end}}
+
+	"github.com/ppreeper/odoorpc"
+)
+{{range .Models}}{{$m := .}}
+// {{.Name}}Model is the name of the model of {{.Name}}.
+const {{.Name}}Model = "{{.Model}}"
+
+// {{.Name}} is a {{.Model}} record.
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}{{with .Comment}} // {{.}}{{end}}
+{{- end}}
+}
+
+// {{.Name}}Fields holds the names of the fields of {{.Name}}, for domains
+// and {{.Plural}}.Write.
+var {{.Name}}Fields = struct {
+{{- range .Fields}}
+	{{.Name}} string
+{{- end}}
+}{
+{{- range .Fields}}
+	{{.Name}}: "{{.Field}}",
+{{- end}}
+}
+{{range .Selections}}{{$s := .}}
+// {{.Type}} is a value of the {{.Field}} selection field of {{$m.Model}}.
+type {{.Type}} string
+
+// Values of {{.Type}}.
+const (
+{{- range .Values}}
+	{{.Name}} {{$s.Type}} = {{printf "%q" .Value}}{{with .Label}} // {{.}}{{end}}
+{{- end}}
+)
+{{end}}
+// {{.Plural}} accesses the {{.Model}} records as {{.Name}} values.
+type {{.Plural}} struct {
+	Odoo odoorpc.Odoo
+}
+
+// Search returns the records matching filters.
+func (m {{.Plural}}) Search(ctx context.Context, filters ...any) ([]{{.Name}}, error) {
+	return odoorpc.SearchReadAs[{{.Name}}](ctx, m.Odoo, {{.Name}}Model, 0, 0, filters...)
+}
+
+// Read returns the records with the given ids.
+func (m {{.Plural}}) Read(ctx context.Context, ids ...int) ([]{{.Name}}, error) {
+	return odoorpc.ReadAs[{{.Name}}](ctx, m.Odoo, {{.Name}}Model, ids)
+}
+
+// Count returns the number of records matching filters.
+func (m {{.Plural}}) Count(ctx context.Context, filters ...any) (int, error) {
+	return m.Odoo.Count(ctx, {{.Name}}Model, filters...)
+}
+
+// Create creates a record with the non-zero fields of rec and returns its
+// id.
+func (m {{.Plural}}) Create(ctx context.Context, rec *{{.Name}}) (int, error) {
+	values, err := odoorpc.EncodeRecord(rec)
+	if err != nil {
+		return 0, err
+	}
+	return m.Odoo.Create(ctx, {{.Name}}Model, values)
+}
+
+// Write updates the record id with the named fields of rec, or with its
+// non-zero fields when none are named.
+func (m {{.Plural}}) Write(ctx context.Context, id int, rec *{{.Name}}, fields ...string) error {
+	values, err := odoorpc.EncodeRecord(rec, fields...)
+	if err != nil {
+		return err
+	}
+	_, err = m.Odoo.Write(ctx, {{.Name}}Model, id, values)
+	return err
+}
+
+// Unlink deletes the records with the given ids.
+func (m {{.Plural}}) Unlink(ctx context.Context, ids ...int) error {
+	_, err := m.Odoo.Unlink(ctx, {{.Name}}Model, ids)
+	return err
+}
+{{end}}`))
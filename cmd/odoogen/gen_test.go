@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/cmd/odoogen/internal/testmodels"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+var update = flag.Bool("update", false, "update internal/testmodels/models.go")
+
+// newTestServer returns a fake server with the models of testmodels.
+func newTestServer(t *testing.T) *odoorpctest.Server {
+	t.Helper()
+	srv := odoorpctest.NewServer()
+	t.Cleanup(srv.Close)
+	testmodels.DefineModels(srv)
+	return srv
+}
+
+// runGen runs odoogen with args against srv over transport.
+func runGen(srv *odoorpctest.Server, transport string, args ...string) (int, string, string) {
+	vars := map[string]string{
+		"ODOORPC_URL":       srv.URL,
+		"ODOORPC_DB":        srv.Database,
+		"ODOORPC_USER":      srv.Username,
+		"ODOORPC_PASSWORD":  srv.Password,
+		"ODOORPC_API_KEY":   srv.APIKey,
+		"ODOORPC_TRANSPORT": transport,
+	}
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr, func(k string) string { return vars[k] })
+	return code, stdout.String(), stderr.String()
+}
+
+func TestGenerate(t *testing.T) {
+	srv := newTestServer(t)
+	golden := filepath.Join("internal", "testmodels", "models.go")
+	if *update {
+		args := append([]string{"-package", "testmodels", "-o", golden}, testmodels.Models...)
+		if code, _, stderr := runGen(srv, odoorpc.TransportJSONRPC, args...); code != 0 {
+			t.Fatalf("exit %d: %s", code, stderr)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, transport := range []string{odoorpc.TransportJSONRPC, odoorpc.TransportXMLRPC, odoorpc.TransportJSON2} {
+		args := append([]string{"-package", "testmodels"}, testmodels.Models...)
+		code, stdout, stderr := runGen(srv, transport, args...)
+		if code != 0 {
+			t.Fatalf("%s: exit %d: %s", transport, code, stderr)
+		}
+		if stdout != string(want) {
+			t.Errorf("%s: generated code differs from %s; run go generate ./cmd/odoogen/internal/testmodels to review:\n%s", transport, golden, stdout)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	tests := []struct {
+		args []string
+		code int
+		want string
+	}{
+		{nil, 2, "missing models"},
+		{[]string{"res.partner=partner"}, 2, "not an exported Go name"},
+		{[]string{"res.partner=Bad-Name"}, 2, "invalid model"},
+		{[]string{"-package", "a.b", "res.partner"}, 2, "invalid package name"},
+		{[]string{"res.partner=Partner", "res.partner.category=Partner"}, 1, "both named Partner"},
+		// The selection type of the type field of res.partner is PartnerType.
+		{[]string{"res.partner=Partner", "res.partner.category=PartnerType"}, 1, "both named PartnerType"},
+	}
+	for _, tt := range tests {
+		code, _, stderr := runGen(srv, odoorpc.TransportJSONRPC, tt.args...)
+		if code != tt.code || !strings.Contains(stderr, tt.want) {
+			t.Errorf("%v: exit %d, stderr %q; want exit %d with %q", tt.args, code, stderr, tt.code, tt.want)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"partner_id":       "PartnerID",
+		"category_ids":     "CategoryIDs",
+		"sale.order.line":  "SaleOrderLine",
+		"website_url":      "WebsiteURL",
+		"x_studio_field":   "XStudioField",
+		"0":                "X0",
+		"out_invoice":      "OutInvoice",
+		"draft-cancelled!": "DraftCancelled",
+		"":                 "",
+	}
+	for in, want := range tests {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for in, want := range map[string]string{"Partner": "Partners", "Address": "Addresses", "Category": "Categories", "Day": "Days", "Tax": "Taxes"} {
+		if got := plural(in); got != want {
+			t.Errorf("plural(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Code generated by odoogen; DO NOT EDIT.
+
+package testmodels
+
+import (
+	"context"
+	"time"
+
+	"github.com/ppreeper/odoorpc"
+)
+
+// PartnerModel is the name of the model of Partner.
+const PartnerModel = "res.partner"
+
+// Partner is a res.partner record.
+type Partner struct {
+	ID               int              `odoo:"id,readonly"`           // ID
+	CategoryID       []int            `odoo:"category_id"`           // Tags (res.partner.category)
+	ChildIDs         []int            `odoo:"child_ids"`             // Contact (res.partner)
+	Color            int              `odoo:"color"`                 // Color Index
+	Comment          string           `odoo:"comment"`               // Notes
+	CreateDate       time.Time        `odoo:"create_date,readonly"`  // Created on
+	CreditLimit      float64          `odoo:"credit_limit"`          // Credit Limit
+	Date             time.Time        `odoo:"date,date"`             // Date
+	DisplayName      string           `odoo:"display_name,readonly"` // Display Name
+	Email            string           `odoo:"email"`                 // Email
+	IsCompany        bool             `odoo:"is_company"`            // Is a Company
+	Name             string           `odoo:"name"`                  // Name, required
+	ParentID         odoorpc.Many2One `odoo:"parent_id"`             // Related Company (res.partner)
+	PartnerLatitude  float64          `odoo:"partner_latitude"`      // Geo Latitude
+	SignupExpiration time.Time        `odoo:"signup_expiration"`     // Signup Expiration
+	Type             PartnerType      `odoo:"type"`                  // Address Type
+	WebsiteURL       string           `odoo:"website_url,readonly"`  // Website URL
+	WriteDate        time.Time        `odoo:"write_date,readonly"`   // Last Updated on
+}
+
+// PartnerFields holds the names of the fields of Partner, for domains
+// and Partners.Write.
+var PartnerFields = struct {
+	ID               string
+	CategoryID       string
+	ChildIDs         string
+	Color            string
+	Comment          string
+	CreateDate       string
+	CreditLimit      string
+	Date             string
+	DisplayName      string
+	Email            string
+	IsCompany        string
+	Name             string
+	ParentID         string
+	PartnerLatitude  string
+	SignupExpiration string
+	Type             string
+	WebsiteURL       string
+	WriteDate        string
+}{
+	ID:               "id",
+	CategoryID:       "category_id",
+	ChildIDs:         "child_ids",
+	Color:            "color",
+	Comment:          "comment",
+	CreateDate:       "create_date",
+	CreditLimit:      "credit_limit",
+	Date:             "date",
+	DisplayName:      "display_name",
+	Email:            "email",
+	IsCompany:        "is_company",
+	Name:             "name",
+	ParentID:         "parent_id",
+	PartnerLatitude:  "partner_latitude",
+	SignupExpiration: "signup_expiration",
+	Type:             "type",
+	WebsiteURL:       "website_url",
+	WriteDate:        "write_date",
+}
+
+// PartnerType is a value of the type selection field of res.partner.
+type PartnerType string
+
+// Values of PartnerType.
+const (
+	PartnerTypeContact  PartnerType = "contact"  // Contact
+	PartnerTypeInvoice  PartnerType = "invoice"  // Invoice Address
+	PartnerTypeDelivery PartnerType = "delivery" // Delivery Address
+)
+
+// Partners accesses the res.partner records as Partner values.
+type Partners struct {
+	Odoo odoorpc.Odoo
+}
+
+// Search returns the records matching filters.
+func (m Partners) Search(ctx context.Context, filters ...any) ([]Partner, error) {
+	return odoorpc.SearchReadAs[Partner](ctx, m.Odoo, PartnerModel, 0, 0, filters...)
+}
+
+// Read returns the records with the given ids.
+func (m Partners) Read(ctx context.Context, ids ...int) ([]Partner, error) {
+	return odoorpc.ReadAs[Partner](ctx, m.Odoo, PartnerModel, ids)
+}
+
+// Count returns the number of records matching filters.
+func (m Partners) Count(ctx context.Context, filters ...any) (int, error) {
+	return m.Odoo.Count(ctx, PartnerModel, filters...)
+}
+
+// Create creates a record with the non-zero fields of rec and returns its
+// id.
+func (m Partners) Create(ctx context.Context, rec *Partner) (int, error) {
+	values, err := odoorpc.EncodeRecord(rec)
+	if err != nil {
+		return 0, err
+	}
+	return m.Odoo.Create(ctx, PartnerModel, values)
+}
+
+// Write updates the record id with the named fields of rec, or with its
+// non-zero fields when none are named.
+func (m Partners) Write(ctx context.Context, id int, rec *Partner, fields ...string) error {
+	values, err := odoorpc.EncodeRecord(rec, fields...)
+	if err != nil {
+		return err
+	}
+	_, err = m.Odoo.Write(ctx, PartnerModel, id, values)
+	return err
+}
+
+// Unlink deletes the records with the given ids.
+func (m Partners) Unlink(ctx context.Context, ids ...int) error {
+	_, err := m.Odoo.Unlink(ctx, PartnerModel, ids)
+	return err
+}
+
+// CategoryModel is the name of the model of Category.
+const CategoryModel = "res.partner.category"
+
+// Category is a res.partner.category record.
+type Category struct {
+	ID          int       `odoo:"id,readonly"`           // ID
+	Color       int       `odoo:"color"`                 // Color
+	CreateDate  time.Time `odoo:"create_date,readonly"`  // Created on
+	DisplayName string    `odoo:"display_name,readonly"` // Display Name
+	Name        string    `odoo:"name"`                  // Tag Name, required
+	WriteDate   time.Time `odoo:"write_date,readonly"`   // Last Updated on
+}
+
+// CategoryFields holds the names of the fields of Category, for domains
+// and Categories.Write.
+var CategoryFields = struct {
+	ID          string
+	Color       string
+	CreateDate  string
+	DisplayName string
+	Name        string
+	WriteDate   string
+}{
+	ID:          "id",
+	Color:       "color",
+	CreateDate:  "create_date",
+	DisplayName: "display_name",
+	Name:        "name",
+	WriteDate:   "write_date",
+}
+
+// Categories accesses the res.partner.category records as Category values.
+type Categories struct {
+	Odoo odoorpc.Odoo
+}
+
+// Search returns the records matching filters.
+func (m Categories) Search(ctx context.Context, filters ...any) ([]Category, error) {
+	return odoorpc.SearchReadAs[Category](ctx, m.Odoo, CategoryModel, 0, 0, filters...)
+}
+
+// Read returns the records with the given ids.
+func (m Categories) Read(ctx context.Context, ids ...int) ([]Category, error) {
+	return odoorpc.ReadAs[Category](ctx, m.Odoo, CategoryModel, ids)
+}
+
+// Count returns the number of records matching filters.
+func (m Categories) Count(ctx context.Context, filters ...any) (int, error) {
+	return m.Odoo.Count(ctx, CategoryModel, filters...)
+}
+
+// Create creates a record with the non-zero fields of rec and returns its
+// id.
+func (m Categories) Create(ctx context.Context, rec *Category) (int, error) {
+	values, err := odoorpc.EncodeRecord(rec)
+	if err != nil {
+		return 0, err
+	}
+	return m.Odoo.Create(ctx, CategoryModel, values)
+}
+
+// Write updates the record id with the named fields of rec, or with its
+// non-zero fields when none are named.
+func (m Categories) Write(ctx context.Context, id int, rec *Category, fields ...string) error {
+	values, err := odoorpc.EncodeRecord(rec, fields...)
+	if err != nil {
+		return err
+	}
+	_, err = m.Odoo.Write(ctx, CategoryModel, id, values)
+	return err
+}
+
+// Unlink deletes the records with the given ids.
+func (m Categories) Unlink(ctx context.Context, ids ...int) error {
+	_, err := m.Odoo.Unlink(ctx, CategoryModel, ids)
+	return err
+}
@@ -0,0 +1,92 @@
+package testmodels_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/cmd/odoogen/internal/testmodels"
+	"github.com/ppreeper/odoorpc/internal/cliconfig"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+func TestModels(t *testing.T) {
+	t.Parallel()
+	srv := odoorpctest.NewServer()
+	t.Cleanup(srv.Close)
+	testmodels.DefineModels(srv)
+	ctx := context.Background()
+
+	for _, transport := range []string{odoorpc.TransportJSONRPC, odoorpc.TransportXMLRPC, odoorpc.TransportJSON2} {
+		o, err := cliconfig.NewClient(cliconfig.Profile{
+			URL: srv.URL, Database: srv.Database, Username: srv.Username,
+			Password: srv.Password, APIKey: srv.APIKey, Transport: transport,
+		}, 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := o.Login(ctx); err != nil {
+			t.Fatalf("%s: login: %v", transport, err)
+		}
+		partners := testmodels.Partners{Odoo: o}
+		categories := testmodels.Categories{Odoo: o}
+
+		tag, err := categories.Create(ctx, &testmodels.Category{Name: "VIP"})
+		if err != nil {
+			t.Fatalf("%s: create category: %v", transport, err)
+		}
+		company, err := partners.Create(ctx, &testmodels.Partner{Name: "Acme " + transport, IsCompany: true})
+		if err != nil {
+			t.Fatalf("%s: create company: %v", transport, err)
+		}
+		expiration := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+		want := testmodels.Partner{
+			Name:             "Alice " + transport,
+			Type:             testmodels.PartnerTypeInvoice,
+			Color:            3,
+			CreditLimit:      1500.5,
+			Date:             time.Date(2025, 2, 14, 0, 0, 0, 0, time.UTC),
+			SignupExpiration: expiration,
+			ParentID:         odoorpc.Many2One{ID: company},
+			CategoryID:       []int{tag},
+		}
+		id, err := partners.Create(ctx, &want)
+		if err != nil {
+			t.Fatalf("%s: create: %v", transport, err)
+		}
+		recs, err := partners.Read(ctx, id)
+		if err != nil || len(recs) != 1 {
+			t.Fatalf("%s: read: %v, %v", transport, recs, err)
+		}
+		got := recs[0]
+		want.ID = id
+		want.ParentID.Name = "Acme " + transport
+		if got.ID != want.ID || got.Name != want.Name || got.Type != want.Type || got.Color != want.Color ||
+			got.CreditLimit != want.CreditLimit || !got.Date.Equal(want.Date) ||
+			!got.SignupExpiration.Equal(want.SignupExpiration) || got.ParentID != want.ParentID ||
+			len(got.CategoryID) != 1 || got.CategoryID[0] != tag || got.Email != "" || got.IsCompany {
+			t.Errorf("%s: read %+v, want %+v", transport, got, want)
+		}
+		if got.CreateDate.IsZero() || got.DisplayName != want.Name {
+			t.Errorf("%s: magic fields not read: %+v", transport, got)
+		}
+
+		// Write clears the named fields with zero values.
+		got.Email = "alice@example.test"
+		got.ParentID = odoorpc.Many2One{}
+		if err := partners.Write(ctx, id, &got, testmodels.PartnerFields.Email, testmodels.PartnerFields.ParentID); err != nil {
+			t.Fatalf("%s: write: %v", transport, err)
+		}
+		found, err := partners.Search(ctx, []any{[]any{testmodels.PartnerFields.Email, "=", "alice@example.test"}})
+		if err != nil || len(found) != 1 || found[0].ID != id || found[0].ParentID.ID != 0 {
+			t.Errorf("%s: search after write: %+v, %v", transport, found, err)
+		}
+		if n, err := partners.Count(ctx, []any{[]any{"is_company", "=", true}}); err != nil || n != 1 {
+			t.Errorf("%s: count companies: %d, %v", transport, n, err)
+		}
+		if err := partners.Unlink(ctx, id, company); err != nil {
+			t.Errorf("%s: unlink: %v", transport, err)
+		}
+	}
+}
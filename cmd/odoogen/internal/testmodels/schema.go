@@ -0,0 +1,36 @@
+// Package testmodels holds the code odoogen generates for the models that
+// DefineModels defines, to check that it compiles and works.
+package testmodels
+
+//go:generate go test ../.. -run TestGenerate -update
+
+import "github.com/ppreeper/odoorpc/odoorpctest"
+
+// Models are the odoogen arguments generating models.go.
+var Models = []string{"res.partner=Partner", "res.partner.category=Category"}
+
+// DefineModels defines the models of models.go on srv.
+func DefineModels(srv *odoorpctest.Server) {
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name":              {Type: "char", String: "Name", Required: true},
+		"email":             {Type: "char", String: "Email"},
+		"comment":           {Type: "html", String: "Notes"},
+		"is_company":        {Type: "boolean", String: "Is a Company"},
+		"color":             {Type: "integer", String: "Color Index"},
+		"credit_limit":      {Type: "monetary", String: "Credit Limit"},
+		"partner_latitude":  {Type: "float", String: "Geo Latitude"},
+		"date":              {Type: "date", String: "Date"},
+		"signup_expiration": {Type: "datetime", String: "Signup Expiration"},
+		"type": {Type: "selection", String: "Address Type", Selection: [][2]string{
+			{"contact", "Contact"}, {"invoice", "Invoice Address"}, {"delivery", "Delivery Address"},
+		}},
+		"parent_id":   {Type: "many2one", String: "Related Company", Relation: "res.partner"},
+		"child_ids":   {Type: "one2many", String: "Contact", Relation: "res.partner"},
+		"category_id": {Type: "many2many", String: "Tags", Relation: "res.partner.category"},
+		"website_url": {Type: "char", String: "Website URL", Readonly: true},
+	})
+	srv.DefineModel("res.partner.category", map[string]odoorpctest.Field{
+		"name":  {Type: "char", String: "Tag Name", Required: true},
+		"color": {Type: "integer", String: "Color"},
+	})
+}
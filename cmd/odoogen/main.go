@@ -0,0 +1,130 @@
+// Command odoogen generates Go types for Odoo models from their fields, as
+// reported by fields_get, so that code using them fails to compile when the
+// schema changes.
+//
+// Usage:
+//
+//	odoogen [flags] <model>[=Name]...
+//
+// For each model, odoogen generates a struct named Name, by default the model
+// name in camel case, with a field per Odoo field, and a client wrapper named
+// after its plural, e.g. for res.partner=Partner:
+//
+//	partners := models.Partners{Odoo: client}
+//	recs, err := partners.Search(ctx, []any{[]any{models.PartnerFields.IsCompany, "=", true}})
+//	id, err := partners.Create(ctx, &models.Partner{Name: "Acme", Type: models.PartnerTypeContact})
+//
+// Field types map to Go types as follows: char, text, html, binary and
+// reference to string; integer to int; float and monetary to float64;
+// boolean to bool; many2one to odoorpc.Many2One; one2many and many2many to
+// []int; date and datetime to time.Time; selection to a string type with a
+// constant per value. Other types map to any. The struct fields are tagged
+// for odoorpc.DecodeRecord and odoorpc.EncodeRecord.
+//
+// The server is selected with the same flags, ODOORPC_* environment
+// variables and configuration profiles as the odoorpc command.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/internal/cliconfig"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr, os.Getenv))
+}
+
+// run executes the command line args and returns the exit status.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, getenv func(string) string) int {
+	flags := flag.NewFlagSet("odoogen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: odoogen [flags] <model>[=Name]...\n\nflags:")
+		flags.PrintDefaults()
+	}
+	var conn cliconfig.Flags
+	conn.Register(flags)
+	output := flags.String("o", "", "write the code to `file` instead of stdout")
+	pkg := flags.String("package", "models", "package `name` of the generated code")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	err := generateModels(ctx, &conn, flags.Args(), *pkg, *output, stdout, getenv)
+	var ue cliconfig.UsageError
+	switch {
+	case errors.As(err, &ue):
+		fmt.Fprintf(stderr, "odoogen: %v\n", err)
+		flags.Usage()
+		return 2
+	case err != nil:
+		fmt.Fprintf(stderr, "odoogen: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// generateModels generates the code of the models named by args and writes
+// it to output, or to stdout when output is empty.
+func generateModels(ctx context.Context, conn *cliconfig.Flags, args []string, pkg, output string, stdout io.Writer, getenv func(string) string) error {
+	if len(args) == 0 {
+		return cliconfig.UsageError("missing models")
+	}
+	if !token.IsIdentifier(pkg) {
+		return cliconfig.UsageError(fmt.Sprintf("invalid package name %q", pkg))
+	}
+	specs := make([]modelSpec, 0, len(args))
+	for _, arg := range args {
+		spec, err := parseModelSpec(arg)
+		if err != nil {
+			return cliconfig.UsageError(err.Error())
+		}
+		specs = append(specs, spec)
+	}
+	p, err := conn.Resolve(getenv)
+	if err != nil {
+		return err
+	}
+	o, err := cliconfig.NewClient(p, conn.Timeout, nil)
+	if err != nil {
+		return err
+	}
+	if err := o.Login(ctx); err != nil {
+		return err
+	}
+	fields := map[string]map[string]odoorpc.FieldInfo{}
+	for _, spec := range specs {
+		result, err := o.FieldsGet(ctx, spec.model, nil, odoorpc.FieldInfoAttributes...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", spec.model, err)
+		}
+		if fields[spec.model], err = odoorpc.ParseFieldInfo(result); err != nil {
+			return fmt.Errorf("%s: %w", spec.model, err)
+		}
+		if len(fields[spec.model]) == 0 {
+			return fmt.Errorf("%s: no fields: is the model installed?", spec.model)
+		}
+	}
+	src, err := generate(pkg, specs, fields)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(output, src, 0o644)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/internal/cliconfig"
+)
+
+// connFlags are the connection and output flags shared by all commands.
+type connFlags struct {
+	cliconfig.Flags
+	format string
+
+	// rt, when set, is the http.RoundTripper of the client.
+	rt http.RoundTripper
+	// resolved holds the settings of the last connect.
+	resolved cliconfig.Profile
+}
+
+// register adds the connection and output flags to fs.
+func (c *connFlags) register(flags *flag.FlagSet) {
+	c.Flags.Register(flags)
+	flags.StringVar(&c.format, "format", "table", "output format: table, json, ndjson or csv")
+}
+
+// connect returns a logged-in client for the resolved settings.
+func (c *connFlags) connect(ctx context.Context, e *env) (odoorpc.Odoo, error) {
+	p, err := c.Resolve(e.getenv)
+	if err != nil {
+		return nil, err
+	}
+	c.resolved = p
+	o, err := cliconfig.NewClient(p, c.Timeout, c.rt)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.Login(ctx); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
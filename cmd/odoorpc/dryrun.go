@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// redacted replaces credentials in printed payloads.
+const redacted = "[REDACTED]"
+
+// errDryRun is returned for the requests a dryRun prints instead of sending.
+var errDryRun = errors.New("dry run: request not sent")
+
+// dryRun is an http.RoundTripper that sends requests until it is armed, and
+// then prints them instead of sending them. Logging in and reading the
+// records to preview are sent; the calls that modify records are printed.
+type dryRun struct {
+	w       io.Writer
+	secrets []string
+	armed   atomic.Bool
+}
+
+// RoundTrip implements http.RoundTripper.
+func (d *dryRun) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !d.armed.Load() {
+		return http.DefaultTransport.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	for _, s := range d.secrets {
+		body = redact(body, s)
+	}
+	fmt.Fprintf(d.w, "%s %s\n%s\n\n", req.Method, req.URL, bytes.TrimSpace(body))
+	return nil, errDryRun
+}
+
+// redact replaces secret where it appears as a JSON or XML-RPC string.
+func redact(body []byte, secret string) []byte {
+	if secret == "" {
+		return body
+	}
+	quoted, _ := json.Marshal(secret)
+	body = bytes.ReplaceAll(body, quoted, []byte(`"`+redacted+`"`))
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(secret))
+	for _, tag := range []string{"string", "value"} {
+		body = bytes.ReplaceAll(body,
+			[]byte("<"+tag+">"+escaped.String()+"</"+tag+">"),
+			[]byte("<"+tag+">"+redacted+"</"+tag+">"))
+	}
+	return body
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// openInput opens the named file, or returns stdin for "-".
+func openInput(e *env, name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(e.stdin), nil
+	}
+	return os.Open(name)
+}
+
+// inputFormat returns the format of the named file: format when set, and
+// otherwise the format its extension implies.
+func inputFormat(name, format string) (string, error) {
+	if format == "" {
+		switch filepath.Ext(name) {
+		case ".csv":
+			format = "csv"
+		case ".json":
+			format = "json"
+		case ".ndjson", ".jsonl":
+			format = "ndjson"
+		default:
+			return "", usageError(fmt.Sprintf("cannot tell the format of %q: set -input-format", name))
+		}
+	}
+	switch format {
+	case "csv", "json", "ndjson":
+		return format, nil
+	}
+	return "", usageError(fmt.Sprintf("unknown input format %q: use csv, json or ndjson", format))
+}
+
+// readObjects reads JSON objects: a single object, an array of objects or
+// newline-delimited objects.
+func readObjects(r io.Reader) ([]map[string]any, error) {
+	var objects []map[string]any
+	dec := json.NewDecoder(r)
+	for {
+		var v any
+		err := dec.Decode(&v)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON input: %w", err)
+		}
+		items, ok := v.([]any)
+		if !ok {
+			items = []any{v}
+		}
+		for _, item := range items {
+			obj, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("invalid JSON input: expected objects, got %T", item)
+			}
+			objects = append(objects, normalizeJSON(obj).(map[string]any))
+		}
+	}
+}
+
+// readTable reads the rows to load: a CSV file whose first line holds the
+// field names, or JSON objects keyed by field name. JSON values are
+// converted to the text load expects.
+func readTable(r io.Reader, format string) (header []string, rows [][]any, err error) {
+	if format == "csv" {
+		records, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CSV input: %w", err)
+		}
+		if len(records) == 0 {
+			return nil, nil, fmt.Errorf("invalid CSV input: missing header line")
+		}
+		header = records[0]
+		for _, rec := range records[1:] {
+			row := make([]any, len(rec))
+			for i, v := range rec {
+				row[i] = v
+			}
+			rows = append(rows, row)
+		}
+		return header, rows, nil
+	}
+	objects, err := readObjects(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	seen := map[string]bool{}
+	for _, obj := range objects {
+		for k := range obj {
+			if !seen[k] {
+				seen[k] = true
+				header = append(header, k)
+			}
+		}
+	}
+	sort.Strings(header)
+	for _, obj := range objects {
+		row := make([]any, len(header))
+		for i, k := range header {
+			if row[i], err = loadValue(obj[k]); err != nil {
+				return nil, nil, fmt.Errorf("field %s: %w", k, err)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return header, rows, nil
+}
+
+// loadValue converts a JSON value to the text representation of load.
+func loadValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("unsupported value %v: load takes text, numbers and booleans", v)
+}
@@ -0,0 +1,248 @@
+// Command odoorpc queries and modifies the records of an Odoo server from
+// the command line over any of the JSON-RPC, XML-RPC and JSON-2 transports.
+//
+// Usage:
+//
+//	odoorpc <command> [flags] <model> [arguments]
+//
+// The commands are:
+//
+//	search       print the ids of the records matching a domain
+//	read         print records by id
+//	search-read  print the records matching a domain
+//	count        print the number of records matching a domain
+//	fields       print the fields of a model
+//	call         call a method and print its result
+//	create       create records from JSON
+//	write        update records by id or domain
+//	unlink       delete records by id or domain
+//	load         import records from a CSV, JSON or NDJSON file
+//
+// Domains are written in Odoo syntax, e.g. "[('name', 'ilike', 'acme')]",
+// or as JSON, e.g. '[["name", "ilike", "acme"]]'. Flags may be placed
+// anywhere after the command.
+//
+// The server is selected with -url, -db, -user, -password, -api-key and
+// -transport, with the ODOORPC_URL, ODOORPC_DB, ODOORPC_USER,
+// ODOORPC_PASSWORD, ODOORPC_API_KEY and ODOORPC_TRANSPORT environment
+// variables, or with a named profile of the configuration file (see
+// -config and -profile). Flags take precedence over environment variables,
+// which take precedence over the profile.
+//
+// The configuration file is JSON:
+//
+//	{
+//		"default": "prod",
+//		"profiles": {
+//			"prod": {
+//				"url": "https://erp.example.com",
+//				"database": "prod",
+//				"api_key": "...",
+//				"transport": "json2"
+//			}
+//		}
+//	}
+//
+// The commands that modify records print a preview of the records affected
+// to stderr. write, unlink and load of rows with an id ask for confirmation
+// on stdin unless -yes is set; loading such rows from stdin requires -yes.
+// With -dry-run, the requests that would modify records are printed,
+// credentials redacted, instead of being sent.
+//
+// Results are printed as an aligned table (-format table, the default), a
+// JSON array (json), one JSON object per line (ndjson) or CSV (csv).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+
+	"github.com/ppreeper/odoorpc/internal/cliconfig"
+)
+
+// env holds the process environment and streams of a run.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+}
+
+// command is an odoorpc subcommand.
+type command struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, e *env, cl *cmdline) error
+}
+
+var commands = map[string]command{
+	"search": {
+		usage: "search [flags] <model> [domain]",
+		help:  "print the ids of the records matching domain",
+		run:   runSearch,
+	},
+	"read": {
+		usage: "read [flags] <model> <id>...",
+		help:  "print records by id",
+		run:   runRead,
+	},
+	"search-read": {
+		usage: "search-read [flags] <model> [domain]",
+		help:  "print the records matching domain",
+		run:   runSearchRead,
+	},
+	"count": {
+		usage: "count [flags] <model> [domain]",
+		help:  "print the number of records matching domain",
+		run:   runCount,
+	},
+	"fields": {
+		usage: "fields [flags] <model> [field]...",
+		help:  "print the fields of model",
+		run:   runFields,
+	},
+	"call": {
+		usage: "call [flags] <model> <method>",
+		help:  "call method and print its result",
+		run:   runCall,
+	},
+	"create": {
+		usage: "create [flags] <model>",
+		help:  "create records from JSON",
+		run:   runCreate,
+	},
+	"write": {
+		usage: "write [flags] <model> [id]...",
+		help:  "update records by id or domain",
+		run:   runWrite,
+	},
+	"unlink": {
+		usage: "unlink [flags] <model> [id]...",
+		help:  "delete records by id or domain",
+		run:   runUnlink,
+	},
+	"load": {
+		usage: "load [flags] <model> <file>",
+		help:  "import records from a CSV, JSON or NDJSON file",
+		run:   runLoad,
+	},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], &env{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		getenv: os.Getenv,
+	}))
+}
+
+// run executes the command line args and returns the exit status.
+func run(ctx context.Context, args []string, e *env) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" || args[0] == "help" {
+		usage(e.stderr)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(e.stderr, "odoorpc: unknown command %q\n", args[0])
+		usage(e.stderr)
+		return 2
+	}
+	cl := newCmdline(args[0], cmd.usage, e, args[1:])
+	if err := cmd.run(ctx, e, cl); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		if errors.Is(err, errUsageShown) {
+			return 2
+		}
+		var ue usageError
+		if errors.As(err, &ue) {
+			fmt.Fprintf(e.stderr, "odoorpc: %v\nusage: odoorpc %s\n", err, cmd.usage)
+			return 2
+		}
+		fmt.Fprintf(e.stderr, "odoorpc: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: odoorpc <command> [flags] <model> [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-12s %s\n", name, commands[name].help)
+	}
+	fmt.Fprintln(w, "\nRun 'odoorpc <command> -h' for the flags of a command.")
+}
+
+// errUsageShown reports invalid flags, for which the flag package has
+// already printed the error and the usage.
+var errUsageShown = errors.New("invalid flags")
+
+// usageError reports invalid command line arguments.
+type usageError = cliconfig.UsageError
+
+// cmdline is the command line of a command: its flags, including the
+// connection flags, and its arguments.
+type cmdline struct {
+	*flag.FlagSet
+	conn connFlags
+	args []string
+}
+
+func newCmdline(name, usage string, e *env, args []string) *cmdline {
+	cl := &cmdline{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError), args: args}
+	cl.SetOutput(e.stderr)
+	cl.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: odoorpc %s\n\nflags:\n", usage)
+		cl.PrintDefaults()
+	}
+	cl.conn.register(cl.FlagSet)
+	return cl
+}
+
+// parse parses the flags found anywhere in the arguments and returns the
+// positional arguments, checking that there are at least min and at most
+// max of them; a negative max means no limit.
+func (cl *cmdline) parse(min, max int) ([]string, error) {
+	var positional []string
+	args := cl.args
+	for {
+		if err := cl.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsageShown
+		}
+		if args = cl.Args(); len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	switch {
+	case len(positional) < min:
+		return nil, usageError("missing arguments")
+	case max >= 0 && len(positional) > max:
+		return nil, usageError(fmt.Sprintf("unexpected arguments %q", positional[max:]))
+	}
+	return positional, checkFormat(cl.conn.format)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+// newTestServer returns a fake server with a few partners.
+func newTestServer(t *testing.T) *odoorpctest.Server {
+	t.Helper()
+	srv := odoorpctest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name":      {Type: "char", String: "Name", Required: true},
+		"email":     {Type: "char", String: "Email"},
+		"parent_id": {Type: "many2one", String: "Company", Relation: "res.partner"},
+	})
+	acme, err := srv.Seed("res.partner", map[string]any{"name": "Acme", "email": "info@acme.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Seed("res.partner",
+		map[string]any{"name": "Alice", "email": "alice@acme.test", "parent_id": acme[0]},
+		map[string]any{"name": "Bob"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	return srv
+}
+
+// runCmd runs the command line args with the environment variables vars and
+// returns the exit status and outputs.
+func runCmd(vars map[string]string, args ...string) (int, string, string) {
+	return runInput(vars, "", args...)
+}
+
+// runInput is runCmd with stdin as the standard input.
+func runInput(vars map[string]string, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &env{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(k string) string { return vars[k] },
+	})
+	return code, stdout.String(), stderr.String()
+}
+
+// serverVars returns the environment selecting srv over transport.
+func serverVars(srv *odoorpctest.Server, transport string) map[string]string {
+	return map[string]string{
+		"ODOORPC_URL":       srv.URL,
+		"ODOORPC_DB":        srv.Database,
+		"ODOORPC_USER":      srv.Username,
+		"ODOORPC_PASSWORD":  srv.Password,
+		"ODOORPC_API_KEY":   srv.APIKey,
+		"ODOORPC_TRANSPORT": transport,
+	}
+}
+
+func TestQueryCommands(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	for _, transport := range []string{odoorpc.TransportJSONRPC, odoorpc.TransportXMLRPC, odoorpc.TransportJSON2} {
+		vars := serverVars(srv, transport)
+
+		tests := []struct {
+			args []string
+			want string
+		}{
+			{[]string{"search", "res.partner", "[('name', 'ilike', 'a')]", "-format", "json"}, "[\n  1,\n  2\n]\n"},
+			{[]string{"search", "-limit", "1", "-offset", "1", "-order", "name desc", "res.partner", "-format", "ndjson"}, "2\n"},
+			{[]string{"count", "res.partner", `[["email", "!=", false]]`}, "2\n"},
+			{[]string{"read", "res.partner", "2", "-fields", "name,parent_id"}, "id  name   parent_id\n2   Alice  Acme\n"},
+			{
+				[]string{"search-read", "res.partner", "-fields", "name,email", "-format", "csv"},
+				"id,name,email\n1,Acme,info@acme.test\n2,Alice,alice@acme.test\n3,Bob,\n",
+			},
+			{
+				[]string{"search-read", "res.partner", "[('name', '=', 'Bob')]", "-fields", "name", "-format", "ndjson"},
+				`{"id":3,"name":"Bob"}` + "\n",
+			},
+			{
+				[]string{"fields", "res.partner", "name", "parent_id", "-attributes", "type,relation"},
+				"name       type      relation\nname       char      \nparent_id  many2one  res.partner\n",
+			},
+			{[]string{"call", "res.partner", "search_count", "-kwargs", `{"domain": [["id", "in", [1, 3]]]}`}, "2\n"},
+			{[]string{"call", "res.partner", "read", "-ids", "3", "-kwargs", `{"fields": ["name"]}`}, "id  name\n3   Bob\n"},
+		}
+		for _, tt := range tests {
+			code, stdout, stderr := runCmd(vars, tt.args...)
+			if code != 0 || stdout != tt.want {
+				t.Errorf("%s: %v: exit %d, stdout %q, want %q; stderr %s", transport, tt.args, code, stdout, tt.want, stderr)
+			}
+		}
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := `{
+		"default": "broken",
+		"profiles": {
+			"broken": {"url": "http://127.0.0.1:1", "database": "none"},
+			"test": {"url": "` + srv.URL + `", "database": "` + srv.Database + `",
+				"username": "` + srv.Username + `", "password": "` + srv.Password + `"}
+		}
+	}`
+	if err := os.WriteFile(path, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string]string{"ODOORPC_CONFIG": path}
+
+	if code, stdout, stderr := runCmd(vars, "count", "res.partner", "-profile", "test"); code != 0 || stdout != "3\n" {
+		t.Errorf("-profile: exit %d, stdout %q, stderr %s", code, stdout, stderr)
+	}
+	vars["ODOORPC_PROFILE"] = "test"
+	if code, stdout, stderr := runCmd(vars, "count", "res.partner"); code != 0 || stdout != "3\n" {
+		t.Errorf("ODOORPC_PROFILE: exit %d, stdout %q, stderr %s", code, stdout, stderr)
+	}
+	// Flags take precedence over the profile.
+	if code, _, _ := runCmd(vars, "count", "res.partner", "-db", "other"); code != 1 {
+		t.Errorf("-db other: exit %d, want 1", code)
+	}
+	vars["ODOORPC_PROFILE"] = "missing"
+	if code, _, stderr := runCmd(vars, "count", "res.partner"); code != 1 || !strings.Contains(stderr, `profile "missing" not found`) {
+		t.Errorf("missing profile: exit %d, stderr %s", code, stderr)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	vars := serverVars(srv, odoorpc.TransportJSONRPC)
+	tests := [][]string{
+		{},
+		{"frobnicate"},
+		{"read", "res.partner"},
+		{"read", "res.partner", "x"},
+		{"count", "res.partner", "name = Bob"},
+		{"count", "res.partner", "-format", "yaml"},
+		{"count", "res.partner", "-nope"},
+		{"count", "res.partner", "[]", "extra"},
+		{"count", "res.partner", "-transport", "soap"},
+	}
+	for _, args := range tests {
+		if code, _, stderr := runCmd(vars, args...); code != 2 || stderr == "" {
+			t.Errorf("%v: exit %d, stderr %q; want exit 2 with a message", args, code, stderr)
+		}
+	}
+	if code, _, _ := runCmd(vars, "count", "-h"); code != 0 {
+		t.Errorf("-h: exit %d, want 0", code)
+	}
+}
+
+func TestParseDomain(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in   string
+		want []any
+	}{
+		{"", []any{}},
+		{"[]", []any{}},
+		{"[('name', 'ilike', 'acme'), ('id', 'in', [1, 2])]", []any{[]any{"name", "ilike", "acme"}, []any{"id", "in", []any{1, 2}}}},
+		{`["|", ["a", "=", 1], ["b", "=", 2.5]]`, []any{"|", []any{"a", "=", 1}, []any{"b", "=", 2.5}}},
+	}
+	for _, tt := range tests {
+		got, err := parseDomain(tt.in)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseDomain(%q) = %#v, %v; want %#v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"name = acme", "[('name', 'lik', 'acme')]"} {
+		if _, err := parseDomain(in); err == nil {
+			t.Errorf("parseDomain(%q): expected an error", in)
+		}
+	}
+}
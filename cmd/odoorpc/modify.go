@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ppreeper/odoorpc"
+)
+
+// errAborted is returned when the user does not confirm an operation.
+var errAborted = errors.New("aborted")
+
+// modifyFlags are the flags of the commands that modify records.
+type modifyFlags struct {
+	yes    *bool
+	dryRun *bool
+	dry    *dryRun
+}
+
+func (cl *cmdline) modifyFlags() *modifyFlags {
+	return &modifyFlags{
+		yes:    cl.Bool("yes", false, "do not ask for confirmation"),
+		dryRun: cl.Bool("dry-run", false, "print the requests that would modify records instead of sending them"),
+	}
+}
+
+// connect returns a logged-in client. In dry-run mode its requests go
+// through a dryRun printing to stdout.
+func (mf *modifyFlags) connect(ctx context.Context, e *env, cl *cmdline) (odoorpc.Odoo, error) {
+	if *mf.dryRun {
+		mf.dry = &dryRun{w: e.stdout}
+		cl.conn.rt = mf.dry
+	}
+	o, err := cl.conn.connect(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	if mf.dry != nil {
+		mf.dry.secrets = []string{cl.conn.resolved.Password, cl.conn.resolved.APIKey}
+	}
+	return o, nil
+}
+
+// confirm prints the preview of an operation to stderr and, for destructive
+// operations, asks for confirmation on stdin unless -yes or -dry-run is set.
+func (mf *modifyFlags) confirm(e *env, destructive bool, preview string) error {
+	fmt.Fprintln(e.stderr, preview)
+	if !destructive || *mf.yes || *mf.dryRun {
+		return nil
+	}
+	fmt.Fprint(e.stderr, "Continue? [y/N] ")
+	line, _ := bufio.NewReader(e.stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return nil
+	}
+	return errAborted
+}
+
+// begin starts the modifying calls: in dry-run mode, the following requests
+// are printed instead of sent.
+func (mf *modifyFlags) begin() {
+	if mf.dry != nil {
+		mf.dry.armed.Store(true)
+	}
+}
+
+// failed reports whether a modifying call failed. In dry-run mode the calls
+// fail once their request is printed, which is not a failure.
+func (mf *modifyFlags) failed(err error) bool {
+	return err != nil && mf.dry == nil
+}
+
+func runCreate(ctx context.Context, e *env, cl *cmdline) error {
+	values := cl.String("values", "", "field values as a JSON object, or an array of objects")
+	file := cl.String("file", "", "read the records from a JSON or NDJSON `file`, - for stdin")
+	mf := cl.modifyFlags()
+	args, err := cl.parse(1, 1)
+	if err != nil {
+		return err
+	}
+	var records []map[string]any
+	switch {
+	case *values != "" && *file != "":
+		return usageError("-values and -file are exclusive")
+	case *values != "":
+		records, err = readObjects(strings.NewReader(*values))
+	case *file != "":
+		f, ferr := openInput(e, *file)
+		if ferr != nil {
+			return ferr
+		}
+		records, err = readObjects(f)
+		f.Close()
+	default:
+		return usageError("missing -values or -file")
+	}
+	if err != nil {
+		return usageError(err.Error())
+	}
+	o, err := mf.connect(ctx, e, cl)
+	if err != nil {
+		return err
+	}
+	if err := mf.confirm(e, false, fmt.Sprintf("create: %d record(s) in %s", len(records), args[0])); err != nil {
+		return err
+	}
+	mf.begin()
+	var ids []int
+	for i, rec := range records {
+		id, err := o.Create(ctx, args[0], rec)
+		if mf.failed(err) {
+			return fmt.Errorf("record %d: %w", i, err)
+		}
+		if mf.dry == nil {
+			ids = append(ids, id)
+		}
+	}
+	if mf.dry != nil {
+		return nil
+	}
+	return writeIDs(e.stdout, cl.conn.format, ids)
+}
+
+func runWrite(ctx context.Context, e *env, cl *cmdline) error {
+	domainFlag := cl.String("domain", "", "update the records matching `domain` instead of the given ids")
+	values := cl.String("values", "", "field values as a JSON object")
+	mf := cl.modifyFlags()
+	args, err := cl.parse(1, -1)
+	if err != nil {
+		return err
+	}
+	var vals map[string]any
+	if err := json.Unmarshal([]byte(*values), &vals); err != nil || len(vals) == 0 {
+		return usageError("-values must be a non-empty JSON object")
+	}
+	vals = normalizeJSON(vals).(map[string]any)
+	o, err := mf.connect(ctx, e, cl)
+	if err != nil {
+		return err
+	}
+	ids, err := targets(ctx, e, o, args, *domainFlag)
+	if err != nil {
+		return err
+	}
+	fields := make([]string, 0, len(vals))
+	for k := range vals {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	preview := fmt.Sprintf("write: %d record(s) of %s, fields %s", len(ids), args[0], strings.Join(fields, ", "))
+	if err := mf.confirm(e, true, preview); err != nil {
+		return err
+	}
+	mf.begin()
+	for _, id := range ids {
+		if _, err := o.Write(ctx, args[0], id, vals); mf.failed(err) {
+			return fmt.Errorf("record %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func runUnlink(ctx context.Context, e *env, cl *cmdline) error {
+	domainFlag := cl.String("domain", "", "delete the records matching `domain` instead of the given ids")
+	mf := cl.modifyFlags()
+	args, err := cl.parse(1, -1)
+	if err != nil {
+		return err
+	}
+	o, err := mf.connect(ctx, e, cl)
+	if err != nil {
+		return err
+	}
+	ids, err := targets(ctx, e, o, args, *domainFlag)
+	if err != nil {
+		return err
+	}
+	if err := mf.confirm(e, true, fmt.Sprintf("unlink: %d record(s) of %s", len(ids), args[0])); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	mf.begin()
+	if _, err := o.Unlink(ctx, args[0], ids); mf.failed(err) {
+		return err
+	}
+	return nil
+}
+
+func runLoad(ctx context.Context, e *env, cl *cmdline) error {
+	format := cl.String("input-format", "", "input format: csv, json or ndjson (default from the file extension)")
+	chunkSize := cl.Int("chunk-size", odoorpc.DefaultLoadChunkSize, "number of rows per load call")
+	mf := cl.modifyFlags()
+	args, err := cl.parse(2, 2)
+	if err != nil {
+		return err
+	}
+	inFormat, err := inputFormat(args[1], *format)
+	if err != nil {
+		return err
+	}
+	f, err := openInput(e, args[1])
+	if err != nil {
+		return err
+	}
+	header, rows, err := readTable(f, inFormat)
+	f.Close()
+	if err != nil {
+		return usageError(err.Error())
+	}
+	if *chunkSize <= 0 {
+		return usageError("-chunk-size must be positive")
+	}
+	// Rows with an id update the records they identify.
+	updates := slices.Contains(header, "id") || slices.Contains(header, ".id")
+	if updates && args[1] == "-" && !*mf.yes && !*mf.dryRun {
+		// stdin holds the rows: there is no answer to read.
+		return usageError("rows with an id update records and cannot be confirmed when read from stdin: use -yes")
+	}
+	o, err := mf.connect(ctx, e, cl)
+	if err != nil {
+		return err
+	}
+
+	preview := fmt.Sprintf("load: %d row(s) into %s, fields %s", len(rows), args[0], strings.Join(header, ", "))
+	if updates {
+		preview += " (rows with an id update existing records)"
+	}
+	if err := mf.confirm(e, updates, preview); err != nil {
+		return err
+	}
+	mf.begin()
+	if mf.dry != nil {
+		for start := 0; start < len(rows); start += *chunkSize {
+			// The request is printed, not sent, so the call always fails.
+			_, _ = o.Load(ctx, args[0], header, rows[start:min(start+*chunkSize, len(rows))])
+		}
+		return nil
+	}
+	res, err := odoorpc.BulkLoad(ctx, o, args[0], header, rows, odoorpc.BulkLoadOptions{ChunkSize: *chunkSize})
+	for _, m := range res.Messages {
+		fmt.Fprintf(e.stderr, "%s: %s\n", m.Type, m)
+	}
+	if err != nil {
+		return err
+	}
+	return writeIDs(e.stdout, cl.conn.format, res.IDs)
+}
+
+// targets returns the ids of the existing records selected by the id
+// arguments after the model, or by domain. One of them is required, so that
+// an empty selection never means all records; "[]" selects all records.
+func targets(ctx context.Context, e *env, o odoorpc.Odoo, args []string, domain string) ([]int, error) {
+	model := args[0]
+	switch {
+	case len(args) > 1 && domain != "":
+		return nil, usageError("ids and -domain are exclusive")
+	case domain != "":
+		d, err := parseDomain(domain)
+		if err != nil {
+			return nil, err
+		}
+		return o.Search(ctx, model, d)
+	case len(args) > 1:
+		ids, err := parseIDs(args[1:])
+		if err != nil {
+			return nil, err
+		}
+		// Archived records are selected by id too.
+		result, err := o.CallMethod(ctx, model, "search", nil, map[string]any{
+			"domain":  []any{[]any{"id", "in", ids}},
+			"context": map[string]any{"active_test": false},
+		})
+		if err != nil {
+			return nil, err
+		}
+		found, err := toIDs(result)
+		if err != nil {
+			return nil, err
+		}
+		if missing := len(ids) - len(found); missing > 0 {
+			fmt.Fprintf(e.stderr, "%d of the ids do not exist\n", missing)
+		}
+		return found, nil
+	}
+	return nil, usageError("missing record ids or -domain")
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+// partnerNames returns the names of the partners of srv in id order.
+func partnerNames(srv *odoorpctest.Server) string {
+	var names []string
+	for _, rec := range srv.Records("res.partner") {
+		name, _ := rec["name"].(string)
+		if email, _ := rec["email"].(string); email != "" {
+			name += "<" + email + ">"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestModifyCommands(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	vars := serverVars(srv, odoorpc.TransportJSONRPC)
+
+	code, stdout, stderr := runCmd(vars, "create", "res.partner", "-values", `[{"name": "Carol"}, {"name": "Dan"}]`, "-format", "json")
+	if code != 0 || stdout != "[\n  4,\n  5\n]\n" || !strings.Contains(stderr, "create: 2 record(s) in res.partner") {
+		t.Fatalf("create: exit %d, stdout %q, stderr %s", code, stdout, stderr)
+	}
+
+	// write asks for confirmation.
+	code, _, stderr = runInput(vars, "n\n", "write", "res.partner", "3", "-values", `{"email": "bob@example.test"}`)
+	if code != 1 || !strings.Contains(stderr, "write: 1 record(s) of res.partner, fields email") || !strings.Contains(stderr, "aborted") {
+		t.Errorf("declined write: exit %d, stderr %s", code, stderr)
+	}
+	if code, _, stderr := runInput(vars, "y\n", "write", "res.partner", "3", "-values", `{"email": "bob@example.test"}`); code != 0 {
+		t.Errorf("confirmed write: exit %d, stderr %s", code, stderr)
+	}
+	if want := "Acme<info@acme.test>,Alice<alice@acme.test>,Bob<bob@example.test>,Carol,Dan"; partnerNames(srv) != want {
+		t.Fatalf("after write: %s, want %s", partnerNames(srv), want)
+	}
+
+	if code, _, stderr := runCmd(vars, "unlink", "res.partner", "-domain", "[('name', 'in', ['Carol', 'Dan'])]", "-yes"); code != 0 ||
+		!strings.Contains(stderr, "unlink: 2 record(s) of res.partner") {
+		t.Errorf("unlink: exit %d, stderr %s", code, stderr)
+	}
+	if code, _, _ := runCmd(vars, "unlink", "res.partner", "-yes"); code != 2 {
+		t.Errorf("unlink without ids or domain: exit %d, want 2", code)
+	}
+
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.csv")
+	bad := filepath.Join(dir, "bad.ndjson")
+	if err := os.WriteFile(good, []byte("name,email\nEve,eve@example.test\nFay,\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte(`{"name": "Gus"}`+"\n"+`{"email": "nobody@example.test"}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if code, stdout, stderr := runCmd(vars, "load", "res.partner", good, "-format", "ndjson"); code != 0 || stdout != "6\n7\n" {
+		t.Errorf("load: exit %d, stdout %q, stderr %s", code, stdout, stderr)
+	}
+	code, _, stderr = runCmd(vars, "load", "res.partner", bad)
+	if code != 1 || !strings.Contains(stderr, "error: row 1") {
+		t.Errorf("rejected load: exit %d, stderr %s", code, stderr)
+	}
+	if want := "Acme<info@acme.test>,Alice<alice@acme.test>,Bob<bob@example.test>,Eve<eve@example.test>,Fay"; partnerNames(srv) != want {
+		t.Errorf("after load: %s, want %s", partnerNames(srv), want)
+	}
+}
+
+func TestArchivedTargets(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	srv.DefineModel("res.partner.category", map[string]odoorpctest.Field{
+		"name":   {Type: "char", Required: true},
+		"active": {Type: "boolean"},
+	})
+	if _, err := srv.Seed("res.partner.category", map[string]any{"name": "Old", "active": false}); err != nil {
+		t.Fatal(err)
+	}
+	vars := serverVars(srv, odoorpc.TransportXMLRPC)
+	code, _, stderr := runCmd(vars, "write", "res.partner.category", "1", "-values", `{"name": "Older"}`, "-yes")
+	if code != 0 || strings.Contains(stderr, "do not exist") {
+		t.Errorf("write of an archived record: exit %d, stderr %s", code, stderr)
+	}
+	if name := srv.Records("res.partner.category")[0]["name"]; name != "Older" {
+		t.Errorf("archived record name %v, want Older", name)
+	}
+	if code, _, stderr := runCmd(vars, "unlink", "res.partner.category", "1", "-yes"); code != 0 || len(srv.Records("res.partner.category")) != 0 {
+		t.Errorf("unlink of an archived record: exit %d, stderr %s", code, stderr)
+	}
+}
+
+func TestLoadStdinNeedsYes(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	vars := serverVars(srv, odoorpc.TransportJSONRPC)
+	rows := "id,name\n__export__.bob,Robert\n"
+	code, _, stderr := runInput(vars, rows, "load", "res.partner", "-", "-input-format", "csv")
+	if code != 2 || !strings.Contains(stderr, "use -yes") {
+		t.Errorf("load of updates from stdin: exit %d, stderr %s", code, stderr)
+	}
+	if code, _, stderr := runInput(vars, "name\nZoe\n", "load", "res.partner", "-", "-input-format", "csv"); code != 0 {
+		t.Errorf("load of new records from stdin: exit %d, stderr %s", code, stderr)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	t.Parallel()
+	srv := newTestServer(t)
+	csvFile := filepath.Join(t.TempDir(), "rows.csv")
+	if err := os.WriteFile(csvFile, []byte("name\nZed\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	endpoints := map[string]string{
+		odoorpc.TransportJSONRPC: "/jsonrpc",
+		odoorpc.TransportXMLRPC:  "/xmlrpc/2/object",
+		odoorpc.TransportJSON2:   "/json/2/res.partner/",
+	}
+	before := partnerNames(srv)
+	for transport, endpoint := range endpoints {
+		vars := serverVars(srv, transport)
+		tests := [][]string{
+			{"create", "res.partner", "-values", `{"name": "Zoe"}`},
+			{"write", "res.partner", "1", "2", "-values", `{"name": "Renamed"}`},
+			{"unlink", "res.partner", "-domain", "[]"},
+			{"load", "res.partner", csvFile},
+		}
+		for _, args := range tests {
+			code, stdout, stderr := runCmd(vars, append(args, "-dry-run")...)
+			if code != 0 {
+				t.Errorf("%s: %v: exit %d, stderr %s", transport, args, code, stderr)
+				continue
+			}
+			requests := strings.Count(stdout, "POST ")
+			if want := map[string]int{"write": 2}[args[0]]; requests != max(want, 1) {
+				t.Errorf("%s: %v: printed %d requests:\n%s", transport, args, requests, stdout)
+			}
+			if !strings.Contains(stdout, endpoint) || !strings.Contains(stdout, args[0]) {
+				t.Errorf("%s: %v: unexpected payload:\n%s", transport, args, stdout)
+			}
+			if strings.Contains(stdout, srv.Password) || strings.Contains(stdout, srv.APIKey) {
+				t.Errorf("%s: %v: credentials not redacted:\n%s", transport, args, stdout)
+			}
+		}
+	}
+	if after := partnerNames(srv); after != before {
+		t.Errorf("dry runs modified records: %s, want %s", after, before)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// formats are the supported output formats.
+var formats = []string{"table", "json", "ndjson", "csv"}
+
+// checkFormat validates an output format.
+func checkFormat(format string) error {
+	if !slices.Contains(formats, format) {
+		return usageError(fmt.Sprintf("unknown format %q: use one of %s", format, strings.Join(formats, ", ")))
+	}
+	return nil
+}
+
+// writeRecords prints records in format. The columns are printed in order
+// in table and CSV output; when columns is empty, the keys of the records
+// are used with id first.
+func writeRecords(w io.Writer, format string, columns []string, records []map[string]any) error {
+	if len(columns) == 0 {
+		columns = recordColumns(records)
+	}
+	switch format {
+	case "json":
+		if records == nil {
+			records = []map[string]any{}
+		}
+		return writeJSON(w, records)
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, rec := range records {
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+		row := make([]string, len(columns))
+		for _, rec := range records {
+			for i, c := range columns {
+				row[i] = cell(rec[c])
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	row := make([]string, len(columns))
+	for _, rec := range records {
+		for i, c := range columns {
+			// Tabs and newlines would break the alignment.
+			row[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cell(rec[c]))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeIDs prints record ids: as a JSON array in json output, one per line
+// in ndjson output and as an id column otherwise.
+func writeIDs(w io.Writer, format string, ids []int) error {
+	switch format {
+	case "json":
+		if ids == nil {
+			ids = []int{}
+		}
+		return writeJSON(w, ids)
+	case "ndjson":
+		for _, id := range ids {
+			if _, err := fmt.Fprintln(w, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	records := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		records = append(records, map[string]any{"id": id})
+	}
+	return writeRecords(w, format, []string{"id"}, records)
+}
+
+// writeValue prints a single value: as JSON in json and ndjson output and
+// as text otherwise. Lists of records are printed with writeRecords.
+func writeValue(w io.Writer, format string, v any) error {
+	if records, ok := asRecords(v); ok {
+		return writeRecords(w, format, nil, records)
+	}
+	switch format {
+	case "json":
+		return writeJSON(w, v)
+	case "ndjson":
+		return json.NewEncoder(w).Encode(v)
+	}
+	switch v.(type) {
+	case []any, map[string]any:
+		return writeJSON(w, v)
+	}
+	_, err := fmt.Fprintln(w, cell(v))
+	return err
+}
+
+// writeJSON prints v as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// asRecords converts a non-empty list of objects into records.
+func asRecords(v any) ([]map[string]any, bool) {
+	list, ok := v.([]any)
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	records := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		rec, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		records = append(records, rec)
+	}
+	return records, true
+}
+
+// recordColumns returns the keys of records, sorted with id first.
+func recordColumns(records []map[string]any) []string {
+	seen := map[string]bool{}
+	var columns []string
+	for _, rec := range records {
+		for k := range rec {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	slices.SortFunc(columns, func(a, b string) int {
+		switch {
+		case a == b:
+			return 0
+		case a == "id":
+			return -1
+		case b == "id":
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	return columns
+}
+
+// cell formats a field value as text: many2one values by display name,
+// other lists comma-separated and false as empty.
+func cell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if !v {
+			return ""
+		}
+		return "true"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		if len(v) == 2 {
+			if name, ok := v[1].(string); ok && isNumber(v[0]) {
+				return name
+			}
+		}
+		parts := make([]string, 0, len(v))
+		for _, x := range v {
+			parts = append(parts, cell(x))
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+// isNumber reports whether v is a number decoded from JSON or XML-RPC.
+func isNumber(v any) bool {
+	switch v.(type) {
+	case float64, int, int64:
+		return true
+	}
+	return false
+}
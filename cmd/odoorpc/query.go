@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ppreeper/odoosearchdomain"
+)
+
+func runSearch(ctx context.Context, e *env, cl *cmdline) error {
+	limit := cl.Int("limit", 0, "maximum number of ids, 0 for all")
+	offset := cl.Int("offset", 0, "number of records to skip")
+	order := cl.String("order", "", "sort order, e.g. \"name desc\"")
+	args, err := cl.parse(1, 2)
+	if err != nil {
+		return err
+	}
+	domain, err := domainArg(args, 1)
+	if err != nil {
+		return err
+	}
+	o, err := cl.conn.connect(ctx, e)
+	if err != nil {
+		return err
+	}
+	kwargs := map[string]any{"domain": domain}
+	if *limit > 0 {
+		kwargs["limit"] = *limit
+	}
+	if *offset > 0 {
+		kwargs["offset"] = *offset
+	}
+	if *order != "" {
+		kwargs["order"] = *order
+	}
+	res, err := o.CallMethod(ctx, args[0], "search", nil, kwargs)
+	if err != nil {
+		return err
+	}
+	ids, err := toIDs(res)
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+	return writeIDs(e.stdout, cl.conn.format, ids)
+}
+
+func runRead(ctx context.Context, e *env, cl *cmdline) error {
+	fields := cl.String("fields", "", "comma-separated field names (default all)")
+	args, err := cl.parse(2, -1)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(args[1:])
+	if err != nil {
+		return err
+	}
+	o, err := cl.conn.connect(ctx, e)
+	if err != nil {
+		return err
+	}
+	names := splitList(*fields)
+	records, err := o.Read(ctx, args[0], ids, names...)
+	if err != nil {
+		return err
+	}
+	return writeRecords(e.stdout, cl.conn.format, columns(names), records)
+}
+
+func runSearchRead(ctx context.Context, e *env, cl *cmdline) error {
+	fields := cl.String("fields", "", "comma-separated field names (default all)")
+	limit := cl.Int("limit", 0, "maximum number of records, 0 for all")
+	offset := cl.Int("offset", 0, "number of records to skip")
+	args, err := cl.parse(1, 2)
+	if err != nil {
+		return err
+	}
+	domain, err := domainArg(args, 1)
+	if err != nil {
+		return err
+	}
+	o, err := cl.conn.connect(ctx, e)
+	if err != nil {
+		return err
+	}
+	names := splitList(*fields)
+	records, err := o.SearchRead(ctx, args[0], *offset, *limit, names, domain)
+	if err != nil {
+		return err
+	}
+	return writeRecords(e.stdout, cl.conn.format, columns(names), records)
+}
+
+func runCount(ctx context.Context, e *env, cl *cmdline) error {
+	args, err := cl.parse(1, 2)
+	if err != nil {
+		return err
+	}
+	domain, err := domainArg(args, 1)
+	if err != nil {
+		return err
+	}
+	o, err := cl.conn.connect(ctx, e)
+	if err != nil {
+		return err
+	}
+	count, err := o.Count(ctx, args[0], domain)
+	if err != nil {
+		return err
+	}
+	return writeValue(e.stdout, cl.conn.format, count)
+}
+
+func runFields(ctx context.Context, e *env, cl *cmdline) error {
+	attributes := cl.String("attributes", "string,type,relation,required,readonly", "comma-separated field attributes")
+	args, err := cl.parse(1, -1)
+	if err != nil {
+		return err
+	}
+	o, err := cl.conn.connect(ctx, e)
+	if err != nil {
+		return err
+	}
+	attrs := splitList(*attributes)
+	fields, err := o.FieldsGet(ctx, args[0], args[1:], attrs...)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	records := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		rec := map[string]any{"name": name}
+		if desc, ok := fields[name].(map[string]any); ok {
+			for k, v := range desc {
+				rec[k] = v
+			}
+		}
+		records = append(records, rec)
+	}
+	var cols []string
+	if len(attrs) > 0 {
+		cols = append([]string{"name"}, attrs...)
+	}
+	return writeRecords(e.stdout, cl.conn.format, cols, records)
+}
+
+func runCall(ctx context.Context, e *env, cl *cmdline) error {
+	idList := cl.String("ids", "", "comma-separated ids of the records to call the method on")
+	kwargsJSON := cl.String("kwargs", "", "keyword arguments as a JSON object")
+	args, err := cl.parse(2, 2)
+	if err != nil {
+		return err
+	}
+	var ids []int
+	if *idList != "" {
+		if ids, err = parseIDs([]string{*idList}); err != nil {
+			return err
+		}
+	}
+	var kwargs map[string]any
+	if *kwargsJSON != "" {
+		if err := json.Unmarshal([]byte(*kwargsJSON), &kwargs); err != nil {
+			return usageError(fmt.Sprintf("invalid -kwargs: %v", err))
+		}
+		kwargs = normalizeJSON(kwargs).(map[string]any)
+	}
+	o, err := cl.conn.connect(ctx, e)
+	if err != nil {
+		return err
+	}
+	res, err := o.CallMethod(ctx, args[0], args[1], ids, kwargs)
+	if err != nil {
+		return err
+	}
+	return writeValue(e.stdout, cl.conn.format, res)
+}
+
+// domainArg parses the optional domain at args[i].
+func domainArg(args []string, i int) ([]any, error) {
+	if i >= len(args) {
+		return []any{}, nil
+	}
+	return parseDomain(args[i])
+}
+
+// parseDomain parses a domain in Odoo syntax, e.g.
+// "[('name', 'ilike', 'acme')]", or in JSON.
+func parseDomain(s string) ([]any, error) {
+	var domain []any
+	if err := json.Unmarshal([]byte(s), &domain); err == nil {
+		return normalizeJSON(domain).([]any), nil
+	}
+	domain, err := odoosearchdomain.ParseDomain(s)
+	if err != nil {
+		return nil, usageError(fmt.Sprintf("invalid domain %q: %v", s, err))
+	}
+	// The parser ignores what it does not recognize; an empty result is only
+	// valid for an empty domain.
+	if len(domain) == 0 && strings.Trim(s, "[]() \t\r\n") != "" {
+		return nil, usageError(fmt.Sprintf("invalid domain %q", s))
+	}
+	return domain, nil
+}
+
+// normalizeJSON converts the whole numbers decoded from JSON into ints, so
+// that they are sent as integers by every transport.
+func normalizeJSON(v any) any {
+	switch v := v.(type) {
+	case float64:
+		if v == float64(int(v)) {
+			return int(v)
+		}
+	case []any:
+		for i, x := range v {
+			v[i] = normalizeJSON(x)
+		}
+	case map[string]any:
+		for k, x := range v {
+			v[k] = normalizeJSON(x)
+		}
+	}
+	return v
+}
+
+// parseIDs parses record ids given as separate or comma-separated
+// arguments.
+func parseIDs(args []string) ([]int, error) {
+	var ids []int
+	for _, arg := range args {
+		for _, s := range splitList(arg) {
+			id, err := strconv.Atoi(s)
+			if err != nil || id <= 0 {
+				return nil, usageError(fmt.Sprintf("invalid record id %q", s))
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// toIDs converts a list of ids decoded from JSON or XML-RPC.
+func toIDs(v any) ([]int, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", v)
+	}
+	ids := make([]int, 0, len(list))
+	for _, x := range list {
+		switch x := x.(type) {
+		case float64:
+			ids = append(ids, int(x))
+		case int64:
+			ids = append(ids, int(x))
+		case int:
+			ids = append(ids, x)
+		default:
+			return nil, fmt.Errorf("unexpected id type %T in response", x)
+		}
+	}
+	return ids, nil
+}
+
+// splitList splits a comma-separated list, ignoring empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// columns returns the table columns for the requested fields: id followed
+// by the fields, or nil to use the fields of the records.
+func columns(fields []string) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+	cols := []string{"id"}
+	for _, f := range fields {
+		if f != "id" {
+			cols = append(cols, f)
+		}
+	}
+	return cols
+}
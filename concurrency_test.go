@@ -0,0 +1,91 @@
+package odoorpc_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	odoojrpc "github.com/ppreeper/odoorpc/odoojrpc"
+	odoojson "github.com/ppreeper/odoorpc/odoojson"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+	odooxmlrpc "github.com/ppreeper/odoorpc/odooxmlrpc"
+)
+
+// TestConcurrentCallsAndLogin shares one client of each transport between
+// goroutines that make calls while others log in again. Run with -race.
+func TestConcurrentCallsAndLogin(t *testing.T) {
+	t.Parallel()
+	srv := odoorpctest.NewServer()
+	t.Cleanup(srv.Close) // outlives the parallel subtests
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	clients := map[string]odoorpc.Odoo{
+		odoorpc.TransportJSONRPC: odoojrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password),
+		odoorpc.TransportXMLRPC: odooxmlrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password),
+		odoorpc.TransportJSON2: odoojson.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).WithAPIKey(srv.APIKey),
+	}
+	for name, o := range clients {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			if err := o.Login(ctx); err != nil {
+				t.Fatalf("login: %v", err)
+			}
+			var wg sync.WaitGroup
+			for i := range 16 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for range 10 {
+						if i%4 == 0 {
+							if err := o.Login(ctx); err != nil {
+								t.Errorf("login: %v", err)
+							}
+							continue
+						}
+						if n, err := o.Count(ctx, "res.partner"); err != nil || n != 1 {
+							t.Errorf("count: got %d, %v", n, err)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+// TestConcurrentFirstLogin logs in from many goroutines on fresh clients, so
+// that the URL and HTTP client are built concurrently.
+func TestConcurrentFirstLogin(t *testing.T) {
+	t.Parallel()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+
+	clients := map[string]odoorpc.Odoo{
+		odoorpc.TransportJSONRPC: odoojrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password),
+		odoorpc.TransportXMLRPC: odooxmlrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password),
+		odoorpc.TransportJSON2: odoojson.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).WithAPIKey(srv.APIKey),
+	}
+	for name, o := range clients {
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := loginAndCount(o); err != nil {
+					t.Errorf("%s: %v", name, err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
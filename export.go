@@ -0,0 +1,145 @@
+package odoorpc
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// DefaultExportChunkSize is the number of records exported per export_data
+// call when ExportOptions.ChunkSize is not set.
+const DefaultExportChunkSize = 500
+
+// ExportOptions selects the records of an export.
+type ExportOptions struct {
+	// IDs are the records to export, in order. When nil, the records
+	// matching Domain are exported; an empty non-nil IDs exports nothing.
+	IDs []int
+	// Domain selects the records when IDs is nil; nil selects all records.
+	Domain []any
+	// ChunkSize is the number of records per export_data call. Defaults to
+	// DefaultExportChunkSize.
+	ChunkSize int
+}
+
+// ParseExportData extracts the rows from the result of export_data.
+func ParseExportData(result any) ([][]any, error) {
+	m, ok := result.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", result)
+	}
+	datas, ok := m["datas"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("datas not found in response")
+	}
+	rows := make([][]any, 0, len(datas))
+	for _, d := range datas {
+		row, ok := d.([]any)
+		if !ok {
+			return nil, fmt.Errorf("unexpected row type %T in response", d)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ExportEach exports the field paths of the records selected by opts with
+// export_data and calls fn with each row, fetching the records in chunks.
+// Paths traverse relations with "/", e.g. "partner_id/name", and "id"
+// exports external ids. A record exports on several rows when a path goes
+// through a one2many or many2many field.
+func ExportEach(ctx context.Context, o Odoo, model string, fields []string, opts ExportOptions, fn func(row []any) error) error {
+	ids := opts.IDs
+	if ids == nil {
+		var err error
+		if ids, err = o.Search(ctx, model, opts.Domain); err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+	}
+	size := opts.ChunkSize
+	if size <= 0 {
+		size = DefaultExportChunkSize
+	}
+	for start := 0; start < len(ids); start += size {
+		rows, err := o.ExportData(ctx, model, ids[start:min(start+size, len(ids))], fields)
+		if err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Export returns the rows ExportEach produces.
+func Export(ctx context.Context, o Odoo, model string, fields []string, opts ExportOptions) ([][]any, error) {
+	var rows [][]any
+	err := ExportEach(ctx, o, model, fields, opts, func(row []any) error {
+		rows = append(rows, row)
+		return nil
+	})
+	return rows, err
+}
+
+// ExportCSV writes the export as CSV to w, with the field paths as header.
+// Empty values are written as empty cells.
+func ExportCSV(ctx context.Context, o Odoo, w io.Writer, model string, fields []string, opts ExportOptions) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(fields); err != nil {
+		return err
+	}
+	record := make([]string, len(fields))
+	err := ExportEach(ctx, o, model, fields, opts, func(row []any) error {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) {
+				record[i] = exportCell(row[i])
+			}
+		}
+		return cw.Write(record)
+	})
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// ExportNDJSON writes the export to w as newline-delimited JSON, one object
+// per row keyed by field path.
+func ExportNDJSON(ctx context.Context, o Odoo, w io.Writer, model string, fields []string, opts ExportOptions) error {
+	enc := json.NewEncoder(w)
+	return ExportEach(ctx, o, model, fields, opts, func(row []any) error {
+		obj := make(map[string]any, len(fields))
+		for i, f := range fields {
+			if i < len(row) {
+				obj[f] = row[i]
+			}
+		}
+		return enc.Encode(obj)
+	})
+}
+
+// exportCell formats an exported value as a CSV cell.
+func exportCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if !v {
+			return ""
+		}
+		return "True"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
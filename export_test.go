@@ -0,0 +1,83 @@
+package odoorpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("sale.order.line", map[string]odoorpctest.Field{
+		"name":    {Type: "char"},
+		"qty":     {Type: "float"},
+		"product": {Type: "many2one", Relation: "product.product"},
+	})
+	srv.DefineModel("sale.order", map[string]odoorpctest.Field{
+		"name":       {Type: "char"},
+		"partner_id": {Type: "many2one", Relation: "res.partner"},
+		"order_line": {Type: "one2many", Relation: "sale.order.line"},
+		"note":       {Type: "char"},
+	})
+	partners, _ := srv.Seed("res.partner", map[string]any{"name": "Acme"})
+	products, _ := srv.Seed("product.product", map[string]any{"name": "Desk"}, map[string]any{"name": "Chair"})
+	lines, _ := srv.Seed("sale.order.line",
+		map[string]any{"name": "l1", "qty": 1.0, "product": products[0]},
+		map[string]any{"name": "l2", "qty": 4.0, "product": products[1]},
+	)
+	if _, err := srv.Seed("sale.order",
+		map[string]any{"name": "SO1", "partner_id": partners[0], "order_line": lines},
+		map[string]any{"name": "SO2"},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := []string{"name", "partner_id/name", "order_line/product/name", "order_line/qty"}
+	wantCSV := "name,partner_id/name,order_line/product/name,order_line/qty\n" +
+		"SO1,Acme,Desk,1\n" +
+		",,Chair,4\n" +
+		"SO2,,,\n"
+
+	for name, o := range interceptedClients(t, srv) {
+		rows, err := odoorpc.Export(ctx, o, "sale.order", fields, odoorpc.ExportOptions{ChunkSize: 1})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(rows) != 3 || rows[0][0] != "SO1" || rows[1][2] != "Chair" || rows[2][0] != "SO2" {
+			t.Errorf("%s: rows %v", name, rows)
+		}
+
+		var buf bytes.Buffer
+		if err := odoorpc.ExportCSV(ctx, o, &buf, "sale.order", fields, odoorpc.ExportOptions{}); err != nil {
+			t.Fatalf("%s: csv: %v", name, err)
+		}
+		if buf.String() != wantCSV {
+			t.Errorf("%s: csv:\n%s\nwant:\n%s", name, buf.String(), wantCSV)
+		}
+
+		buf.Reset()
+		opts := odoorpc.ExportOptions{Domain: []any{[]any{"name", "=", "SO2"}}}
+		if err := odoorpc.ExportNDJSON(ctx, o, &buf, "sale.order", []string{"id", "name"}, opts); err != nil {
+			t.Fatalf("%s: ndjson: %v", name, err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		var obj map[string]any
+		if len(lines) != 1 || json.Unmarshal([]byte(lines[0]), &obj) != nil || obj["name"] != "SO2" || obj["id"] != "__export__.sale_order_2" {
+			t.Errorf("%s: ndjson %q", name, buf.String())
+		}
+
+		// An empty selection exports nothing, not the whole model.
+		rows, err = odoorpc.Export(ctx, o, "sale.order", fields, odoorpc.ExportOptions{IDs: []int{}})
+		if err != nil || len(rows) != 0 {
+			t.Errorf("%s: export of no ids = %v, %v", name, rows, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package expvarmetrics implements odoorpc.Metrics with the standard library
+// expvar package, so call metrics can be exposed on /debug/vars without a
+// dependency on a metrics system.
+//
+//	m := expvarmetrics.New("odoo")
+//	o := odoojrpc.NewOdoo().WithMetrics(m)
+//
+// The published variable is a JSON object of counters keyed by call, where a
+// call is "model.method" for object calls and "service.method" otherwise:
+//
+//	{
+//	  "calls":           {"res.partner.search_read": 12, ...},
+//	  "errors":          {"res.partner.write": 1, ...},
+//	  "errors_by_class": {"odoo.exceptions.AccessError": 1, ...},
+//	  "retries":         {"res.partner.write": 1, ...},
+//	  "request_bytes":   {"res.partner.search_read": 5120, ...},
+//	  "response_bytes":  {"res.partner.search_read": 40960, ...},
+//	  "latency_seconds": {"res.partner.search_read": {"buckets": {...}, "count": 12, "sum": 0.84}, ...}
+//	}
+//
+// Latency buckets are cumulative: each holds the number of calls that took at
+// most its upper bound in seconds.
+package expvarmetrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/ppreeper/odoorpc"
+)
+
+// Compile-time assertion that *Metrics implements odoorpc.Metrics.
+var _ odoorpc.Metrics = (*Metrics)(nil)
+
+// DefaultBuckets are the latency histogram upper bounds, in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics collects odoorpc call metrics in expvar variables. It is safe for
+// concurrent use.
+type Metrics struct {
+	root          *expvar.Map
+	calls         *expvar.Map
+	errors        *expvar.Map
+	errorsByClass *expvar.Map
+	retries       *expvar.Map
+	requestBytes  *expvar.Map
+	responseBytes *expvar.Map
+	latency       *expvar.Map
+
+	mu sync.Mutex // serialises histogram creation
+}
+
+// New returns a Metrics published as the expvar variable name. Like
+// expvar.Publish it panics if name is already in use. An empty name returns
+// an unpublished Metrics, which can still be read with String.
+func New(name string) *Metrics {
+	m := &Metrics{
+		root:          new(expvar.Map).Init(),
+		calls:         new(expvar.Map).Init(),
+		errors:        new(expvar.Map).Init(),
+		errorsByClass: new(expvar.Map).Init(),
+		retries:       new(expvar.Map).Init(),
+		requestBytes:  new(expvar.Map).Init(),
+		responseBytes: new(expvar.Map).Init(),
+		latency:       new(expvar.Map).Init(),
+	}
+	m.root.Set("calls", m.calls)
+	m.root.Set("errors", m.errors)
+	m.root.Set("errors_by_class", m.errorsByClass)
+	m.root.Set("retries", m.retries)
+	m.root.Set("request_bytes", m.requestBytes)
+	m.root.Set("response_bytes", m.responseBytes)
+	m.root.Set("latency_seconds", m.latency)
+	if name != "" {
+		expvar.Publish(name, m.root)
+	}
+	return m
+}
+
+// String returns the metrics as JSON. It implements expvar.Var.
+func (m *Metrics) String() string {
+	return m.root.String()
+}
+
+// ObserveCall implements odoorpc.Metrics.
+func (m *Metrics) ObserveCall(info odoorpc.CallInfo, result odoorpc.CallResult) {
+	key := callKey(info)
+	m.calls.Add(key, 1)
+	if info.Attempt > 0 {
+		m.retries.Add(key, 1)
+	}
+	if result.Err != nil {
+		m.errors.Add(key, 1)
+		m.errorsByClass.Add(result.ErrorClass, 1)
+	}
+	m.requestBytes.Add(key, result.RequestBytes)
+	m.responseBytes.Add(key, result.ResponseBytes)
+	m.histogram(key).observe(result.Duration.Seconds())
+}
+
+// histogram returns the latency histogram of key, creating it on first use.
+func (m *Metrics) histogram(key string) *histogram {
+	if h, ok := m.latency.Get(key).(*histogram); ok {
+		return h
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if h, ok := m.latency.Get(key).(*histogram); ok {
+		return h
+	}
+	h := newHistogram(DefaultBuckets)
+	m.latency.Set(key, h)
+	return h
+}
+
+// callKey names the call described by info.
+func callKey(info odoorpc.CallInfo) string {
+	if info.Model != "" {
+		return info.Model + "." + info.Method
+	}
+	if info.Service != "" {
+		return info.Service + "." + info.Method
+	}
+	return info.Method
+}
+
+// histogram is a cumulative latency histogram. It implements expvar.Var.
+type histogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	counts []int64 // counts[i] is the number of observations <= bounds[i]
+	count  int64
+	sum    float64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]int64, len(bounds))}
+}
+
+func (h *histogram) observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, b := range h.bounds {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// String implements expvar.Var.
+func (h *histogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	buckets := make(map[string]int64, len(h.bounds)+1)
+	for i, b := range h.bounds {
+		buckets[strconv.FormatFloat(b, 'g', -1, 64)] = h.counts[i]
+	}
+	buckets["+Inf"] = h.count
+	data, _ := json.Marshal(struct {
+		Buckets map[string]int64 `json:"buckets"`
+		Count   int64            `json:"count"`
+		Sum     float64          `json:"sum"`
+	}{buckets, h.count, math.Round(h.sum*1e6) / 1e6})
+	return string(data)
+}
@@ -0,0 +1,138 @@
+package expvarmetrics_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/expvarmetrics"
+	"github.com/ppreeper/odoorpc/odoojrpc"
+	"github.com/ppreeper/odoorpc/odoojson"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+	"github.com/ppreeper/odoorpc/odooxmlrpc"
+)
+
+// snapshot is the JSON form of the published metrics.
+type snapshot struct {
+	Calls          map[string]int64 `json:"calls"`
+	Errors         map[string]int64 `json:"errors"`
+	ErrorsByClass  map[string]int64 `json:"errors_by_class"`
+	Retries        map[string]int64 `json:"retries"`
+	RequestBytes   map[string]int64 `json:"request_bytes"`
+	ResponseBytes  map[string]int64 `json:"response_bytes"`
+	LatencySeconds map[string]struct {
+		Buckets map[string]int64 `json:"buckets"`
+		Count   int64            `json:"count"`
+		Sum     float64          `json:"sum"`
+	} `json:"latency_seconds"`
+}
+
+func decode(t *testing.T, v expvar.Var) snapshot {
+	t.Helper()
+	var s snapshot
+	if err := json.Unmarshal([]byte(v.String()), &s); err != nil {
+		t.Fatalf("decode %s: %v", v, err)
+	}
+	return s
+}
+
+func TestMetricsFromAllTransports(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := expvarmetrics.New("")
+	clients := []odoorpc.Odoo{
+		odoojrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+			WithMetrics(m),
+		odooxmlrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+			WithMetrics(m),
+		odoojson.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).WithAPIKey(srv.APIKey).
+			WithMetrics(m),
+	}
+	for _, o := range clients {
+		if err := o.Login(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := o.SearchRead(ctx, "res.partner", 0, 0, []string{"name"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := o.Read(ctx, "res.partner", []int{999}); err == nil {
+			t.Fatal("expected read error")
+		}
+	}
+
+	s := decode(t, m)
+	if got := s.Calls["res.partner.search_read"]; got != 3 {
+		t.Errorf("search_read calls: got %d, want 3", got)
+	}
+	if got := s.Errors["res.partner.read"]; got != 3 {
+		t.Errorf("read errors: got %d, want 3", got)
+	}
+	if s.Errors["res.partner.search_read"] != 0 {
+		t.Errorf("unexpected search_read errors: %v", s.Errors)
+	}
+	var classified int64
+	for _, n := range s.ErrorsByClass {
+		classified += n
+	}
+	if classified != 3 {
+		t.Errorf("errors by class: %v", s.ErrorsByClass)
+	}
+	if s.RequestBytes["res.partner.search_read"] == 0 || s.ResponseBytes["res.partner.search_read"] == 0 {
+		t.Errorf("expected payload sizes, got %v / %v", s.RequestBytes, s.ResponseBytes)
+	}
+	if h := s.LatencySeconds["res.partner.search_read"]; h.Count != 3 || h.Buckets["+Inf"] != 3 {
+		t.Errorf("latency histogram: %+v", h)
+	}
+}
+
+func TestRetriesAndBuckets(t *testing.T) {
+	t.Parallel()
+	m := expvarmetrics.New("")
+	info := odoorpc.CallInfo{Service: "object", Model: "res.partner", Method: "write"}
+	m.ObserveCall(info, odoorpc.CallResult{Duration: 20 * time.Millisecond})
+	info.Attempt = 1
+	m.ObserveCall(info, odoorpc.CallResult{
+		Duration:   2 * time.Second,
+		Err:        errors.New("denied"),
+		ErrorClass: "odoo.exceptions.AccessDenied",
+	})
+	m.ObserveCall(odoorpc.CallInfo{Service: "common", Method: "login"}, odoorpc.CallResult{})
+
+	s := decode(t, m)
+	if s.Calls["res.partner.write"] != 2 || s.Retries["res.partner.write"] != 1 || s.Calls["common.login"] != 1 {
+		t.Errorf("calls %v, retries %v", s.Calls, s.Retries)
+	}
+	if s.ErrorsByClass["odoo.exceptions.AccessDenied"] != 1 {
+		t.Errorf("errors by class: %v", s.ErrorsByClass)
+	}
+	h := s.LatencySeconds["res.partner.write"]
+	if h.Buckets["0.025"] != 1 || h.Buckets["2.5"] != 2 || h.Buckets["+Inf"] != 2 || h.Sum != 2.02 {
+		t.Errorf("histogram: %+v", h)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	t.Parallel()
+	m := expvarmetrics.New("odoorpc_test_metrics")
+	m.ObserveCall(odoorpc.CallInfo{Model: "res.partner", Method: "search"}, odoorpc.CallResult{})
+	v := expvar.Get("odoorpc_test_metrics")
+	if v == nil {
+		t.Fatal("metrics were not published")
+	}
+	if s := decode(t, v); s.Calls["res.partner.search"] != 1 {
+		t.Errorf("published calls: %v", s.Calls)
+	}
+}
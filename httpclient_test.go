@@ -0,0 +1,64 @@
+package odoorpc_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	odoojrpc "github.com/ppreeper/odoorpc/odoojrpc"
+	odoojson "github.com/ppreeper/odoorpc/odoojson"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+	odooxmlrpc "github.com/ppreeper/odoorpc/odooxmlrpc"
+)
+
+// countingTransport counts the requests sent through it.
+type countingTransport struct {
+	n atomic.Int64
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.n.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+
+	for _, transport := range []string{odoorpc.TransportJSONRPC, odoorpc.TransportXMLRPC, odoorpc.TransportJSON2} {
+		rt := &countingTransport{}
+		client := &http.Client{Transport: rt}
+		var o odoorpc.Odoo
+		switch transport {
+		case odoorpc.TransportJSONRPC:
+			o = odoojrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+				WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+				WithHTTPClient(client)
+		case odoorpc.TransportXMLRPC:
+			o = odooxmlrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+				WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+				WithHTTPClient(client)
+		case odoorpc.TransportJSON2:
+			o = odoojson.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+				WithDatabase(srv.Database).WithAPIKey(srv.APIKey).
+				WithHTTPClient(client)
+		}
+		if err := o.Login(ctx); err != nil {
+			t.Fatalf("%s login: %v", transport, err)
+		}
+		before := rt.n.Load()
+		if _, err := o.Count(ctx, "res.partner"); err != nil {
+			t.Fatalf("%s count: %v", transport, err)
+		}
+		if rt.n.Load() != before+1 {
+			t.Errorf("%s: request did not go through the injected client", transport)
+		}
+		if client.Transport != rt {
+			t.Errorf("%s: the injected client was modified", transport)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package odoorpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrImportInvalid is returned by ImportCSV when the validation of the data
+// or the import itself reports errors.
+var ErrImportInvalid = errors.New("import has errors")
+
+// importModel is the model of Odoo's import wizard.
+const importModel = "base_import.import"
+
+// ImportOptions configure how a CSV file is parsed and mapped to fields.
+type ImportOptions struct {
+	// Separator is the field separator. Defaults to ",".
+	Separator string
+	// Quoting is the quote character. Defaults to `"`.
+	Quoting string
+	// Encoding is the character encoding of the file. Defaults to "utf-8".
+	Encoding string
+	// NoHeaders tells that the first line of the file is data, in which case
+	// Fields must be set.
+	NoHeaders bool
+	// Fields, when set, are the field paths of the columns in order, e.g.
+	// "partner_id" or "partner_id/id", replacing the automatic mapping. An
+	// empty path skips the column.
+	Fields []string
+	// Mapping overrides the automatic mapping of the columns with these
+	// headers. An empty path skips the column.
+	Mapping map[string]string
+	// FileName is the name the file is uploaded as. Defaults to
+	// "import.csv".
+	FileName string
+}
+
+// Import is a CSV file uploaded to the base_import.import wizard of the
+// server, with its columns mapped to fields of the target model.
+type Import struct {
+	// ID is the id of the wizard record.
+	ID int
+	// Model is the model the records are imported into.
+	Model string
+	// Headers are the column headers of the file; nil when it has none.
+	Headers []string
+	// Fields are the field paths the columns are imported into; an empty
+	// path skips the column. They may be changed before Test or Execute.
+	Fields []string
+
+	o       Odoo
+	options map[string]any
+}
+
+// NewImport uploads the CSV file read from r for import into model and maps
+// its columns to fields with parse_preview, which matches the headers to
+// field names and labels. Columns without a match are skipped unless
+// opts.Mapping names their field.
+func NewImport(ctx context.Context, o Odoo, model string, r io.Reader, opts ImportOptions) (*Import, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+	fileName := opts.FileName
+	if fileName == "" {
+		fileName = "import.csv"
+	}
+	id, err := o.Create(ctx, importModel, map[string]any{
+		"res_model": model,
+		"file":      base64.StdEncoding.EncodeToString(data),
+		"file_name": fileName,
+		"file_type": "text/csv",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+	if id <= 0 {
+		return nil, fmt.Errorf("import failed: wizard not created")
+	}
+	im := &Import{ID: id, Model: model, o: o, options: importOptions(opts)}
+
+	res, err := o.CallMethod(ctx, importModel, "parse_preview", []int{id}, map[string]any{"options": im.options})
+	if err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+	preview, ok := res.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("parse_preview failed: unexpected response type %T", res)
+	}
+	if msg, ok := preview["error"].(string); ok {
+		return nil, fmt.Errorf("parse_preview failed: %s", msg)
+	}
+	headers, _ := preview["headers"].([]any)
+	for _, h := range headers {
+		s, _ := h.(string)
+		im.Headers = append(im.Headers, s)
+	}
+	if opts.Fields != nil {
+		im.Fields = append([]string(nil), opts.Fields...)
+		return im, nil
+	}
+	if opts.NoHeaders {
+		return nil, fmt.Errorf("import failed: Fields must be set for a file without headers")
+	}
+	matches, _ := preview["matches"].(map[string]any)
+	im.Fields = make([]string, len(im.Headers))
+	for i, h := range im.Headers {
+		if path, ok := opts.Mapping[h]; ok {
+			im.Fields[i] = path
+			continue
+		}
+		im.Fields[i] = matchPath(matches[strconv.Itoa(i)])
+	}
+	return im, nil
+}
+
+// matchPath joins a match of parse_preview, a list of field names, into a
+// field path.
+func matchPath(match any) string {
+	parts, _ := match.([]any)
+	names := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if s, ok := p.(string); ok {
+			names = append(names, s)
+		}
+	}
+	return strings.Join(names, "/")
+}
+
+// Test validates the import with execute_import in dry-run mode, which
+// reports the errors of every row without saving anything.
+func (im *Import) Test(ctx context.Context) (LoadResult, error) {
+	return im.execute(ctx, true)
+}
+
+// Execute imports the records. When the data has errors nothing is saved and
+// the result holds the error messages.
+func (im *Import) Execute(ctx context.Context) (LoadResult, error) {
+	return im.execute(ctx, false)
+}
+
+func (im *Import) execute(ctx context.Context, dryrun bool) (LoadResult, error) {
+	fields := make([]any, len(im.Fields))
+	for i, f := range im.Fields {
+		fields[i] = f
+		if f == "" {
+			fields[i] = false
+		}
+	}
+	columns := make([]string, len(im.Fields))
+	copy(columns, im.Headers)
+	res, err := im.o.CallMethod(ctx, importModel, "execute_import", []int{im.ID}, map[string]any{
+		"fields":  fields,
+		"columns": columns,
+		"options": im.options,
+		"dryrun":  dryrun,
+	})
+	if err != nil {
+		return LoadResult{}, fmt.Errorf("import failed: %w", err)
+	}
+	result, err := ParseLoadResult(res)
+	if err != nil {
+		return result, fmt.Errorf("execute_import failed: %w", err)
+	}
+	return result, nil
+}
+
+// ImportCSV imports the CSV file read from r into model: it uploads and maps
+// the file like NewImport, validates it with Test and, when there are no
+// errors, executes it. When the validation reports errors, nothing is saved
+// and the validation result is returned with an error wrapping
+// ErrImportInvalid. So is the result of an import that fails past the
+// validation, e.g. on a constraint checked when saving.
+func ImportCSV(ctx context.Context, o Odoo, model string, r io.Reader, opts ImportOptions) (LoadResult, error) {
+	im, err := NewImport(ctx, o, model, r, opts)
+	if err != nil {
+		return LoadResult{}, err
+	}
+	result, err := im.Test(ctx)
+	if err != nil {
+		return result, err
+	}
+	if result.HasErrors() {
+		return result, importError(result)
+	}
+	result, err = im.Execute(ctx)
+	if err != nil {
+		return result, err
+	}
+	if result.IDs == nil || result.HasErrors() {
+		return result, importError(result)
+	}
+	return result, nil
+}
+
+// importError returns the error wrapping ErrImportInvalid for a failed
+// import, describing its first error message.
+func importError(result LoadResult) error {
+	for _, m := range result.Messages {
+		if m.Type == "error" {
+			return fmt.Errorf("%w: %s", ErrImportInvalid, m)
+		}
+	}
+	return fmt.Errorf("%w: no records saved", ErrImportInvalid)
+}
+
+// importOptions returns the parse options of base_import for opts, with
+// Odoo's defaults for the others.
+func importOptions(opts ImportOptions) map[string]any {
+	separator, quoting, encoding := opts.Separator, opts.Quoting, opts.Encoding
+	if separator == "" {
+		separator = ","
+	}
+	if quoting == "" {
+		quoting = `"`
+	}
+	if encoding == "" {
+		encoding = "utf-8"
+	}
+	return map[string]any{
+		"separator":                  separator,
+		"quoting":                    quoting,
+		"encoding":                   encoding,
+		"has_headers":                !opts.NoHeaders,
+		"advanced":                   true,
+		"keep_matches":               false,
+		"skip":                       0,
+		"date_format":                "",
+		"datetime_format":            "",
+		"float_thousand_separator":   ",",
+		"float_decimal_separator":    ".",
+		"fallback_values":            map[string]any{},
+		"name_create_enabled_fields": map[string]any{},
+		"import_skip_records":        []any{},
+		"import_set_empty_fields":    []any{},
+	}
+}
@@ -0,0 +1,117 @@
+package odoorpc_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+func TestImportCSV(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name":      {Type: "char", Required: true},
+		"age":       {Type: "integer", String: "Age"},
+		"parent_id": {Type: "many2one", String: "Company", Relation: "res.partner"},
+		"comment":   {Type: "text", String: "Notes"},
+	})
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "Acme"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, o := range interceptedClients(t, srv) {
+		before := len(srv.Records("res.partner"))
+
+		bad := "Name,Age,Company\nAlice,30,Acme\nBob,old,Acme\nCarol,40,Nowhere\n,50,\n"
+		res, err := odoorpc.ImportCSV(ctx, o, "res.partner", strings.NewReader(bad), odoorpc.ImportOptions{})
+		if !errors.Is(err, odoorpc.ErrImportInvalid) {
+			t.Fatalf("%s: expected ErrImportInvalid, got %v", name, err)
+		}
+		if res.IDs != nil || len(res.Messages) != 3 {
+			t.Fatalf("%s: unexpected dry-run result %+v", name, res)
+		}
+		for i, want := range []odoorpc.LoadMessage{
+			{Type: "error", Row: 1, Field: "age"},
+			{Type: "error", Row: 2, Field: "parent_id"},
+			{Type: "error", Row: 3},
+		} {
+			if m := res.Messages[i]; m.Type != want.Type || m.Row != want.Row || m.Field != want.Field || m.Message == "" {
+				t.Errorf("%s: message %d is %+v, want %+v", name, i, m, want)
+			}
+		}
+		if got := len(srv.Records("res.partner")); got != before {
+			t.Fatalf("%s: %d records after a failed import, want %d", name, got, before)
+		}
+
+		good := "Name;Age;Company;Remarks\nAlice;30;Acme;first\nBob;;Acme;second\n"
+		im, err := odoorpc.NewImport(ctx, o, "res.partner", strings.NewReader(good), odoorpc.ImportOptions{
+			Separator: ";",
+			Mapping:   map[string]string{"Remarks": "comment"},
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if want := []string{"name", "age", "parent_id", "comment"}; !slices.Equal(im.Fields, want) {
+			t.Fatalf("%s: mapped fields %v, want %v", name, im.Fields, want)
+		}
+		dry, err := im.Test(ctx)
+		if err != nil || dry.HasErrors() || len(dry.IDs) != 2 {
+			t.Fatalf("%s: dry run %+v, %v", name, dry, err)
+		}
+		if got := len(srv.Records("res.partner")); got != before {
+			t.Fatalf("%s: dry run saved records", name)
+		}
+		done, err := im.Execute(ctx)
+		if err != nil || len(done.IDs) != 2 {
+			t.Fatalf("%s: import %+v, %v", name, done, err)
+		}
+		recs, err := o.Read(ctx, "res.partner", done.IDs, "name", "age", "parent_id", "comment")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recs[0]["name"] != "Alice" || recs[0]["comment"] != "first" || recs[1]["name"] != "Bob" {
+			t.Errorf("%s: imported records %v", name, recs)
+		}
+		if parent, ok := recs[1]["parent_id"].([]any); !ok || len(parent) != 2 || parent[1] != "Acme" {
+			t.Errorf("%s: parent %v", name, recs[1]["parent_id"])
+		}
+	}
+}
+
+// rejectingImporter is a client whose server validates imports but rejects
+// them when saving, like Odoo on a constraint of the database.
+type rejectingImporter struct {
+	odoorpc.Odoo
+}
+
+func (o rejectingImporter) CallMethod(ctx context.Context, model string, method string, ids []int, kwargs map[string]any) (any, error) {
+	if dryrun, _ := kwargs["dryrun"].(bool); method == "execute_import" && !dryrun {
+		return map[string]any{"ids": false, "messages": []any{
+			map[string]any{"type": "error", "message": "duplicate key value violates unique constraint", "rows": map[string]any{"from": 0, "to": 1}},
+		}}, nil
+	}
+	return o.Odoo.CallMethod(ctx, model, method, ids, kwargs)
+}
+
+func TestImportCSVRejected(t *testing.T) {
+	t.Parallel()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{"name": {Type: "char", Required: true}})
+	o := rejectingImporter{Odoo: interceptedClients(t, srv)[odoorpc.TransportJSONRPC]}
+
+	res, err := odoorpc.ImportCSV(context.Background(), o, "res.partner", strings.NewReader("Name\nAlice\nBob\n"), odoorpc.ImportOptions{})
+	if !errors.Is(err, odoorpc.ErrImportInvalid) || !strings.Contains(err.Error(), "unique constraint") {
+		t.Fatalf("expected ErrImportInvalid, got %v", err)
+	}
+	if res.IDs != nil || !res.HasErrors() {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
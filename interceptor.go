@@ -0,0 +1,69 @@
+package odoorpc
+
+import "context"
+
+// Transport names reported in CallInfo.Transport.
+const (
+	TransportJSONRPC = "jsonrpc"
+	TransportXMLRPC  = "xmlrpc"
+	TransportJSON2   = "json2"
+)
+
+// CallInfo describes a single RPC call as it passes through the interceptor
+// chain. Interceptors may modify the fields before calling next; the
+// transport builds the wire request from the CallInfo it finally receives.
+type CallInfo struct {
+	// Transport is one of TransportJSONRPC, TransportXMLRPC or TransportJSON2.
+	Transport string
+	// Service is the RPC service ("common" or "object"). It is empty for the
+	// JSON-2 API, which has no services.
+	Service string
+	// Model is the model name of object calls, e.g. "res.partner".
+	Model string
+	// Method is the model method for object calls (e.g. "search_read") and
+	// the service method otherwise (e.g. "login").
+	Method string
+	// Args holds the positional arguments. For object calls the database,
+	// uid and password are not included.
+	Args []any
+	// Kwargs holds the keyword arguments: the execute_kw kwargs for
+	// JSON-RPC and XML-RPC, and the request body for JSON-2.
+	Kwargs map[string]any
+	// Attempt counts the attempts of the call, starting at 0. Calls the
+	// client repeats, e.g. after re-authenticating, have Attempt > 0.
+	Attempt int
+}
+
+// Invoker performs the call described by info and returns its result.
+type Invoker func(ctx context.Context, info CallInfo) (any, error)
+
+// Interceptor wraps a call. It can inspect or modify info, call next to
+// continue the chain, and inspect or replace the result. Returning without
+// calling next skips the remaining interceptors and the network request.
+type Interceptor func(ctx context.Context, info CallInfo, next Invoker) (any, error)
+
+// Chain combines interceptors into one. The first interceptor is the
+// outermost: it sees the call first and the result last.
+func Chain(interceptors ...Interceptor) Interceptor {
+	return func(ctx context.Context, info CallInfo, next Invoker) (any, error) {
+		return invoke(ctx, info, next, interceptors)
+	}
+}
+
+// Invoke runs info through interceptors in order and ends with final. The
+// context passed down the chain carries a fresh CallStats for the call.
+func Invoke(ctx context.Context, info CallInfo, final Invoker, interceptors ...Interceptor) (any, error) {
+	return invoke(withStats(ctx), info, final, interceptors)
+}
+
+// invoke runs info through interceptors and final.
+func invoke(ctx context.Context, info CallInfo, final Invoker, interceptors []Interceptor) (any, error) {
+	h := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		ic, next := interceptors[i], h
+		h = func(ctx context.Context, info CallInfo) (any, error) {
+			return ic(ctx, info, next)
+		}
+	}
+	return h(ctx, info)
+}
@@ -0,0 +1,168 @@
+package odoorpc_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	odoojrpc "github.com/ppreeper/odoorpc/odoojrpc"
+	odoojson "github.com/ppreeper/odoorpc/odoojson"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+	odooxmlrpc "github.com/ppreeper/odoorpc/odooxmlrpc"
+)
+
+func TestChainOrder(t *testing.T) {
+	t.Parallel()
+	var order []string
+	mark := func(name string) odoorpc.Interceptor {
+		return func(ctx context.Context, info odoorpc.CallInfo, next odoorpc.Invoker) (any, error) {
+			order = append(order, name+">")
+			res, err := next(ctx, info)
+			order = append(order, "<"+name)
+			return res, err
+		}
+	}
+	final := func(ctx context.Context, info odoorpc.CallInfo) (any, error) {
+		order = append(order, "call")
+		return info.Method, nil
+	}
+
+	res, err := odoorpc.Invoke(context.Background(), odoorpc.CallInfo{Method: "m"}, final,
+		mark("a"), odoorpc.Chain(mark("b"), mark("c")))
+	if err != nil || res != "m" {
+		t.Fatalf("got %v, %v", res, err)
+	}
+	want := []string{"a>", "b>", "c>", "call", "<c", "<b", "<a"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order: got %v, want %v", order, want)
+	}
+}
+
+// interceptedClients returns a logged-in client per transport, each with
+// interceptors installed.
+func interceptedClients(t *testing.T, srv *odoorpctest.Server, interceptors ...odoorpc.Interceptor) map[string]odoorpc.Odoo {
+	t.Helper()
+	clients := map[string]odoorpc.Odoo{
+		odoorpc.TransportJSONRPC: odoojrpc.NewOdoo().
+			WithHostname(srv.Hostname()).WithPort(srv.Port()).WithDatabase(srv.Database).
+			WithUsername(srv.Username).WithPassword(srv.Password).
+			WithInterceptors(interceptors...),
+		odoorpc.TransportXMLRPC: odooxmlrpc.NewOdoo().
+			WithHostname(srv.Hostname()).WithPort(srv.Port()).WithDatabase(srv.Database).
+			WithUsername(srv.Username).WithPassword(srv.Password).
+			WithInterceptors(interceptors...),
+		odoorpc.TransportJSON2: odoojson.NewOdoo().
+			WithHostname(srv.Hostname()).WithPort(srv.Port()).WithDatabase(srv.Database).
+			WithAPIKey(srv.APIKey).
+			WithInterceptors(interceptors...),
+	}
+	for name, o := range clients {
+		if err := o.Login(context.Background()); err != nil {
+			t.Fatalf("%s login: %v", name, err)
+		}
+	}
+	return clients
+}
+
+func TestInterceptorsSeeEveryTransport(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	seen := map[string][]odoorpc.CallInfo{}
+	results := map[string]any{}
+	record := func(ctx context.Context, info odoorpc.CallInfo, next odoorpc.Invoker) (any, error) {
+		res, err := next(ctx, info)
+		mu.Lock()
+		defer mu.Unlock()
+		if info.Model != "" {
+			seen[info.Transport] = append(seen[info.Transport], info)
+			results[info.Transport] = res
+		}
+		return res, err
+	}
+
+	for name, o := range interceptedClients(t, srv, record) {
+		if _, err := o.Search(ctx, "res.partner", []any{[]any{"name", "=", "Alice"}}); err != nil {
+			t.Fatalf("%s search: %v", name, err)
+		}
+	}
+
+	for _, transport := range []string{odoorpc.TransportJSONRPC, odoorpc.TransportXMLRPC, odoorpc.TransportJSON2} {
+		calls := seen[transport]
+		if len(calls) != 1 {
+			t.Fatalf("%s: expected 1 object call, got %+v", transport, calls)
+		}
+		if calls[0].Model != "res.partner" || calls[0].Method != "search" {
+			t.Errorf("%s: got model=%q method=%q", transport, calls[0].Model, calls[0].Method)
+		}
+		if results[transport] == nil {
+			t.Errorf("%s: interceptor did not see the result", transport)
+		}
+		for _, arg := range calls[0].Args {
+			if arg == srv.Password {
+				t.Errorf("%s: object call args must not include the password: %v", transport, calls[0].Args)
+			}
+		}
+	}
+}
+
+func TestInterceptorDryRun(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	ids, err := srv.Seed("res.partner", map[string]any{"name": "Alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dryRun := func(ctx context.Context, info odoorpc.CallInfo, next odoorpc.Invoker) (any, error) {
+		if info.Method == "write" {
+			return true, nil
+		}
+		return next(ctx, info)
+	}
+
+	for name, o := range interceptedClients(t, srv, dryRun) {
+		ok, err := o.Write(ctx, "res.partner", ids[0], map[string]any{"name": "Changed"})
+		if err != nil || !ok {
+			t.Errorf("%s write: got %v, %v", name, ok, err)
+		}
+	}
+	if got := srv.Records("res.partner")[0]["name"]; got != "Alice" {
+		t.Errorf("dry-run write reached the server: name=%v", got)
+	}
+}
+
+func TestInterceptorRewritesCall(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "Alice"}, map[string]any{"name": "Bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Route the "partner" alias to res.partner.
+	alias := func(ctx context.Context, info odoorpc.CallInfo, next odoorpc.Invoker) (any, error) {
+		if info.Model == "partner" {
+			info.Model = "res.partner"
+		}
+		return next(ctx, info)
+	}
+
+	for name, o := range interceptedClients(t, srv, alias) {
+		n, err := o.Count(ctx, "partner")
+		if err != nil || n != 2 {
+			t.Errorf("%s count: got %d, %v", name, n, err)
+		}
+	}
+}
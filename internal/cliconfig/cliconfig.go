@@ -0,0 +1,162 @@
+// Package cliconfig holds the connection settings shared by the commands of
+// the module: the connection flags, the ODOORPC_* environment variables and
+// the profiles of the configuration file.
+package cliconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ppreeper/odoorpc"
+	odoojrpc "github.com/ppreeper/odoorpc/odoojrpc"
+	odoojson "github.com/ppreeper/odoorpc/odoojson"
+	odooxmlrpc "github.com/ppreeper/odoorpc/odooxmlrpc"
+)
+
+// UsageError reports invalid command line arguments.
+type UsageError string
+
+func (e UsageError) Error() string { return string(e) }
+
+// Profile holds the connection settings of a server.
+type Profile struct {
+	URL       string `json:"url"`
+	Database  string `json:"database"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	APIKey    string `json:"api_key"`
+	Transport string `json:"transport"`
+}
+
+// Config is the content of the configuration file.
+type Config struct {
+	// Default is the profile used when none is selected.
+	Default  string             `json:"default"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// Flags are the connection flags.
+type Flags struct {
+	Config      string
+	ProfileName string
+	Profile
+	Timeout time.Duration
+}
+
+// Register adds the connection flags to flags.
+func (f *Flags) Register(flags *flag.FlagSet) {
+	flags.StringVar(&f.Config, "config", "", "configuration `file` (default $ODOORPC_CONFIG or <user config dir>/odoorpc/config.json)")
+	flags.StringVar(&f.ProfileName, "profile", "", "configuration profile (default $ODOORPC_PROFILE or the default profile)")
+	flags.StringVar(&f.URL, "url", "", "server base URL, e.g. https://erp.example.com")
+	flags.StringVar(&f.Database, "db", "", "database name")
+	flags.StringVar(&f.Username, "user", "", "user login")
+	flags.StringVar(&f.Password, "password", "", "user password")
+	flags.StringVar(&f.APIKey, "api-key", "", "API key, required for json2")
+	flags.StringVar(&f.Transport, "transport", "", "transport: jsonrpc, xmlrpc or json2 (default jsonrpc)")
+	flags.DurationVar(&f.Timeout, "timeout", 0, "request timeout, e.g. 30s")
+}
+
+// Resolve returns the connection settings from the flags, the environment
+// and the configuration file, in that order of precedence.
+func (f *Flags) Resolve(getenv func(string) string) (Profile, error) {
+	name := First(f.ProfileName, getenv("ODOORPC_PROFILE"))
+	path := First(f.Config, getenv("ODOORPC_CONFIG"))
+	explicit := path != ""
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err == nil {
+			path = filepath.Join(dir, "odoorpc", "config.json")
+		}
+	}
+	var p Profile
+	if path != "" {
+		cfg, err := ReadConfig(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && !explicit && name == "":
+		case err != nil:
+			return p, err
+		default:
+			name = First(name, cfg.Default)
+			if name != "" {
+				var ok bool
+				if p, ok = cfg.Profiles[name]; !ok {
+					return p, fmt.Errorf("profile %q not found in %s", name, path)
+				}
+			}
+		}
+	}
+	p.URL = First(f.URL, getenv("ODOORPC_URL"), p.URL)
+	p.Database = First(f.Database, getenv("ODOORPC_DB"), p.Database)
+	p.Username = First(f.Username, getenv("ODOORPC_USER"), p.Username)
+	p.Password = First(f.Password, getenv("ODOORPC_PASSWORD"), p.Password)
+	p.APIKey = First(f.APIKey, getenv("ODOORPC_API_KEY"), p.APIKey)
+	p.Transport = First(f.Transport, getenv("ODOORPC_TRANSPORT"), p.Transport, odoorpc.TransportJSONRPC)
+	if p.URL == "" {
+		return p, UsageError("no server: set -url, ODOORPC_URL or a profile")
+	}
+	return p, nil
+}
+
+// ReadConfig reads the configuration file at path.
+func ReadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// NewClient returns a client for p that sends its requests through rt, or
+// the default transport when rt is nil. Without a password, the API key is
+// used as the password of the JSON-RPC and XML-RPC transports.
+func NewClient(p Profile, timeout time.Duration, rt http.RoundTripper) (odoorpc.Odoo, error) {
+	password := First(p.Password, p.APIKey)
+	switch p.Transport {
+	case odoorpc.TransportJSONRPC:
+		o := odoojrpc.NewOdoo().WithBaseURL(p.URL).WithDatabase(p.Database).
+			WithUsername(p.Username).WithPassword(password).WithTransport(rt)
+		if timeout > 0 {
+			o.WithTimeout(timeout)
+		}
+		return o, nil
+	case odoorpc.TransportXMLRPC:
+		o := odooxmlrpc.NewOdoo().WithBaseURL(p.URL).WithDatabase(p.Database).
+			WithUsername(p.Username).WithPassword(password).WithTransport(rt)
+		if timeout > 0 {
+			o.WithTimeout(timeout)
+		}
+		return o, nil
+	case odoorpc.TransportJSON2:
+		if p.APIKey == "" {
+			return nil, UsageError("the json2 transport requires an API key")
+		}
+		o := odoojson.NewOdoo().WithBaseURL(p.URL).WithDatabase(p.Database).
+			WithAPIKey(p.APIKey).WithTransport(rt)
+		if timeout > 0 {
+			o.WithTimeout(timeout)
+		}
+		return o, nil
+	}
+	return nil, UsageError(fmt.Sprintf("unknown transport %q", p.Transport))
+}
+
+// First returns the first non-empty value.
+func First(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
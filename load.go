@@ -0,0 +1,140 @@
+package odoorpc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LoadMessage is a message reported by load about the imported data.
+type LoadMessage struct {
+	// Type is "error", "warning" or "info".
+	Type string
+	// Row is the index of the row of the data the message is about,
+	// counting from 0, or -1 when it is not about a specific row.
+	Row int
+	// Field is the name of the field the message is about, if any.
+	Field string
+	// Message is the text of the message.
+	Message string
+}
+
+// String formats the message with its row and field.
+func (m LoadMessage) String() string {
+	var where []string
+	if m.Row >= 0 {
+		where = append(where, fmt.Sprintf("row %d", m.Row))
+	}
+	if m.Field != "" {
+		where = append(where, "field "+m.Field)
+	}
+	if len(where) == 0 {
+		return m.Message
+	}
+	return strings.Join(where, ", ") + ": " + m.Message
+}
+
+// LoadResult is the result of load, which base_import's execute_import
+// returns as well.
+type LoadResult struct {
+	// IDs are the ids of the created or updated records, in data order. It
+	// is nil when the data had errors, in which case nothing was saved.
+	IDs []int
+	// Messages are the errors and warnings about the data.
+	Messages []LoadMessage
+	// NextRow is the index of the first row that was not loaded when the
+	// server stopped early because of a limit, or 0.
+	NextRow int
+}
+
+// HasErrors reports whether any message is an error.
+func (r LoadResult) HasErrors() bool {
+	for _, m := range r.Messages {
+		if m.Type == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns a *LoadError when the server saved nothing because of errors
+// in the data, and nil otherwise. Warnings do not make the result fail.
+func (r LoadResult) Err() error {
+	if r.IDs != nil {
+		return nil
+	}
+	return &LoadError{Messages: r.Messages}
+}
+
+// LoadError is returned when load rejects the data. Nothing is saved.
+type LoadError struct {
+	// Messages are the messages of the load, including the row-level errors.
+	Messages []LoadMessage
+}
+
+func (e *LoadError) Error() string {
+	var errs []LoadMessage
+	for _, m := range e.Messages {
+		if m.Type == "error" {
+			errs = append(errs, m)
+		}
+	}
+	switch len(errs) {
+	case 0:
+		return "load failed: no records saved"
+	case 1:
+		return "load failed: " + errs[0].String()
+	}
+	return fmt.Sprintf("load failed: %s (and %d more errors)", errs[0], len(errs)-1)
+}
+
+// ParseLoadResult converts the result of load or execute_import.
+func ParseLoadResult(result any) (LoadResult, error) {
+	var res LoadResult
+	if id, ok := toFloat(result); ok {
+		// Some servers answer a single id for a single row.
+		res.IDs = []int{int(id)}
+		return res, nil
+	}
+	m, ok := result.(map[string]any)
+	if !ok {
+		return res, fmt.Errorf("unexpected response type %T", result)
+	}
+	switch ids := m["ids"].(type) {
+	case []any:
+		res.IDs = make([]int, 0, len(ids))
+		for _, id := range ids {
+			v, ok := toFloat(id)
+			if !ok {
+				return res, fmt.Errorf("unexpected id type %T in response", id)
+			}
+			res.IDs = append(res.IDs, int(v))
+		}
+	case bool, nil:
+		// ids is false when nothing was saved.
+	default:
+		return res, fmt.Errorf("unexpected ids type %T in response", ids)
+	}
+	messages, _ := m["messages"].([]any)
+	for _, msg := range messages {
+		mm, ok := msg.(map[string]any)
+		if !ok {
+			return res, fmt.Errorf("unexpected message type %T in response", msg)
+		}
+		lm := LoadMessage{Row: -1}
+		lm.Type, _ = mm["type"].(string)
+		lm.Field, _ = mm["field"].(string)
+		lm.Message, _ = mm["message"].(string)
+		if row, ok := toFloat(mm["record"]); ok {
+			lm.Row = int(row)
+		} else if rows, ok := mm["rows"].(map[string]any); ok {
+			if from, ok := toFloat(rows["from"]); ok {
+				lm.Row = int(from)
+			}
+		}
+		res.Messages = append(res.Messages, lm)
+	}
+	if next, ok := toFloat(m["nextrow"]); ok {
+		res.NextRow = int(next)
+	}
+	return res, nil
+}
@@ -0,0 +1,55 @@
+package odoorpc_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+func TestLoadResult(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name": {Type: "char", Required: true},
+		"age":  {Type: "integer"},
+	})
+
+	for name, o := range interceptedClients(t, srv) {
+		res, err := o.Load(ctx, "res.partner", []string{"name", "age"}, [][]any{{"Alice", "30"}, {"Bob", "41"}})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(res.IDs) != 2 || len(res.Messages) != 0 || res.NextRow != 0 {
+			t.Errorf("%s: unexpected result %+v", name, res)
+		}
+
+		before := len(srv.Records("res.partner"))
+		res, err = o.Load(ctx, "res.partner", []string{"name", "age"},
+			[][]any{{"Carol", "25"}, {"Dave", "old"}, {"", "50"}})
+		var loadErr *odoorpc.LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("%s: expected a *LoadError, got %v", name, err)
+		}
+		if res.IDs != nil || len(loadErr.Messages) != 2 || len(res.Messages) != 2 {
+			t.Fatalf("%s: unexpected failed result %+v", name, res)
+		}
+		if m := res.Messages[0]; m.Type != "error" || m.Row != 1 || m.Field != "age" {
+			t.Errorf("%s: first message %+v", name, m)
+		}
+		if m := res.Messages[1]; m.Row != 2 || !strings.Contains(m.Message, "required") {
+			t.Errorf("%s: second message %+v", name, m)
+		}
+		if !strings.Contains(err.Error(), "row 1, field age") || !strings.Contains(err.Error(), "1 more") {
+			t.Errorf("%s: error %q", name, err)
+		}
+		if got := len(srv.Records("res.partner")); got != before {
+			t.Errorf("%s: %d records after a rejected load, want %d", name, got, before)
+		}
+	}
+}
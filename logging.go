@@ -0,0 +1,220 @@
+package odoorpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// redacted replaces credentials and binary values in logged arguments.
+const redacted = "[REDACTED]"
+
+// defaultMaxArgBytes bounds the logged argument summary.
+const defaultMaxArgBytes = 1024
+
+// minBinaryLen is the shortest string treated as base64 binary data.
+const minBinaryLen = 128
+
+// defaultRedactKeys are argument map keys whose values are never logged.
+var defaultRedactKeys = []string{"password", "new_password", "old_password", "api_key", "apikey", "token", "secret"}
+
+// LogOptions configures LogInterceptor. The zero value logs successful calls
+// at debug level and failed calls at error level.
+type LogOptions struct {
+	// Level is the level of successful calls. Defaults to slog.LevelDebug.
+	Level slog.Leveler
+	// ErrorLevel is the level of failed calls. Defaults to slog.LevelError.
+	ErrorLevel slog.Leveler
+	// RedactKeys are argument keys whose values are redacted, in addition to
+	// the built-in password, API key and token names.
+	RedactKeys []string
+	// MaxArgBytes bounds the length of the logged argument summary.
+	// Defaults to 1024.
+	MaxArgBytes int
+}
+
+// LogInterceptor returns an interceptor that logs every call to logger with
+// its transport, model, method, a summary of the arguments, the duration, the
+// request and response sizes and, for failures, the error class.
+//
+// Login passwords, values under credential keys and binary field values
+// (long base64 strings and byte slices) are redacted from the summary. A nil
+// logger logs to slog.Default().
+func LogInterceptor(logger *slog.Logger, opts LogOptions) Interceptor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	level, errLevel := opts.Level, opts.ErrorLevel
+	if level == nil {
+		level = slog.LevelDebug
+	}
+	if errLevel == nil {
+		errLevel = slog.LevelError
+	}
+	maxArgs := opts.MaxArgBytes
+	if maxArgs <= 0 {
+		maxArgs = defaultMaxArgBytes
+	}
+	keys := append(slices.Clone(defaultRedactKeys), opts.RedactKeys...)
+	for i, k := range keys {
+		keys[i] = strings.ToLower(k)
+	}
+
+	return func(ctx context.Context, info CallInfo, next Invoker) (any, error) {
+		lvl := level.Level()
+		if !logger.Enabled(ctx, lvl) && !logger.Enabled(ctx, errLevel.Level()) {
+			return next(ctx, info)
+		}
+		start := time.Now()
+		res, err := next(ctx, info)
+		elapsed := time.Since(start)
+
+		if err != nil {
+			lvl = errLevel.Level()
+		}
+		if !logger.Enabled(ctx, lvl) {
+			return res, err
+		}
+		stats := StatsFromContext(ctx)
+		attrs := []slog.Attr{slog.String("transport", info.Transport)}
+		if info.Service != "" {
+			attrs = append(attrs, slog.String("service", info.Service))
+		}
+		if info.Model != "" {
+			attrs = append(attrs, slog.String("model", info.Model))
+		}
+		attrs = append(attrs,
+			slog.String("method", info.Method),
+			slog.String("args", summarize(redactArgs(info, keys), maxArgs)),
+			slog.Duration("duration", elapsed),
+			slog.Int64("request_bytes", stats.RequestBytes()),
+			slog.Int64("response_bytes", stats.ResponseBytes()),
+		)
+		msg := "odoo call"
+		if err != nil {
+			msg = "odoo call failed"
+			attrs = append(attrs, slog.String("error_class", ErrorClass(err)), slog.String("error", err.Error()))
+		}
+		logger.LogAttrs(ctx, lvl, msg, attrs...)
+		return res, err
+	}
+}
+
+// redactArgs returns the arguments and keyword arguments of info with
+// credentials and binary values replaced.
+func redactArgs(info CallInfo, keys []string) map[string]any {
+	args := info.Args
+	if info.Service == "common" && (info.Method == "login" || info.Method == "authenticate") && len(args) >= 3 {
+		args = slices.Clone(args)
+		args[2] = redacted
+	}
+	out := map[string]any{}
+	if len(args) > 0 {
+		out["args"] = redactValue(args, keys)
+	}
+	if len(info.Kwargs) > 0 {
+		out["kwargs"] = redactValue(info.Kwargs, keys)
+	}
+	if info.Method == "load" {
+		// load(fields, data) passes the rows positionally.
+		if a, ok := out["args"].([]any); ok && len(a) >= 2 {
+			redactColumns(a[0], a[1], keys)
+		}
+		if kw, ok := out["kwargs"].(map[string]any); ok {
+			redactColumns(kw["fields"], kw["data"], keys)
+		}
+	}
+	return out
+}
+
+// redactValue replaces credential keys and binary values anywhere in v,
+// walking slices and maps of any type.
+func redactValue(v any, keys []string) any {
+	switch v := v.(type) {
+	case []byte:
+		return fmt.Sprintf("[BINARY %d bytes]", len(v))
+	case string:
+		if isBase64(v) {
+			return fmt.Sprintf("[BINARY %d bytes]", len(v))
+		}
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		out := make(map[string]any, rv.Len())
+		for iter := rv.MapRange(); iter.Next(); {
+			k := fmt.Sprint(iter.Key().Interface())
+			if slices.Contains(keys, strings.ToLower(k)) {
+				out[k] = redacted
+				continue
+			}
+			out[k] = redactValue(iter.Value().Interface(), keys)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = redactValue(rv.Index(i).Interface(), keys)
+		}
+		return out
+	}
+	return v
+}
+
+// redactColumns replaces the cells of rows, as returned by redactValue, in
+// the columns of fields named by a credential key, e.g. "password" or
+// "user_ids/password".
+func redactColumns(fields any, rows any, keys []string) {
+	names, _ := fields.([]any)
+	list, _ := rows.([]any)
+	for i, name := range names {
+		path, _ := name.(string)
+		if j := strings.LastIndex(path, "/"); j >= 0 {
+			path = path[j+1:]
+		}
+		if !slices.Contains(keys, strings.ToLower(path)) {
+			continue
+		}
+		for _, row := range list {
+			if cells, ok := row.([]any); ok && i < len(cells) {
+				cells[i] = redacted
+			}
+		}
+	}
+}
+
+// isBase64 reports whether s looks like base64-encoded binary data: a long
+// string made only of base64 characters.
+func isBase64(s string) bool {
+	if len(s) < minBinaryLen {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '+', c == '/', c == '=', c == '-', c == '_', c == '\n', c == '\r':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// summarize encodes v as JSON, truncated to limit bytes.
+func summarize(v any, limit int) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	if len(data) > limit {
+		return string(data[:limit]) + "…"
+	}
+	return string(data)
+}
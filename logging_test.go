@@ -0,0 +1,193 @@
+package odoorpc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	odoojrpc "github.com/ppreeper/odoorpc/odoojrpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records decodes the JSON log lines written to b.
+func (b *syncBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decode log line %q: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func TestLogInterceptor(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.Password = "s3cret-password"
+
+	var buf syncBuffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	image := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 64))
+
+	for name, o := range interceptedClients(t, srv, odoorpc.LogInterceptor(logger, odoorpc.LogOptions{Level: slog.LevelInfo})) {
+		if _, err := o.Create(ctx, "res.users", map[string]any{
+			"login": "bob", "password": "hunter2", "image_1920": image,
+		}); err != nil {
+			t.Fatalf("%s create: %v", name, err)
+		}
+		if _, err := o.Read(ctx, "res.users", []int{999}, "login"); err == nil {
+			t.Fatalf("%s read: expected error", name)
+		}
+	}
+
+	records := buf.records(t)
+	creates, failures := map[string]bool{}, map[string]bool{}
+	for _, rec := range records {
+		line, _ := json.Marshal(rec)
+		for _, secret := range []string{srv.Password, "hunter2", image[:64]} {
+			if strings.Contains(string(line), secret) {
+				t.Errorf("log contains secret %q: %s", secret, line)
+			}
+		}
+		transport, _ := rec["transport"].(string)
+		switch rec["method"] {
+		case "create":
+			creates[transport] = true
+			if rec["level"] != "INFO" || rec["model"] != "res.users" {
+				t.Errorf("create: got %s", line)
+			}
+			if !strings.Contains(rec["args"].(string), `"bob"`) || !strings.Contains(rec["args"].(string), "[BINARY") {
+				t.Errorf("create args: got %s", rec["args"])
+			}
+			if n, _ := rec["response_bytes"].(float64); n <= 0 {
+				t.Errorf("create: expected response_bytes, got %s", line)
+			}
+		case "read":
+			failures[transport] = true
+			// XML-RPC reports MissingError as a generic UserError fault.
+			class, _ := rec["error_class"].(string)
+			if rec["level"] != "ERROR" || !strings.HasPrefix(class, "odoo.exceptions.") {
+				t.Errorf("read: got %s", line)
+			}
+		}
+	}
+	for _, transport := range []string{odoorpc.TransportJSONRPC, odoorpc.TransportXMLRPC, odoorpc.TransportJSON2} {
+		if !creates[transport] || !failures[transport] {
+			t.Errorf("%s: missing log records in %v", transport, records)
+		}
+	}
+}
+
+func TestLogInterceptorRedactsLoad(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+
+	var buf syncBuffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	image := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 64))
+
+	for name, o := range interceptedClients(t, srv, odoorpc.LogInterceptor(logger, odoorpc.LogOptions{Level: slog.LevelInfo})) {
+		if _, err := o.Load(ctx, "res.users", []string{"login", "password", "image_1920"}, [][]any{
+			{"bob", "hunter2", image},
+		}); err != nil {
+			t.Fatalf("%s load: %v", name, err)
+		}
+		if _, err := o.CallMethod(ctx, "res.users", "search", nil, map[string]any{
+			"domain":  []any{[]any{"image_1920", "in", []string{image}}},
+			"context": map[string]string{"api_key": "k3y"},
+		}); err != nil {
+			t.Fatalf("%s search: %v", name, err)
+		}
+	}
+
+	loads := 0
+	for _, rec := range buf.records(t) {
+		line, _ := json.Marshal(rec)
+		for _, secret := range []string{"hunter2", image[:64], "k3y"} {
+			if strings.Contains(string(line), secret) {
+				t.Errorf("log contains secret %q: %s", secret, line)
+			}
+		}
+		if rec["method"] == "load" {
+			loads++
+			if !strings.Contains(rec["args"].(string), `"bob"`) {
+				t.Errorf("load args: got %s", rec["args"])
+			}
+		}
+	}
+	if loads != 3 {
+		t.Errorf("got %d load records, want 3", loads)
+	}
+}
+
+func TestWithLoggerRedactsLogin(t *testing.T) {
+	t.Parallel()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.Password = "s3cret-password"
+
+	var buf syncBuffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	o := odoojrpc.NewOdoo().
+		WithHostname(srv.Hostname()).WithPort(srv.Port()).WithDatabase(srv.Database).
+		WithUsername(srv.Username).WithPassword(srv.Password).
+		WithLogger(logger)
+	if err := o.Login(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	records := buf.records(t)
+	if len(records) != 1 || records[0]["method"] != "login" || records[0]["level"] != "DEBUG" {
+		t.Fatalf("expected one debug login record, got %v", records)
+	}
+	if args := records[0]["args"].(string); strings.Contains(args, srv.Password) || !strings.Contains(args, srv.Username) {
+		t.Errorf("login args: got %s", args)
+	}
+}
+
+func TestNilLoggerUsesDefault(t *testing.T) {
+	t.Parallel()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	for name, o := range interceptedClients(t, srv, odoorpc.LogInterceptor(nil, odoorpc.LogOptions{})) {
+		if _, err := o.Count(context.Background(), "res.partner"); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	o := odoojrpc.NewOdoo().
+		WithHostname(srv.Hostname()).WithPort(srv.Port()).WithDatabase(srv.Database).
+		WithUsername(srv.Username).WithPassword(srv.Password).
+		WithLogger(nil)
+	if err := o.Login(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
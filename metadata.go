@@ -0,0 +1,352 @@
+package odoorpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FieldInfo describes a model field, as reported by fields_get.
+type FieldInfo struct {
+	Name string `json:"name"`
+	// Type is the Odoo field type, e.g. char, many2one or selection.
+	Type string `json:"type"`
+	// String is the field label.
+	String string `json:"string"`
+	// Relation is the comodel name of relational fields.
+	Relation string `json:"relation,omitempty"`
+	Required bool   `json:"required"`
+	Readonly bool   `json:"readonly"`
+	// Store reports whether the field is stored in the database, which
+	// computed fields may not be.
+	Store bool `json:"store"`
+	// Selection lists the (value, label) pairs of selection fields.
+	Selection [][2]string `json:"selection,omitempty"`
+}
+
+// FieldInfoAttributes are the fields_get attributes FieldInfo holds.
+var FieldInfoAttributes = []string{"type", "string", "relation", "required", "readonly", "store", "selection"}
+
+// ParseFieldInfo converts the result of fields_get, keyed by field name.
+func ParseFieldInfo(result any) (map[string]FieldInfo, error) {
+	m, ok := result.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", result)
+	}
+	fields := make(map[string]FieldInfo, len(m))
+	for name, v := range m {
+		desc, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unexpected field type %T in response", v)
+		}
+		f := FieldInfo{Name: name}
+		f.Type, _ = desc["type"].(string)
+		f.String, _ = desc["string"].(string)
+		f.Relation, _ = desc["relation"].(string)
+		f.Required, _ = desc["required"].(bool)
+		f.Readonly, _ = desc["readonly"].(bool)
+		f.Store, _ = desc["store"].(bool)
+		if sel, ok := desc["selection"].([]any); ok {
+			for _, p := range sel {
+				pair, ok := p.([]any)
+				if !ok || len(pair) != 2 {
+					return nil, fmt.Errorf("unexpected selection %v of field %s", p, name)
+				}
+				f.Selection = append(f.Selection, [2]string{fmt.Sprint(pair[0]), fmt.Sprint(pair[1])})
+			}
+		}
+		fields[name] = f
+	}
+	return fields, nil
+}
+
+// MetadataCache caches the field metadata of the models of a client, per
+// model and language, so that it is fetched with fields_get once. It is safe
+// for concurrent use: concurrent lookups of the same model and language share
+// a single fetch, and lookups of other models do not wait for it.
+type MetadataCache struct {
+	o        Odoo
+	ttl      time.Duration
+	dir      string
+	database string
+
+	mu       sync.Mutex
+	version  string
+	entries  map[metadataKey]metadataEntry
+	inflight map[metadataKey]*metadataFetch
+	// gen counts invalidations, so that fetches started before one are not
+	// cached.
+	gen int
+}
+
+type metadataKey struct {
+	model string
+	lang  string
+}
+
+// metadataEntry is a cached fields_get result, in memory and on disk.
+type metadataEntry struct {
+	Fetched time.Time            `json:"fetched"`
+	Fields  map[string]FieldInfo `json:"fields"`
+}
+
+// metadataFetch is a fetch in flight, which concurrent lookups wait for.
+type metadataFetch struct {
+	done  chan struct{}
+	entry metadataEntry
+	err   error
+}
+
+// NewMetadataCache returns a cache of the field metadata of o. Entries do
+// not expire until a TTL is set.
+func NewMetadataCache(o Odoo) *MetadataCache {
+	return &MetadataCache{
+		o:        o,
+		entries:  make(map[metadataKey]metadataEntry),
+		inflight: make(map[metadataKey]*metadataFetch),
+	}
+}
+
+// WithTTL sets how long the metadata is used before it is fetched again; 0
+// keeps it until it is invalidated.
+func (c *MetadataCache) WithTTL(ttl time.Duration) *MetadataCache {
+	c.ttl = ttl
+	return c
+}
+
+// WithDiskCache persists the metadata in files under dir, so that it
+// survives the process. The files are keyed by database and server version:
+// upgrading the server does not use the metadata of the previous version.
+func (c *MetadataCache) WithDiskCache(dir string, database string) *MetadataCache {
+	c.dir = dir
+	c.database = database
+	return c
+}
+
+// Fields returns the fields of model, with labels in lang, or in the
+// language of the user when lang is empty. The map is a copy the caller may
+// modify.
+func (c *MetadataCache) Fields(ctx context.Context, model string, lang string) (map[string]FieldInfo, error) {
+	key := metadataKey{model: model, lang: lang}
+	for {
+		c.mu.Lock()
+		if e, ok := c.entries[key]; ok && c.fresh(e) {
+			c.mu.Unlock()
+			return cloneFields(e.Fields), nil
+		}
+		if f, ok := c.inflight[key]; ok {
+			c.mu.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if f.err != nil && ctx.Err() == nil &&
+				(errors.Is(f.err, context.Canceled) || errors.Is(f.err, context.DeadlineExceeded)) {
+				// The context of the fetch ended, not ours: fetch again.
+				continue
+			}
+			if f.err != nil {
+				return nil, f.err
+			}
+			return cloneFields(f.entry.Fields), nil
+		}
+		f := &metadataFetch{done: make(chan struct{})}
+		c.inflight[key] = f
+		gen := c.gen
+		c.mu.Unlock()
+
+		f.entry, f.err = c.fetch(ctx, key)
+		c.mu.Lock()
+		delete(c.inflight, key)
+		if f.err == nil && gen == c.gen {
+			c.entries[key] = f.entry
+		}
+		c.mu.Unlock()
+		close(f.done)
+		if f.err != nil {
+			return nil, f.err
+		}
+		return cloneFields(f.entry.Fields), nil
+	}
+}
+
+// fetch reads the metadata of key from disk, or from the server with
+// fields_get.
+func (c *MetadataCache) fetch(ctx context.Context, key metadataKey) (metadataEntry, error) {
+	var version string
+	if c.dir != "" {
+		var err error
+		if version, err = c.serverVersion(ctx); err != nil {
+			return metadataEntry{}, err
+		}
+		if e, ok := c.readFile(key, version); ok && c.fresh(e) {
+			return e, nil
+		}
+	}
+	kwargs := map[string]any{"attributes": FieldInfoAttributes}
+	if key.lang != "" {
+		kwargs["context"] = map[string]any{"lang": key.lang}
+	}
+	result, err := c.o.CallMethod(ctx, key.model, "fields_get", nil, kwargs)
+	if err != nil {
+		return metadataEntry{}, err
+	}
+	fields, err := ParseFieldInfo(result)
+	if err != nil {
+		return metadataEntry{}, fmt.Errorf("fields_get failed: %w", err)
+	}
+	e := metadataEntry{Fetched: time.Now(), Fields: fields}
+	if c.dir != "" {
+		if err := c.writeFile(key, version, e); err != nil {
+			return metadataEntry{}, err
+		}
+	}
+	return e, nil
+}
+
+// cloneFields returns a copy of fields that shares nothing with it.
+func cloneFields(fields map[string]FieldInfo) map[string]FieldInfo {
+	out := make(map[string]FieldInfo, len(fields))
+	for name, f := range fields {
+		f.Selection = slices.Clone(f.Selection)
+		out[name] = f
+	}
+	return out
+}
+
+// Field returns the field name of model, with its label in lang.
+func (c *MetadataCache) Field(ctx context.Context, model string, lang string, name string) (FieldInfo, error) {
+	fields, err := c.Fields(ctx, model, lang)
+	if err != nil {
+		return FieldInfo{}, err
+	}
+	f, ok := fields[name]
+	if !ok {
+		return FieldInfo{}, fmt.Errorf("field %s not found in %s", name, model)
+	}
+	return f, nil
+}
+
+// Invalidate drops the metadata of models in all languages, or of all models
+// when none are given, from memory and disk. Fetches in flight are not
+// cached.
+func (c *MetadataCache) Invalidate(models ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	if len(models) == 0 {
+		clear(c.entries)
+		if c.dir != "" {
+			return os.RemoveAll(filepath.Join(c.dir, url.PathEscape(c.database)))
+		}
+		return nil
+	}
+	for _, model := range models {
+		for key := range c.entries {
+			if key.model == model {
+				delete(c.entries, key)
+			}
+		}
+		if c.dir == "" {
+			continue
+		}
+		// The files of all the server versions.
+		for _, pattern := range []string{model + ".json", model + "@*.json"} {
+			paths, _ := filepath.Glob(filepath.Join(c.dir, url.PathEscape(c.database), "*", pattern))
+			for _, p := range paths {
+				if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fresh reports whether e has not expired.
+func (c *MetadataCache) fresh(e metadataEntry) bool {
+	return c.ttl <= 0 || time.Since(e.Fetched) < c.ttl
+}
+
+// serverVersion returns the server version that keys the files, fetched
+// once.
+func (c *MetadataCache) serverVersion(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	version := c.version
+	c.mu.Unlock()
+	if version != "" {
+		return version, nil
+	}
+	v, err := c.o.ServerVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.version = v.Raw
+	c.mu.Unlock()
+	return v.Raw, nil
+}
+
+// path returns the file of the metadata of key for a server version.
+func (c *MetadataCache) path(key metadataKey, version string) string {
+	name := key.model
+	if key.lang != "" {
+		name += "@" + key.lang
+	}
+	return filepath.Join(c.dir, url.PathEscape(c.database), url.PathEscape(version), url.PathEscape(name)+".json")
+}
+
+// readFile reads the metadata of key from disk. Missing and unreadable
+// files are fetched again.
+func (c *MetadataCache) readFile(key metadataKey, version string) (metadataEntry, bool) {
+	var e metadataEntry
+	data, err := os.ReadFile(c.path(key, version))
+	if err != nil || json.Unmarshal(data, &e) != nil || e.Fields == nil {
+		return e, false
+	}
+	return e, true
+}
+
+// writeFile writes the metadata of key to disk.
+func (c *MetadataCache) writeFile(key metadataKey, version string, e metadataEntry) error {
+	data, err := json.Marshal(e)
+	if err == nil {
+		err = writeFileAtomic(c.path(key, version), data)
+	}
+	if err != nil {
+		return fmt.Errorf("metadata cache: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to path, creating its directory, through a
+// temporary file renamed over it, so that readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
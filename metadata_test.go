@@ -0,0 +1,229 @@
+package odoorpc_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+// countingOdoo counts the calls of CallMethod.
+type countingOdoo struct {
+	odoorpc.Odoo
+	calls atomic.Int32
+}
+
+func (o *countingOdoo) CallMethod(ctx context.Context, model string, method string, ids []int, kwargs map[string]any) (any, error) {
+	o.calls.Add(1)
+	return o.Odoo.CallMethod(ctx, model, method, ids, kwargs)
+}
+
+func TestMetadataCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name":      {Type: "char", String: "Name", Required: true},
+		"parent_id": {Type: "many2one", String: "Company", Relation: "res.partner"},
+		"type": {Type: "selection", String: "Type", Selection: [][2]string{
+			{"contact", "Contact"}, {"invoice", "Invoice"},
+		}},
+	})
+	srv.DefineModel("res.country", map[string]odoorpctest.Field{"name": {Type: "char"}})
+
+	for name, client := range interceptedClients(t, srv) {
+		o := &countingOdoo{Odoo: client}
+		cache := odoorpc.NewMetadataCache(o)
+		fields, err := cache.Fields(ctx, "res.partner", "")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		want := map[string]odoorpc.FieldInfo{
+			"name":      {Name: "name", Type: "char", String: "Name", Required: true, Store: true},
+			"parent_id": {Name: "parent_id", Type: "many2one", String: "Company", Relation: "res.partner", Store: true},
+			"type": {Name: "type", Type: "selection", String: "Type", Store: true,
+				Selection: [][2]string{{"contact", "Contact"}, {"invoice", "Invoice"}}},
+			"display_name": {Name: "display_name", Type: "char", String: "Display Name", Readonly: true},
+		}
+		for k, w := range want {
+			if !reflect.DeepEqual(fields[k], w) {
+				t.Errorf("%s: field %s = %+v, want %+v", name, k, fields[k], w)
+			}
+		}
+
+		// Each model and language is fetched once.
+		if _, err := cache.Field(ctx, "res.partner", "", "name"); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if _, err := cache.Fields(ctx, "res.partner", "fr_FR"); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if _, err := cache.Fields(ctx, "res.country", ""); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if _, err := cache.Fields(ctx, "res.partner", "fr_FR"); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if n := o.calls.Load(); n != 3 {
+			t.Errorf("%s: %d fields_get calls, want 3", name, n)
+		}
+		if _, err := cache.Field(ctx, "res.partner", "", "nope"); err == nil {
+			t.Errorf("%s: missing field: expected an error", name)
+		}
+
+		if err := cache.Invalidate("res.partner"); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = cache.Fields(ctx, "res.partner", "")
+		_, _ = cache.Fields(ctx, "res.partner", "fr_FR")
+		_, _ = cache.Fields(ctx, "res.country", "")
+		if n := o.calls.Load(); n != 5 {
+			t.Errorf("%s: %d fields_get calls after Invalidate, want 5", name, n)
+		}
+
+		cache.WithTTL(time.Nanosecond)
+		_, _ = cache.Fields(ctx, "res.country", "")
+		if n := o.calls.Load(); n != 6 {
+			t.Errorf("%s: %d fields_get calls after expiry, want 6", name, n)
+		}
+	}
+}
+
+// blockingOdoo holds fields_get calls until release is closed.
+type blockingOdoo struct {
+	countingOdoo
+	release chan struct{}
+}
+
+func (o *blockingOdoo) CallMethod(ctx context.Context, model string, method string, ids []int, kwargs map[string]any) (any, error) {
+	if method == "fields_get" && model == "res.partner" {
+		<-o.release
+	}
+	return o.countingOdoo.CallMethod(ctx, model, method, ids, kwargs)
+}
+
+func TestMetadataCacheConcurrent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{"name": {Type: "char"}})
+	srv.DefineModel("res.country", map[string]odoorpctest.Field{"name": {Type: "char"}})
+	o := &blockingOdoo{
+		countingOdoo: countingOdoo{Odoo: interceptedClients(t, srv)[odoorpc.TransportJSONRPC]},
+		release:      make(chan struct{}),
+	}
+	cache := odoorpc.NewMetadataCache(o)
+
+	var wg sync.WaitGroup
+	results := make([]map[string]odoorpc.FieldInfo, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fields, err := cache.Fields(ctx, "res.partner", "")
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = fields
+		}()
+	}
+	// Another model is not held up by the fetch in flight.
+	if _, err := cache.Fields(ctx, "res.country", ""); err != nil {
+		t.Fatal(err)
+	}
+	close(o.release)
+	wg.Wait()
+	if n := o.calls.Load(); n != 2 {
+		t.Errorf("%d fields_get calls, want 2", n)
+	}
+
+	// The maps are copies.
+	delete(results[0], "name")
+	fields, err := cache.Fields(ctx, "res.partner", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["name"]; !ok {
+		t.Error("modifying a returned map changed the cache")
+	}
+	if _, ok := results[1]["name"]; !ok {
+		t.Error("modifying a returned map changed another one")
+	}
+}
+
+func TestMetadataDiskCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{"name": {Type: "char"}})
+	dir := t.TempDir()
+	client := interceptedClients(t, srv)[odoorpc.TransportJSONRPC]
+
+	first := &countingOdoo{Odoo: client}
+	want, err := odoorpc.NewMetadataCache(first).WithDiskCache(dir, srv.Database).Fields(ctx, "res.partner", "en_US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, srv.Database, srv.Version, "res.partner@en_US.json")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("cache file: %v", err)
+	}
+
+	// Another cache, e.g. of the next run, reads the file.
+	second := &countingOdoo{Odoo: client}
+	cache := odoorpc.NewMetadataCache(second).WithDiskCache(dir, srv.Database)
+	got, err := cache.Fields(ctx, "res.partner", "en_US")
+	if err != nil || !reflect.DeepEqual(got, want) || second.calls.Load() != 0 {
+		t.Errorf("from disk: %v, %v after %d calls; want %v", got, err, second.calls.Load(), want)
+	}
+
+	if err := cache.Invalidate("res.partner"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("cache file after Invalidate: %v", err)
+	}
+
+	// Files are keyed by server version: after an upgrade, the metadata is
+	// fetched again.
+	upgraded := odoorpctest.NewServer()
+	defer upgraded.Close()
+	upgraded.Version = "99.0"
+	upgraded.DefineModel("res.partner", map[string]odoorpctest.Field{"name": {Type: "char"}})
+	third := &countingOdoo{Odoo: interceptedClients(t, upgraded)[odoorpc.TransportJSONRPC]}
+	if _, err := odoorpc.NewMetadataCache(third).WithDiskCache(dir, srv.Database).Fields(ctx, "res.partner", "en_US"); err != nil || third.calls.Load() != 1 {
+		t.Errorf("new version: %v after %d calls, want 1 call", err, third.calls.Load())
+	}
+	if _, err := os.Stat(filepath.Join(dir, srv.Database, "99.0", "res.partner@en_US.json")); err != nil {
+		t.Errorf("cache file of the new version: %v", err)
+	}
+	if err := cache.Invalidate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, srv.Database)); !os.IsNotExist(err) {
+		t.Errorf("database directory after Invalidate: %v", err)
+	}
+}
+
+func TestParseFieldInfo(t *testing.T) {
+	t.Parallel()
+	got, err := odoorpc.ParseFieldInfo(map[string]any{
+		"state": map[string]any{"type": "selection", "selection": []any{[]any{int64(1), "One"}}},
+	})
+	if err != nil || !reflect.DeepEqual(got["state"].Selection, [][2]string{{"1", "One"}}) {
+		t.Errorf("ParseFieldInfo = %+v, %v", got, err)
+	}
+	if _, err := odoorpc.ParseFieldInfo([]any{}); err == nil {
+		t.Error("expected an error for a list")
+	}
+}
@@ -0,0 +1,71 @@
+package odoorpc
+
+import (
+	"context"
+	"time"
+)
+
+// CallResult is the outcome of a finished call, as reported to tracers and
+// metrics.
+type CallResult struct {
+	// Records is the number of records or ids returned, when the result is
+	// a list.
+	Records int
+	// Duration is the time the call took, including the interceptors after
+	// the one reporting it.
+	Duration time.Duration
+	// RequestBytes and ResponseBytes are the body sizes on the wire.
+	RequestBytes  int64
+	ResponseBytes int64
+	// Err is the error of the call, if any, and ErrorClass its ErrorClass.
+	Err        error
+	ErrorClass string
+}
+
+// Metrics receives a measurement for every RPC call. Implementations adapt
+// the measurements to a metrics system; see the expvarmetrics package for
+// one based on expvar.
+//
+// ObserveCall is called concurrently and must not block.
+type Metrics interface {
+	// ObserveCall records a finished call. Retried calls are observed once
+	// per attempt, with info.Attempt > 0 for the retries.
+	ObserveCall(info CallInfo, result CallResult)
+}
+
+// MetricsInterceptor returns an interceptor that reports every call to m.
+func MetricsInterceptor(m Metrics) Interceptor {
+	return func(ctx context.Context, info CallInfo, next Invoker) (any, error) {
+		start := time.Now()
+		res, err := next(ctx, info)
+		m.ObserveCall(info, newCallResult(ctx, res, err, time.Since(start)))
+		return res, err
+	}
+}
+
+// newCallResult builds the CallResult of a call from its result, error,
+// duration and the CallStats of ctx.
+func newCallResult(ctx context.Context, res any, err error, d time.Duration) CallResult {
+	stats := StatsFromContext(ctx)
+	return CallResult{
+		Records:       recordCount(res),
+		Duration:      d,
+		RequestBytes:  stats.RequestBytes(),
+		ResponseBytes: stats.ResponseBytes(),
+		Err:           err,
+		ErrorClass:    ErrorClass(err),
+	}
+}
+
+// recordCount returns the length of list results.
+func recordCount(res any) int {
+	switch res := res.(type) {
+	case []any:
+		return len(res)
+	case []int:
+		return len(res)
+	case []map[string]any:
+		return len(res)
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package odoorpc
+
+import "fmt"
+
+// Many2One is a reference to a record by id and display name, as returned
+// for many2one fields and by name_search.
+type Many2One struct {
+	ID   int
+	Name string
+}
+
+// displayNameMajor is the first major version in which display_name replaces
+// name_get, which Odoo 17 removed.
+const displayNameMajor = 17
+
+// UsesDisplayName reports whether a server of version v provides display
+// names through the display_name field rather than the name_get method.
+func UsesDisplayName(v Version) bool {
+	return v.Major >= displayNameMajor
+}
+
+// ParseMany2Ones converts a list of [id, name] pairs, as returned by
+// name_search and name_get.
+func ParseMany2Ones(result any) ([]Many2One, error) {
+	pairs, ok := result.([]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", result)
+	}
+	refs := make([]Many2One, 0, len(pairs))
+	for _, p := range pairs {
+		pair, ok := p.([]any)
+		if !ok || len(pair) != 2 {
+			return nil, fmt.Errorf("unexpected pair %v in response", p)
+		}
+		id, ok := toFloat(pair[0])
+		if !ok {
+			return nil, fmt.Errorf("unexpected id type %T in response", pair[0])
+		}
+		name, _ := pair[1].(string)
+		refs = append(refs, Many2One{ID: int(id), Name: name})
+	}
+	return refs, nil
+}
+
+// DisplayNames converts records read with the display_name field.
+func DisplayNames(records []map[string]any) []Many2One {
+	refs := make([]Many2One, 0, len(records))
+	for _, rec := range records {
+		id, _ := toFloat(rec["id"])
+		name, _ := rec["display_name"].(string)
+		refs = append(refs, Many2One{ID: int(id), Name: name})
+	}
+	return refs
+}
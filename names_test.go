@@ -0,0 +1,54 @@
+package odoorpc_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+func TestNameSearchAndDisplayNames(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	for _, version := range []string{"16.0", "17.0"} {
+		srv := odoorpctest.NewServer()
+		defer srv.Close()
+		srv.Version = version
+		srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+			"name":          {Type: "char"},
+			"customer_rank": {Type: "integer"},
+		})
+		ids, err := srv.Seed("res.partner",
+			map[string]any{"name": "Acme Corp", "customer_rank": 1},
+			map[string]any{"name": "Acme Supplies", "customer_rank": 0},
+			map[string]any{"name": "Globex", "customer_rank": 1},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, o := range interceptedClients(t, srv) {
+			got, err := o.NameSearch(ctx, "res.partner", "acme", nil, "", 0)
+			want := []odoorpc.Many2One{{ID: ids[0], Name: "Acme Corp"}, {ID: ids[1], Name: "Acme Supplies"}}
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("%s/%s: name_search got %v, %v", version, name, got, err)
+			}
+			got, err = o.NameSearch(ctx, "res.partner", "acme", []any{[]any{"customer_rank", ">", 0}}, "ilike", 0)
+			if err != nil || !reflect.DeepEqual(got, want[:1]) {
+				t.Errorf("%s/%s: name_search with domain got %v, %v", version, name, got, err)
+			}
+			got, err = o.NameSearch(ctx, "res.partner", "Globex", nil, "=", 1)
+			if err != nil || len(got) != 1 || got[0].ID != ids[2] {
+				t.Errorf("%s/%s: exact name_search got %v, %v", version, name, got, err)
+			}
+
+			got, err = o.DisplayNames(ctx, "res.partner", []int{ids[2], ids[0]})
+			want = []odoorpc.Many2One{{ID: ids[2], Name: "Globex"}, {ID: ids[0], Name: "Acme Corp"}}
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("%s/%s: display names got %v, %v", version, name, got, err)
+			}
+		}
+	}
+}
@@ -4,16 +4,15 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ppreeper/odoorpc"
 	"github.com/ppreeper/odoosearchdomain"
 )
 
 // Login
 // Login to the server and return the uid
 func (o *OdooJSON) Login(ctx context.Context) (err error) {
-	if o.url == "" {
-		if err = o.genURL(); err != nil {
-			return fmt.Errorf("genURL failed in login: %w", err)
-		}
+	if _, _, err = o.conn(); err != nil {
+		return fmt.Errorf("genURL failed in login: %w", err)
 	}
 	// Logging in
 	v, err := o.Call(ctx, "common", "login", o.database, o.username, o.password)
@@ -25,7 +24,10 @@ func (o *OdooJSON) Login(ctx context.Context) (err error) {
 		if v2 == 0 {
 			return fmt.Errorf("login failed: invalid credentials")
 		}
+		mu := o.lock()
+		mu.Lock()
 		o.uid = int(v2)
+		mu.Unlock()
 	default:
 		return fmt.Errorf("login failed: unexpected response type %T (credentials may be invalid)", v)
 	}
@@ -45,7 +47,7 @@ func (o *OdooJSON) Login(ctx context.Context) (err error) {
 //	}
 func (o *OdooJSON) Create(ctx context.Context, model string, values map[string]any) (row int, err error) {
 	v, err := o.Call(ctx, "object", "execute",
-		o.database, o.uid, o.password,
+		o.database, o.currentUID(), o.password,
 		model, "create", values,
 	)
 	if err != nil {
@@ -61,7 +63,10 @@ func (o *OdooJSON) Create(ctx context.Context, model string, values map[string]a
 }
 
 // Load
-// Create multiple records using a datamatrix and return their ids
+// Create or update multiple records using a datamatrix and return their ids
+// with the messages of the server. When the server rejects the data, nothing
+// is saved and the error is a *odoorpc.LoadError holding the row-level
+// messages
 // model: model name
 // header: list of field names
 // values: list of lists of field values
@@ -74,33 +79,18 @@ func (o *OdooJSON) Create(ctx context.Context, model string, values map[string]a
 //	["ZExample1", "zexample1@example.com"],
 //	["ZExample2", "zexample1@example.com"],
 //	]
-func (o *OdooJSON) Load(ctx context.Context, model string, header []string, values [][]any) (ids []int, err error) {
-	results, err := o.Call(ctx, "object", "execute",
-		o.database, o.uid, o.password,
+func (o *OdooJSON) Load(ctx context.Context, model string, header []string, values [][]any) (result odoorpc.LoadResult, err error) {
+	v, err := o.Call(ctx, "object", "execute",
+		o.database, o.currentUID(), o.password,
 		model, "load", header, values,
 	)
 	if err != nil {
-		return nil, err
+		return result, err
 	}
-	switch restype := results.(type) {
-	case map[string]any:
-		idmap, ok := restype["ids"].([]any)
-		if !ok {
-			return nil, fmt.Errorf("ids not found in response")
-		}
-		for _, id := range idmap {
-			v, ok := id.(float64)
-			if !ok {
-				return nil, fmt.Errorf("load failed: unexpected id type in response")
-			}
-			ids = append(ids, int(v))
-		}
-	case float64:
-		ids = []int{int(restype)}
-	default:
-		return nil, fmt.Errorf("load failed: unexpected response type %T", results)
+	if result, err = odoorpc.ParseLoadResult(v); err != nil {
+		return result, fmt.Errorf("load failed: %w", err)
 	}
-	return ids, nil
+	return result, result.Err()
 }
 
 // Count record
@@ -115,7 +105,7 @@ func (o *OdooJSON) Load(ctx context.Context, model string, header []string, valu
 // limit = 1
 func (o *OdooJSON) Count(ctx context.Context, model string, domains ...any) (count int, err error) {
 	v, err := o.Call(ctx, "object", "execute",
-		o.database, o.uid, o.password,
+		o.database, o.currentUID(), o.password,
 		model, "search_count", odoosearchdomain.DomainList(domains...),
 	)
 	if err != nil {
@@ -140,7 +130,7 @@ func (o *OdooJSON) FieldsGet(ctx context.Context, model string, fields []string,
 	var v any
 	if len(fieldAttributes) == 0 {
 		v, err = o.Call(ctx, "object", "execute",
-			o.database, o.uid, o.password,
+			o.database, o.currentUID(), o.password,
 			model, "fields_get", odoosearchdomain.DomainString(fields...),
 		)
 		if err != nil {
@@ -148,7 +138,7 @@ func (o *OdooJSON) FieldsGet(ctx context.Context, model string, fields []string,
 		}
 	} else {
 		v, err = o.Call(ctx, "object", "execute",
-			o.database, o.uid, o.password,
+			o.database, o.currentUID(), o.password,
 			model, "fields_get", odoosearchdomain.DomainString(fields...),
 			odoosearchdomain.DomainString(fieldAttributes...),
 		)
@@ -171,7 +161,7 @@ func (o *OdooJSON) FieldsGet(ctx context.Context, model string, fields []string,
 // domain = [[["name", "=", "ZExample1"]]]
 func (o *OdooJSON) GetID(ctx context.Context, model string, domains ...any) (id int, err error) {
 	v, err := o.Call(ctx, "object", "execute",
-		o.database, o.uid, o.password,
+		o.database, o.currentUID(), o.password,
 		model, "search", odoosearchdomain.DomainList(domains...),
 	)
 	if err != nil {
@@ -196,7 +186,7 @@ func (o *OdooJSON) GetID(ctx context.Context, model string, domains ...any) (id
 // domain = [[["name", "=", "ZExample1"]]]
 func (o *OdooJSON) Search(ctx context.Context, model string, domains ...any) (ids []int, err error) {
 	v, err := o.Call(ctx, "object", "execute",
-		o.database, o.uid, o.password,
+		o.database, o.currentUID(), o.password,
 		model, "search", odoosearchdomain.DomainList(domains...),
 	)
 	if err != nil {
@@ -231,7 +221,7 @@ func (o *OdooJSON) Search(ctx context.Context, model string, domains ...any) (id
 // fields = ["name", "email"]
 func (o *OdooJSON) Read(ctx context.Context, model string, ids []int, fields ...string) (records []map[string]any, err error) {
 	v, err := o.Call(ctx, "object", "execute",
-		o.database, o.uid, o.password,
+		o.database, o.currentUID(), o.password,
 		model, "read", ids, odoosearchdomain.DomainString(fields...),
 	)
 	if err != nil {
@@ -268,7 +258,7 @@ func (o *OdooJSON) Read(ctx context.Context, model string, ids []int, fields ...
 // domains = [["name", "=", "ZExample1"]]
 func (o *OdooJSON) SearchRead(ctx context.Context, model string, offset int, limit int, fields []string, domains ...any) (records []map[string]any, err error) {
 	vv, err := o.Call(ctx, "object", "execute",
-		o.database, o.uid, o.password,
+		o.database, o.currentUID(), o.password,
 		model, "search_read", odoosearchdomain.DomainList(domains...), fields, offset, limit,
 	)
 	if err != nil {
@@ -306,7 +296,7 @@ func (o *OdooJSON) SearchRead(ctx context.Context, model string, offset int, lim
 //	}
 func (o *OdooJSON) Write(ctx context.Context, model string, recordID int, values map[string]any) (result bool, err error) {
 	v, err := o.Call(ctx, "object", "execute",
-		o.database, o.uid, o.password,
+		o.database, o.currentUID(), o.password,
 		model, "write", recordID, values,
 	)
 	if err != nil {
@@ -329,7 +319,7 @@ func (o *OdooJSON) Write(ctx context.Context, model string, recordID int, values
 // ids = [1, 2, 3]
 func (o *OdooJSON) Unlink(ctx context.Context, model string, recordIDs []int) (result bool, err error) {
 	v, err := o.Call(ctx, "object", "execute",
-		o.database, o.uid, o.password,
+		o.database, o.currentUID(), o.password,
 		model, "unlink", recordIDs,
 	)
 	if err != nil {
@@ -354,7 +344,7 @@ func (o *OdooJSON) Unlink(ctx context.Context, model string, recordIDs []int) (r
 // method = "search_read"
 func (o *OdooJSON) Execute(ctx context.Context, model string, method string, args []any) (result bool, err error) {
 	v, err := o.Call(ctx, "object", "execute_kw",
-		o.database, o.uid, o.password,
+		o.database, o.currentUID(), o.password,
 		model, method, args,
 	)
 	if err != nil {
@@ -391,7 +381,7 @@ func (o *OdooJSON) ExecuteKw(ctx context.Context, model string, method string, a
 		kw = map[string]any{}
 	}
 	v, err := o.Call(ctx, "object", "execute_kw",
-		o.database, o.uid, o.password,
+		o.database, o.currentUID(), o.password,
 		model, method, args, kw,
 	)
 	if err != nil {
@@ -405,3 +395,207 @@ func (o *OdooJSON) ExecuteKw(ctx context.Context, model string, method string, a
 	}
 	return result, nil
 }
+
+// ServerVersion returns the version of the server. It is queried once and
+// cached until the connection settings change.
+func (o *OdooJSON) ServerVersion(ctx context.Context) (version odoorpc.Version, err error) {
+	mu := o.lock()
+	mu.RLock()
+	cached := o.version
+	mu.RUnlock()
+	if cached != nil {
+		return *cached, nil
+	}
+	v, err := o.Call(ctx, "common", "version")
+	if err != nil {
+		return version, fmt.Errorf("version failed: %w", err)
+	}
+	if version, err = odoorpc.VersionFromInfo(v); err != nil {
+		return version, err
+	}
+	mu.Lock()
+	o.version = &version
+	mu.Unlock()
+	return version, nil
+}
+
+// ReadGroup
+// Group the records matching the domain and aggregate their values, using
+// formatted_read_group or read_group depending on the server version
+// model: model name
+// domain: list of search criteria
+// groupBy: list of groupby specifications
+// aggregates: list of aggregate specifications
+// Example:
+// groupBy = ["partner_id", "date_order:month"]
+// aggregates = ["amount_total:sum"]
+func (o *OdooJSON) ReadGroup(ctx context.Context, model string, domain []any, groupBy []string, aggregates []string, opts odoorpc.ReadGroupOptions) (groups []odoorpc.Group, err error) {
+	version, err := o.ServerVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read_group failed: %w", err)
+	}
+	method := odoorpc.ReadGroupMethod(version)
+	v, err := o.Call(ctx, "object", "execute_kw",
+		o.database, o.currentUID(), o.password,
+		model, method, []any{}, odoorpc.ReadGroupKwargs(method, domain, groupBy, aggregates, opts),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, err)
+	}
+	return odoorpc.ParseGroups(method, groupBy, aggregates, v)
+}
+
+// NameSearch
+// Return the records whose display name matches name, as (id, name) pairs
+// model: model name
+// name: text to match; an empty name matches all records
+// domain: list of search criteria restricting the records
+// operator: comparison operator, "ilike" when empty
+// limit: maximum number of results, 100 when 0
+// Example:
+// name = "acme"
+// domain = [["customer_rank", ">", 0]]
+func (o *OdooJSON) NameSearch(ctx context.Context, model string, name string, domain []any, operator string, limit int) (records []odoorpc.Many2One, err error) {
+	if domain == nil {
+		domain = []any{}
+	}
+	if operator == "" {
+		operator = "ilike"
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	v, err := o.Call(ctx, "object", "execute_kw",
+		o.database, o.currentUID(), o.password,
+		model, "name_search", []any{name, domain, operator, limit},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("name_search failed: %w", err)
+	}
+	if records, err = odoorpc.ParseMany2Ones(v); err != nil {
+		return nil, fmt.Errorf("name_search failed: %w", err)
+	}
+	return records, nil
+}
+
+// DisplayNames
+// Return the display names of the records with the given ids, read from
+// display_name or, before Odoo 17, with name_get
+// model: model name
+// ids: list of record ids
+func (o *OdooJSON) DisplayNames(ctx context.Context, model string, ids []int) (records []odoorpc.Many2One, err error) {
+	version, err := o.ServerVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("display_names failed: %w", err)
+	}
+	if odoorpc.UsesDisplayName(version) {
+		rows, err := o.Read(ctx, model, ids, "display_name")
+		if err != nil {
+			return nil, err
+		}
+		return odoorpc.DisplayNames(rows), nil
+	}
+	v, err := o.Call(ctx, "object", "execute",
+		o.database, o.currentUID(), o.password,
+		model, "name_get", ids,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("name_get failed: %w", err)
+	}
+	if records, err = odoorpc.ParseMany2Ones(v); err != nil {
+		return nil, fmt.Errorf("name_get failed: %w", err)
+	}
+	return records, nil
+}
+
+// ExportData
+// Export the field paths of the records with the given ids as rows, with
+// relations resolved by the server
+// model: model name
+// ids: list of record ids
+// fields: list of field paths
+// Example:
+// fields = ["name", "partner_id/name", "order_line/product_id/id"]
+func (o *OdooJSON) ExportData(ctx context.Context, model string, ids []int, fields []string) (rows [][]any, err error) {
+	v, err := o.Call(ctx, "object", "execute",
+		o.database, o.currentUID(), o.password,
+		model, "export_data", ids, fields,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("export_data failed: %w", err)
+	}
+	if rows, err = odoorpc.ParseExportData(v); err != nil {
+		return nil, fmt.Errorf("export_data failed: %w", err)
+	}
+	return rows, nil
+}
+
+// CallMethod
+// Call any method of the model with keyword arguments and return its raw
+// result
+// model: model name
+// method: method name
+// ids: record ids the method is called on, nil for model methods
+// kwargs: dictionary of keyword arguments
+// Example:
+// method = "parse_preview"
+// ids = [1]
+// kwargs = {"options": {"has_headers": true}}
+func (o *OdooJSON) CallMethod(ctx context.Context, model string, method string, ids []int, kwargs map[string]any) (result any, err error) {
+	args := []any{}
+	if ids != nil {
+		args = []any{ids}
+	}
+	if kwargs == nil {
+		kwargs = map[string]any{}
+	}
+	result, err = o.Call(ctx, "object", "execute_kw",
+		o.database, o.currentUID(), o.password,
+		model, method, args, kwargs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, err)
+	}
+	return result, nil
+}
+
+// RefID
+// Return the model and id of the record an external id refers to
+// xmlid: external id, "module.name"
+// Example:
+// xmlid = "base.main_company"
+func (o *OdooJSON) RefID(ctx context.Context, xmlid string) (model string, id int, err error) {
+	return odoorpc.RefID(ctx, o, xmlid)
+}
+
+// RefIDs
+// Return the records the external ids refer to, looked up in a single call;
+// external ids that do not exist are absent from the result
+// xmlids: list of external ids
+func (o *OdooJSON) RefIDs(ctx context.Context, xmlids []string) (refs map[string]odoorpc.XMLRef, err error) {
+	return odoorpc.RefIDs(ctx, o, xmlids)
+}
+
+// EnsureXMLID
+// Give a record an external id unless it already has it, failing if the
+// external id refers to another record
+// model: model name
+// id: record id
+// xmlid: external id, "module.name"
+func (o *OdooJSON) EnsureXMLID(ctx context.Context, model string, id int, xmlid string) (err error) {
+	return odoorpc.EnsureXMLIDs(ctx, o, model, map[string]int{xmlid: id})
+}
+
+// Upsert
+// Update the record the external id refers to with the values, or create
+// it with the values and the external id, and return its id
+// model: model name
+// xmlid: external id, "module.name"
+// values: dictionary of field values
+func (o *OdooJSON) Upsert(ctx context.Context, model string, xmlid string, values map[string]any) (id int, err error) {
+	ids, err := odoorpc.UpsertXMLIDs(ctx, o, model, []string{xmlid}, []map[string]any{values})
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
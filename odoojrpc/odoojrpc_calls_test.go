@@ -498,14 +498,14 @@ func TestLoad(t *testing.T) {
 	defer ts.Close()
 
 	o := newJRPCTestClient(ts)
-	ids, err := o.Load(context.Background(), "res.partner",
+	res, err := o.Load(context.Background(), "res.partner",
 		[]string{"name"},
 		[][]any{{"Alice"}, {"Bob"}},
 	)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(ids) != 2 || ids[0] != 10 || ids[1] != 11 {
+	if ids := res.IDs; len(ids) != 2 || ids[0] != 10 || ids[1] != 11 {
 		t.Errorf("got %v, want [10 11]", ids)
 	}
 }
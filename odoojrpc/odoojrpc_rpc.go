@@ -9,6 +9,9 @@ import (
 	"io"
 	"math/rand/v2"
 	"net/http"
+	"slices"
+
+	"github.com/ppreeper/odoorpc"
 )
 
 // maxResponseBytes is the maximum number of bytes read from a JSON-RPC response
@@ -57,6 +60,23 @@ func (e *rpcError) Error() string {
 	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
 }
 
+// OdooException returns the server exception class, e.g.
+// "odoo.exceptions.AccessError".
+func (e *rpcError) OdooException() string {
+	name, _ := e.Data["name"].(string)
+	return name
+}
+
+// AuthFailure reports whether the server rejected the credentials or the
+// session expired.
+func (e *rpcError) AuthFailure() bool {
+	switch e.OdooException() {
+	case "odoo.exceptions.AccessDenied", "odoo.http.SessionExpiredException":
+		return true
+	}
+	return e.Code == 100
+}
+
 // EncodeClientRequest encodes parameters for a JSON-RPC client request.
 func encodeClientRequest(service, method string, args any) ([]byte, error) {
 	// Use a non-cryptographic PRNG for JSON-RPC request IDs. The ID is only
@@ -90,25 +110,97 @@ func decodeClientResponse(r io.Reader, reply any) error {
 	return json.Unmarshal(*c.Result, reply)
 }
 
+// Call invokes method on the given JSON-RPC service. Calls to the object
+// service's execute and execute_kw methods are presented to interceptors by
+// model and method, without the leading database, uid and password.
 func (o *OdooJSON) Call(ctx context.Context, service string, method string, args ...any) (res any, err error) {
-	if o.url == "" {
-		if err = o.genURL(); err != nil {
-			return nil, fmt.Errorf("genURL failed: %w", err)
+	info := odoorpc.CallInfo{
+		Transport: odoorpc.TransportJSONRPC,
+		Service:   service,
+		Method:    method,
+		Args:      args,
+	}
+	var credentials []any
+	var keywords bool
+	if service == "object" && (method == "execute" || method == "execute_kw") && len(args) >= 5 {
+		credentials = args[:3:3]
+		info.Model, _ = args[3].(string)
+		info.Method, _ = args[4].(string)
+		info.Args = args[5:]
+		if method == "execute_kw" {
+			info.Args, info.Kwargs, keywords = splitExecuteKw(args[5:])
+		}
+	}
+
+	final := func(ctx context.Context, info odoorpc.CallInfo) (any, error) {
+		wire := info.Args
+		if credentials != nil {
+			wire = append(slices.Clone(credentials), info.Model, info.Method)
+			if info.Attempt > 0 {
+				// Retried after a re-login, which may have changed the uid.
+				wire[1] = o.currentUID()
+			}
+			if keywords {
+				args := info.Args
+				if args == nil {
+					args = []any{}
+				}
+				wire = append(wire, args)
+				if info.Kwargs != nil {
+					wire = append(wire, info.Kwargs)
+				}
+			} else {
+				wire = append(wire, info.Args...)
+			}
+		}
+		return o.call(ctx, info.Service, method, wire...)
+	}
+	if service == "common" {
+		return odoorpc.Invoke(ctx, info, final, o.interceptors...)
+	}
+	return o.relogin.Invoke(ctx, o.Login, info, final, o.interceptors...)
+}
+
+// splitExecuteKw splits the trailing execute_kw parameters into positional
+// and keyword arguments. It reports false, returning params unchanged as the
+// positional arguments, when they are not an args list optionally followed by
+// a kwargs map.
+func splitExecuteKw(params []any) (args []any, kwargs map[string]any, ok bool) {
+	if len(params) > 2 {
+		return params, nil, false
+	}
+	if len(params) > 0 {
+		if args, ok = params[0].([]any); !ok {
+			return params, nil, false
 		}
 	}
+	if len(params) > 1 {
+		if kwargs, ok = params[1].(map[string]any); !ok {
+			return params, nil, false
+		}
+	}
+	return args, kwargs, true
+}
+
+// call sends a JSON-RPC request and decodes the result.
+func (o *OdooJSON) call(ctx context.Context, service string, method string, args ...any) (res any, err error) {
+	url, client, err := o.conn()
+	if err != nil {
+		return nil, fmt.Errorf("genURL failed: %w", err)
+	}
 
 	req, err := encodeClientRequest(service, method, args)
 	if err != nil {
 		return nil, err
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, o.url, bytes.NewBuffer(req))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(req))
 	if err != nil {
 		return nil, err
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 
-	resp, err := o.client.Do(httpReq)
+	resp, err := client.Do(httpReq)
 	if err != nil {
 		return nil, err
 	}
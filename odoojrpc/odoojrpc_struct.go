@@ -1,9 +1,14 @@
 package odoojrpc
 
 import (
+	"crypto/tls"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/ppreeper/odoorpc"
 )
@@ -13,17 +18,31 @@ var _ odoorpc.Odoo = (*OdooJSON)(nil)
 
 // OdooJSON connection
 // Return a new instance of the :class 'OdooJSON' class.
+// Configure it with the With methods before use; after that it is safe for
+// concurrent use, including Login while other calls are in flight.
 type OdooJSON struct {
-	hostname string
-	port     int
-	schema   string
-	database string
-	username string
-	password string
-	url      string
-	uid      int
-	timeout  time.Duration
-	client   *http.Client
+	hostname     string
+	port         int
+	schema       string
+	database     string
+	username     string
+	password     string
+	url          string
+	uid          int
+	version      *odoorpc.Version
+	timeout      time.Duration
+	client       *http.Client
+	baseClient   *http.Client
+	transport    http.RoundTripper
+	baseURL      string
+	host         string
+	tls          *odoorpc.TLSOptions
+	interceptors []odoorpc.Interceptor
+	relogin      *odoorpc.LoginGroup
+
+	// mu guards url, client, uid and version, which genURL, Login and
+	// ServerVersion set while other calls may be in flight.
+	mu *sync.RWMutex
 }
 
 func (o *OdooJSON) WithHostname(hostname string) *OdooJSON {
@@ -56,11 +75,97 @@ func (o *OdooJSON) WithSchema(schema string) *OdooJSON {
 	return o
 }
 
+// WithBaseURL sets the server URL, e.g. "https://erp.example.com/odoo/" for
+// Odoo served under a path prefix behind a reverse proxy. It takes precedence
+// over the schema, hostname and port; the port defaults to 80 for http and
+// 443 for https.
+func (o *OdooJSON) WithBaseURL(baseURL string) *OdooJSON {
+	o.baseURL = baseURL
+	o.url = ""
+	return o
+}
+
+// WithHost sets the Host header sent with every request, e.g. to select a
+// database through Odoo's dbfilter while connecting by IP address.
+func (o *OdooJSON) WithHost(host string) *OdooJSON {
+	o.host = host
+	o.url = ""
+	return o
+}
+
 func (o *OdooJSON) WithTimeout(timeout time.Duration) *OdooJSON {
 	o.timeout = timeout
 	return o
 }
 
+// WithHTTPClient sets the http.Client used for requests, e.g. one with a
+// corporate proxy, a custom dialer or connection pool settings. The client is
+// copied; its Timeout is used instead of the one set by WithTimeout. A nil
+// client restores the default.
+func (o *OdooJSON) WithHTTPClient(client *http.Client) *OdooJSON {
+	o.baseClient = client
+	o.url = ""
+	return o
+}
+
+// WithTransport sets the http.RoundTripper used for requests, e.g. a
+// recording transport or a test double. It takes precedence over the
+// transport of the client set by WithHTTPClient. A nil transport restores the
+// default.
+func (o *OdooJSON) WithTransport(transport http.RoundTripper) *OdooJSON {
+	o.transport = transport
+	o.url = ""
+	return o
+}
+
+// WithTLS sets the TLS options for https connections: trusted CAs, a client
+// certificate, the minimum version and SPKI pins. The options are validated
+// when the URL is built.
+func (o *OdooJSON) WithTLS(options odoorpc.TLSOptions) *OdooJSON {
+	o.tls = &options
+	o.url = ""
+	return o
+}
+
+// WithInterceptors appends interceptors that wrap every call made by the
+// client, in the order given.
+func (o *OdooJSON) WithInterceptors(interceptors ...odoorpc.Interceptor) *OdooJSON {
+	o.interceptors = append(o.interceptors, interceptors...)
+	return o
+}
+
+// WithLogger logs every call made by the client to logger, with credentials
+// and binary values redacted. Successful calls are logged at debug level and
+// failures at error level; use odoorpc.LogInterceptor with WithInterceptors
+// for other levels. A nil logger logs to slog.Default().
+func (o *OdooJSON) WithLogger(logger *slog.Logger) *OdooJSON {
+	return o.WithInterceptors(odoorpc.LogInterceptor(logger, odoorpc.LogOptions{}))
+}
+
+// WithTracer wraps every call made by the client in a span started by tracer
+// and sends the span's W3C traceparent header with the request.
+func (o *OdooJSON) WithTracer(tracer odoorpc.Tracer) *OdooJSON {
+	return o.WithInterceptors(odoorpc.TraceInterceptor(tracer))
+}
+
+// WithMetrics reports the latency, sizes and outcome of every call made by
+// the client to m.
+func (o *OdooJSON) WithMetrics(m odoorpc.Metrics) *OdooJSON {
+	return o.WithInterceptors(odoorpc.MetricsInterceptor(m))
+}
+
+// WithAutoRelogin makes the client log in again and retry a call once when
+// the server rejects it as unauthenticated, e.g. after a server restart or
+// the expiry of a session. Concurrent failures share a single Login. The
+// retry is passed to interceptors with CallInfo.Attempt set to 1.
+func (o *OdooJSON) WithAutoRelogin(enabled bool) *OdooJSON {
+	o.relogin = nil
+	if enabled {
+		o.relogin = new(odoorpc.LoginGroup)
+	}
+	return o
+}
+
 func NewOdoo() *OdooJSON {
 	return &OdooJSON{
 		hostname: "localhost",
@@ -70,11 +175,16 @@ func NewOdoo() *OdooJSON {
 		username: "odoo",
 		password: "odoo",
 		timeout:  30 * time.Second,
+		mu:       new(sync.RWMutex),
 	}
 }
 
 func NewOdooWithConfig(config OdooJSON) *OdooJSON {
 	c := config
+	c.mu = new(sync.RWMutex)
+	if c.relogin != nil {
+		c.relogin = new(odoorpc.LoginGroup)
+	}
 	// Avoid sharing the same *http.Client pointer between the provided config
 	// and the returned Odoo instance. Copy the client struct so callers can
 	// modify the returned client's fields (e.g. Timeout) without affecting the
@@ -86,21 +196,109 @@ func NewOdooWithConfig(config OdooJSON) *OdooJSON {
 	return &c
 }
 
+// lock returns the mutex guarding the connection state, created on first
+// use for clients not built by the constructors.
+func (o *OdooJSON) lock() *sync.RWMutex {
+	p := (*unsafe.Pointer)(unsafe.Pointer(&o.mu))
+	if mu := atomic.LoadPointer(p); mu != nil {
+		return (*sync.RWMutex)(mu)
+	}
+	atomic.CompareAndSwapPointer(p, nil, unsafe.Pointer(new(sync.RWMutex)))
+	return (*sync.RWMutex)(atomic.LoadPointer(p))
+}
+
+// conn returns the base URL and HTTP client, building them on first use.
+func (o *OdooJSON) conn() (string, *http.Client, error) {
+	mu := o.lock()
+	mu.RLock()
+	base, client := o.url, o.client
+	mu.RUnlock()
+	if base != "" {
+		return base, client, nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if o.url == "" {
+		if err := o.genURL(); err != nil {
+			return "", nil, err
+		}
+	}
+	return o.url, o.client, nil
+}
+
+// currentUID returns the uid set by Login.
+func (o *OdooJSON) currentUID() int {
+	mu := o.lock()
+	mu.RLock()
+	defer mu.RUnlock()
+	return o.uid
+}
+
 // genURL returns url string
 func (o *OdooJSON) genURL() error {
-	if o.schema != "http" && o.schema != "https" {
+	schema, hostname, port, prefix := o.schema, o.hostname, o.port, ""
+	if o.baseURL != "" {
+		var err error
+		if schema, hostname, port, prefix, err = odoorpc.ParseBaseURL(o.baseURL); err != nil {
+			return err
+		}
+	}
+	if schema != "http" && schema != "https" {
 		return fmt.Errorf("invalid schema: http or https")
 	}
-	if o.port < 1 || o.port > 65535 {
+	if port < 1 || port > 65535 {
 		return fmt.Errorf("invalid port: 1-65535")
 	}
-	if len(o.hostname) == 0 || len(o.hostname) > 2048 {
+	if len(hostname) == 0 || len(hostname) > 2048 {
 		return fmt.Errorf("invalid hostname length: 1-2048")
 	}
 
-	o.url = fmt.Sprintf("%s://%s:%d/jsonrpc/", o.schema, o.hostname, o.port)
-	if o.client == nil {
-		o.client = &http.Client{Timeout: o.timeout}
+	var tlsConfig *tls.Config
+	if o.tls != nil {
+		if schema != "https" {
+			return fmt.Errorf("invalid tls: options require the https schema")
+		}
+		cfg, err := o.tls.Config()
+		if err != nil {
+			return fmt.Errorf("invalid tls: %w", err)
+		}
+		tlsConfig = cfg
+	}
+
+	client, err := o.httpClient(tlsConfig)
+	if err != nil {
+		return err
 	}
+	o.client = client
+	o.version = nil
+	o.url = odoorpc.JoinURL(schema, hostname, port, prefix, "jsonrpc/")
 	return nil
 }
+
+// httpClient returns the client requests are sent with: a copy of the client
+// set by WithHTTPClient, or a new one with the configured timeout. The
+// transport set by WithTransport replaces the client's own, and is wrapped by
+// odoorpc.InstrumentTransport. A non-nil tlsConfig is applied to the
+// transport.
+func (o *OdooJSON) httpClient(tlsConfig *tls.Config) (*http.Client, error) {
+	client := &http.Client{Timeout: o.timeout}
+	if o.baseClient != nil {
+		clientCopy := *o.baseClient
+		client = &clientCopy
+	}
+	if o.transport != nil {
+		client.Transport = o.transport
+	}
+	if tlsConfig != nil {
+		transport, err := odoorpc.TransportWithTLS(client.Transport, tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls: %w", err)
+		}
+		client.Transport = transport
+	}
+	if o.host != "" {
+		client.Transport = odoorpc.HostTransport(client.Transport, o.host)
+	}
+	client.Transport = odoorpc.InstrumentTransport(client.Transport)
+	return client, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ppreeper/odoorpc"
 	"github.com/ppreeper/odoosearchdomain"
 )
 
@@ -13,7 +14,8 @@ import (
 // is performed here. Configuration errors (missing API key, invalid schema,
 // port, or hostname) are surfaced at this point rather than on the first call.
 func (o *OdooJSON) Login(ctx context.Context) error {
-	return o.genURL()
+	_, _, err := o.conn()
+	return err
 }
 
 // Create
@@ -51,7 +53,10 @@ func (o *OdooJSON) Create(ctx context.Context, model string, values map[string]a
 }
 
 // Load
-// Create multiple records using a datamatrix and return their ids
+// Create or update multiple records using a datamatrix and return their ids
+// with the messages of the server. When the server rejects the data, nothing
+// is saved and the error is a *odoorpc.LoadError holding the row-level
+// messages
 // model: model name
 // header: list of field names
 // values: list of lists of field values
@@ -64,31 +69,18 @@ func (o *OdooJSON) Create(ctx context.Context, model string, values map[string]a
 //	["ZExample1", "zexample1@example.com"],
 //	["ZExample2", "zexample1@example.com"],
 //	]
-func (o *OdooJSON) Load(ctx context.Context, model string, header []string, values [][]any) (ids []int, err error) {
+func (o *OdooJSON) Load(ctx context.Context, model string, header []string, values [][]any) (result odoorpc.LoadResult, err error) {
 	data, err := o.Call(ctx, model, "load", map[string]any{
 		"fields": header,
 		"data":   values,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("load failed: %w", err)
+		return result, fmt.Errorf("load failed: %w", err)
 	}
-
-	responseMap, ok := data.(map[string]any)
-	if !ok {
-		return nil, fmt.Errorf("load failed: unexpected response type")
-	}
-	idmap, ok := responseMap["ids"].([]any)
-	if !ok {
-		return nil, fmt.Errorf("load failed: ids not found in response")
-	}
-	for _, id := range idmap {
-		v, ok := id.(float64)
-		if !ok {
-			return nil, fmt.Errorf("load failed: unexpected id type in response")
-		}
-		ids = append(ids, int(v))
+	if result, err = odoorpc.ParseLoadResult(data); err != nil {
+		return result, fmt.Errorf("load failed: %w", err)
 	}
-	return ids, nil
+	return result, result.Err()
 }
 
 // Count record
@@ -434,3 +426,200 @@ func (o *OdooJSON) Action(ctx context.Context, model string, action string, para
 	result, _ = res.(bool)
 	return result, nil
 }
+
+// ServerVersion returns the version of the server, read from the
+// /web/version route. It is queried once and cached until the connection
+// settings change.
+func (o *OdooJSON) ServerVersion(ctx context.Context) (version odoorpc.Version, err error) {
+	mu := o.lock()
+	mu.RLock()
+	cached := o.version
+	mu.RUnlock()
+	if cached != nil {
+		return *cached, nil
+	}
+	info, err := o.webVersion(ctx)
+	if err != nil {
+		return version, fmt.Errorf("version failed: %w", err)
+	}
+	if version, err = odoorpc.VersionFromInfo(info); err != nil {
+		return version, err
+	}
+	mu.Lock()
+	o.version = &version
+	mu.Unlock()
+	return version, nil
+}
+
+// ReadGroup
+// Group the records matching the domain and aggregate their values, using
+// formatted_read_group or read_group depending on the server version
+// model: model name
+// domain: list of search criteria
+// groupBy: list of groupby specifications
+// aggregates: list of aggregate specifications
+// Example:
+// groupBy = ["partner_id", "date_order:month"]
+// aggregates = ["amount_total:sum"]
+func (o *OdooJSON) ReadGroup(ctx context.Context, model string, domain []any, groupBy []string, aggregates []string, opts odoorpc.ReadGroupOptions) (groups []odoorpc.Group, err error) {
+	version, err := o.ServerVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read_group failed: %w", err)
+	}
+	method := odoorpc.ReadGroupMethod(version)
+	data, err := o.Call(ctx, model, method, odoorpc.ReadGroupKwargs(method, domain, groupBy, aggregates, opts))
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, err)
+	}
+	return odoorpc.ParseGroups(method, groupBy, aggregates, data)
+}
+
+// NameSearch
+// Return the records whose display name matches name, as (id, name) pairs
+// model: model name
+// name: text to match; an empty name matches all records
+// domain: list of search criteria restricting the records
+// operator: comparison operator, "ilike" when empty
+// limit: maximum number of results, 100 when 0
+// Example:
+// name = "acme"
+// domain = [["customer_rank", ">", 0]]
+func (o *OdooJSON) NameSearch(ctx context.Context, model string, name string, domain []any, operator string, limit int) (records []odoorpc.Many2One, err error) {
+	if domain == nil {
+		domain = []any{}
+	}
+	if operator == "" {
+		operator = "ilike"
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	data, err := o.Call(ctx, model, "name_search", map[string]any{
+		"name":     name,
+		"domain":   domain,
+		"operator": operator,
+		"limit":    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("name_search failed: %w", err)
+	}
+	if records, err = odoorpc.ParseMany2Ones(data); err != nil {
+		return nil, fmt.Errorf("name_search failed: %w", err)
+	}
+	return records, nil
+}
+
+// DisplayNames
+// Return the display names of the records with the given ids, read from
+// display_name or, before Odoo 17, with name_get
+// model: model name
+// ids: list of record ids
+func (o *OdooJSON) DisplayNames(ctx context.Context, model string, ids []int) (records []odoorpc.Many2One, err error) {
+	version, err := o.ServerVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("display_names failed: %w", err)
+	}
+	if odoorpc.UsesDisplayName(version) {
+		rows, err := o.Read(ctx, model, ids, "display_name")
+		if err != nil {
+			return nil, err
+		}
+		return odoorpc.DisplayNames(rows), nil
+	}
+	data, err := o.Call(ctx, model, "name_get", map[string]any{"ids": ids})
+	if err != nil {
+		return nil, fmt.Errorf("name_get failed: %w", err)
+	}
+	if records, err = odoorpc.ParseMany2Ones(data); err != nil {
+		return nil, fmt.Errorf("name_get failed: %w", err)
+	}
+	return records, nil
+}
+
+// ExportData
+// Export the field paths of the records with the given ids as rows, with
+// relations resolved by the server
+// model: model name
+// ids: list of record ids
+// fields: list of field paths
+// Example:
+// fields = ["name", "partner_id/name", "order_line/product_id/id"]
+func (o *OdooJSON) ExportData(ctx context.Context, model string, ids []int, fields []string) (rows [][]any, err error) {
+	data, err := o.Call(ctx, model, "export_data", map[string]any{
+		"ids":              ids,
+		"fields_to_export": fields,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("export_data failed: %w", err)
+	}
+	if rows, err = odoorpc.ParseExportData(data); err != nil {
+		return nil, fmt.Errorf("export_data failed: %w", err)
+	}
+	return rows, nil
+}
+
+// CallMethod
+// Call any method of the model with keyword arguments and return its raw
+// result
+// model: model name
+// method: method name
+// ids: record ids the method is called on, nil for model methods
+// kwargs: dictionary of keyword arguments
+// Example:
+// method = "parse_preview"
+// ids = [1]
+// kwargs = {"options": {"has_headers": true}}
+func (o *OdooJSON) CallMethod(ctx context.Context, model string, method string, ids []int, kwargs map[string]any) (result any, err error) {
+	payload := make(map[string]any, len(kwargs)+1)
+	for k, v := range kwargs {
+		payload[k] = v
+	}
+	if ids != nil {
+		payload["ids"] = ids
+	}
+	if result, err = o.Call(ctx, model, method, payload); err != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, err)
+	}
+	return result, nil
+}
+
+// RefID
+// Return the model and id of the record an external id refers to
+// xmlid: external id, "module.name"
+// Example:
+// xmlid = "base.main_company"
+func (o *OdooJSON) RefID(ctx context.Context, xmlid string) (model string, id int, err error) {
+	return odoorpc.RefID(ctx, o, xmlid)
+}
+
+// RefIDs
+// Return the records the external ids refer to, looked up in a single call;
+// external ids that do not exist are absent from the result
+// xmlids: list of external ids
+func (o *OdooJSON) RefIDs(ctx context.Context, xmlids []string) (refs map[string]odoorpc.XMLRef, err error) {
+	return odoorpc.RefIDs(ctx, o, xmlids)
+}
+
+// EnsureXMLID
+// Give a record an external id unless it already has it, failing if the
+// external id refers to another record
+// model: model name
+// id: record id
+// xmlid: external id, "module.name"
+func (o *OdooJSON) EnsureXMLID(ctx context.Context, model string, id int, xmlid string) (err error) {
+	return odoorpc.EnsureXMLIDs(ctx, o, model, map[string]int{xmlid: id})
+}
+
+// Upsert
+// Update the record the external id refers to with the values, or create
+// it with the values and the external id, and return its id
+// model: model name
+// xmlid: external id, "module.name"
+// values: dictionary of field values
+func (o *OdooJSON) Upsert(ctx context.Context, model string, xmlid string, values map[string]any) (id int, err error) {
+	ids, err := odoorpc.UpsertXMLIDs(ctx, o, model, []string{xmlid}, []map[string]any{values})
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
@@ -0,0 +1,61 @@
+package odoojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Error is an error response of the JSON-2 API. Odoo reports server
+// exceptions with a JSON body carrying the exception class, message and
+// traceback; the fields are empty when the body is not JSON, e.g. an HTML
+// page from a reverse proxy.
+type Error struct {
+	// StatusCode is the HTTP status code, e.g. 404.
+	StatusCode int
+	// Status is the HTTP status line, e.g. "404 Not Found".
+	Status string
+	// Name is the exception class, e.g. "odoo.exceptions.MissingError".
+	Name string
+	// Message is the exception message.
+	Message string
+	// Arguments are the exception arguments.
+	Arguments []any
+	// Debug is the server-side traceback.
+	Debug string
+}
+
+func (e *Error) Error() string {
+	var argument string
+	if len(e.Arguments) > 0 {
+		argument = fmt.Sprint(e.Arguments[0])
+	}
+	return fmt.Sprintf("request failed with status %d: %s %v", e.StatusCode, e.Status, argument)
+}
+
+// OdooException returns the exception class, e.g.
+// "odoo.exceptions.AccessError".
+func (e *Error) OdooException() string {
+	return e.Name
+}
+
+// AuthFailure reports whether the server rejected the API key.
+func (e *Error) AuthFailure() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+// decodeError builds an Error from a non-2xx response.
+func decodeError(resp *http.Response) *Error {
+	e := &Error{StatusCode: resp.StatusCode, Status: resp.Status}
+	var body struct {
+		Name      string `json:"name"`
+		Message   string `json:"message"`
+		Arguments []any  `json:"arguments"`
+		Debug     string `json:"debug"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&body); err == nil {
+		e.Name, e.Message, e.Arguments, e.Debug = body.Name, body.Message, body.Arguments, body.Debug
+	}
+	return e
+}
@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+
+	"github.com/ppreeper/odoorpc"
 )
 
 // maxResponseBytes is the maximum number of bytes read from an API response
@@ -17,11 +20,25 @@ const maxResponseBytes = 32 << 20 // 32 MiB
 // Request and Response
 // ----------------------------------------------------------------------------
 
+// Call invokes method on model with payload as the named arguments. The
+// payload is presented to interceptors as CallInfo.Kwargs.
 func (o *OdooJSON) Call(ctx context.Context, model string, method string, payload map[string]any) (any, error) {
-	if o.url == "" {
-		if err := o.genURL(); err != nil {
-			return nil, fmt.Errorf("genURL failed: %w", err)
-		}
+	info := odoorpc.CallInfo{
+		Transport: odoorpc.TransportJSON2,
+		Model:     model,
+		Method:    method,
+		Kwargs:    payload,
+	}
+	return o.relogin.Invoke(ctx, o.Login, info, func(ctx context.Context, info odoorpc.CallInfo) (any, error) {
+		return o.call(ctx, info.Model, info.Method, info.Kwargs)
+	}, o.interceptors...)
+}
+
+// call sends a JSON-2 request and decodes the response body.
+func (o *OdooJSON) call(ctx context.Context, model string, method string, payload map[string]any) (any, error) {
+	_, client, err := o.conn()
+	if err != nil {
+		return nil, fmt.Errorf("genURL failed: %w", err)
 	}
 
 	// Request payload structure
@@ -53,7 +70,7 @@ func (o *OdooJSON) Call(ctx context.Context, model string, method string, payloa
 		req.Header.Set("X-Odoo-Database", o.database)
 	}
 
-	resp, err := o.client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -62,16 +79,7 @@ func (o *OdooJSON) Call(ctx context.Context, model string, method string, payloa
 	// Handle non-2xx responses before attempting to decode the body.
 	// Non-2xx bodies may not be JSON (e.g. HTML from a reverse proxy).
 	if resp.StatusCode >= 400 {
-		var data any
-		var argument string
-		if decErr := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&data); decErr == nil {
-			if responseMap, ok := data.(map[string]any); ok {
-				if args, ok := responseMap["arguments"].([]any); ok && len(args) > 0 {
-					argument = fmt.Sprint(args[0])
-				}
-			}
-		}
-		return nil, fmt.Errorf("request failed with status %d: %s %v", resp.StatusCode, resp.Status, argument)
+		return nil, decodeError(resp)
 	}
 
 	// Decode the response
@@ -82,3 +90,49 @@ func (o *OdooJSON) Call(ctx context.Context, model string, method string, payloa
 
 	return data, nil
 }
+
+// webVersion calls the /web/version JSON route, which reports the server
+// version. The JSON-2 API has no version method of its own.
+func (o *OdooJSON) webVersion(ctx context.Context) (any, error) {
+	info := odoorpc.CallInfo{
+		Transport: odoorpc.TransportJSON2,
+		Method:    "version",
+	}
+	return odoorpc.Invoke(ctx, info, func(ctx context.Context, info odoorpc.CallInfo) (any, error) {
+		base, client, err := o.conn()
+		if err != nil {
+			return nil, fmt.Errorf("genURL failed: %w", err)
+		}
+		body, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "method": "call", "params": map[string]any{}})
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+			strings.TrimSuffix(base, "json/2/")+"web/version", bytes.NewBuffer(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return nil, decodeError(resp)
+		}
+
+		var reply struct {
+			Result any            `json:"result"`
+			Error  map[string]any `json:"error"`
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&reply); err != nil {
+			return nil, err
+		}
+		if reply.Error != nil {
+			return nil, fmt.Errorf("web/version: %v", reply.Error["message"])
+		}
+		return reply.Result, nil
+	}, o.interceptors...)
+}
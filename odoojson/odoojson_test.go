@@ -30,7 +30,7 @@ var genURLTests = []struct {
 	{"empty hostname", "http", "", 8069, "key", "", errors.New("invalid hostname length: 1-2048")},
 	{"empty apikey", "http", "localhost", 8069, "", "", errors.New("invalid apikey: must not be empty")},
 	{"http ok", "http", "localhost", 8069, "mykey", "http://localhost:8069/json/2/", nil},
-	{"https ok", "https", "myhost", 443, "mykey", "https://myhost:443/json/2/", nil},
+	{"https ok", "https", "myhost", 443, "mykey", "https://myhost/json/2/", nil},
 }
 
 func TestGenURL(t *testing.T) {
@@ -635,14 +635,14 @@ func TestLoad(t *testing.T) {
 	defer ts.Close()
 
 	o := newTestClient(ts)
-	ids, err := o.Load(context.Background(), "res.partner",
+	res, err := o.Load(context.Background(), "res.partner",
 		[]string{"name", "email"},
 		[][]any{{"Alice", "alice@example.com"}, {"Bob", "bob@example.com"}},
 	)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(ids) != 2 || ids[0] != 10 || ids[1] != 11 {
+	if ids := res.IDs; len(ids) != 2 || ids[0] != 10 || ids[1] != 11 {
 		t.Errorf("got %v, want [10 11]", ids)
 	}
 }
@@ -5,7 +5,7 @@ import "context"
 type Odoo interface {
 	Login(ctx context.Context) (err error)
 	Create(ctx context.Context, model string, values map[string]any) (row int, err error)
-	Load(ctx context.Context, model string, header []string, values [][]any) (ids []int, err error)
+	Load(ctx context.Context, model string, header []string, values [][]any) (result LoadResult, err error)
 	Count(ctx context.Context, model string, filters ...any) (count int, err error)
 	FieldsGet(ctx context.Context, model string, fields []string, fieldAttributes ...string) (recordFields map[string]any, err error)
 	GetID(ctx context.Context, model string, filters ...any) (id int, err error)
@@ -16,4 +16,14 @@ type Odoo interface {
 	Unlink(ctx context.Context, model string, recordIDs []int) (result bool, err error)
 	Execute(ctx context.Context, model string, method string, args []any) (result bool, err error)
 	ExecuteKw(ctx context.Context, model string, method string, args []any, kwargs []map[string]any) (result bool, err error)
+	ServerVersion(ctx context.Context) (version Version, err error)
+	ExportData(ctx context.Context, model string, ids []int, fields []string) (rows [][]any, err error)
+	NameSearch(ctx context.Context, model string, name string, domain []any, operator string, limit int) (records []Many2One, err error)
+	DisplayNames(ctx context.Context, model string, ids []int) (records []Many2One, err error)
+	ReadGroup(ctx context.Context, model string, domain []any, groupBy []string, aggregates []string, opts ReadGroupOptions) (groups []Group, err error)
+	CallMethod(ctx context.Context, model string, method string, ids []int, kwargs map[string]any) (result any, err error)
+	RefID(ctx context.Context, xmlid string) (model string, id int, err error)
+	RefIDs(ctx context.Context, xmlids []string) (refs map[string]XMLRef, err error)
+	EnsureXMLID(ctx context.Context, model string, id int, xmlid string) (err error)
+	Upsert(ctx context.Context, model string, xmlid string, values map[string]any) (id int, err error)
 }
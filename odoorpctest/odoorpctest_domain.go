@@ -0,0 +1,234 @@
+package odoorpctest
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// filter returns the records of m that match domain, ordered by id.
+func (m *model) filter(domain []any) ([]map[string]any, error) {
+	var out []map[string]any
+	for _, id := range m.ids() {
+		rec := m.records[id]
+		ok, err := m.match(rec, domain)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+// activeDomain restricts domain to active records, like Odoo's active_test,
+// when the model has an active field that domain does not mention and the
+// call context does not set active_test to false.
+func (m *model) activeDomain(domain []any, kw map[string]any) []any {
+	if _, ok := m.fields["active"]; !ok {
+		return domain
+	}
+	if ctx, _ := kw["context"].(map[string]any); ctx["active_test"] == false {
+		return domain
+	}
+	for _, term := range domain {
+		if t, ok := term.([]any); ok && len(t) == 3 && t[0] == "active" {
+			return domain
+		}
+	}
+	return append(slices.Clone(domain), []any{"active", "=", true})
+}
+
+// match evaluates a domain in Odoo's prefix notation against rec. Terms that
+// are not joined by an explicit operator are implicitly ANDed.
+func (m *model) match(rec map[string]any, domain []any) (bool, error) {
+	var stack []bool
+	pop := func() (bool, error) {
+		if len(stack) == 0 {
+			return false, newError(excValueError, "Invalid domain: %v", domain)
+		}
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return v, nil
+	}
+	for i := len(domain) - 1; i >= 0; i-- {
+		item := normalize(domain[i])
+		switch op := item.(type) {
+		case string:
+			switch op {
+			case "!":
+				a, err := pop()
+				if err != nil {
+					return false, err
+				}
+				stack = append(stack, !a)
+			case "&", "|":
+				a, err := pop()
+				if err != nil {
+					return false, err
+				}
+				b, err := pop()
+				if err != nil {
+					return false, err
+				}
+				if op == "&" {
+					stack = append(stack, a && b)
+				} else {
+					stack = append(stack, a || b)
+				}
+			default:
+				return false, newError(excValueError, "Invalid domain operator %q", op)
+			}
+		case []any:
+			v, err := m.leaf(rec, op)
+			if err != nil {
+				return false, err
+			}
+			stack = append(stack, v)
+		default:
+			return false, newError(excValueError, "Invalid domain term: %v", item)
+		}
+	}
+	for _, v := range stack {
+		if !v {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// leaf evaluates a single (field, operator, value) term.
+func (m *model) leaf(rec map[string]any, term []any) (bool, error) {
+	if len(term) != 3 {
+		return false, newError(excValueError, "Invalid leaf %v", term)
+	}
+	op, ok := term[1].(string)
+	if !ok {
+		return false, newError(excValueError, "Invalid leaf %v", term)
+	}
+	op = strings.ToLower(op)
+	value := term[2]
+
+	// TRUE_LEAF (1, '=', 1) and FALSE_LEAF (0, '=', 1).
+	if n, ok := term[0].(int); ok {
+		return n == 1 && op == "=" && value == 1, nil
+	}
+	name, ok := term[0].(string)
+	if !ok {
+		return false, newError(excValueError, "Invalid leaf %v", term)
+	}
+	if strings.Contains(name, ".") {
+		return false, newError(excValueError, "Unsupported field path %q in leaf %v", name, term)
+	}
+	f, ok := m.fields[name]
+	if !ok {
+		return false, newError(excValueError, "Invalid field %s.%s in leaf %v", m.name, name, term)
+	}
+
+	var field any
+	if name == "display_name" {
+		field = m.displayName(rec)
+	} else {
+		field = rec[name]
+	}
+	if f.Type == "boolean" && field == nil {
+		field = false
+	}
+	if b, ok := value.(bool); ok && !b && f.Type != "boolean" {
+		value = nil
+	}
+	if pair, ok := value.([]any); ok && f.Type == "many2one" && len(pair) == 2 && op != "in" && op != "not in" {
+		value = pair[0]
+	}
+
+	switch op {
+	case "=?":
+		if isFalsy(value) {
+			return true, nil
+		}
+		return equal(field, value), nil
+	case "=":
+		return equal(field, value), nil
+	case "!=", "<>":
+		return !equal(field, value), nil
+	case "<", "<=", ">", ">=":
+		if field == nil || value == nil {
+			return false, nil
+		}
+		c := compare(field, value)
+		switch op {
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "in", "not in":
+		list, ok := value.([]any)
+		if !ok {
+			list = []any{value}
+		}
+		in := slices.ContainsFunc(list, func(v any) bool { return equal(field, v) })
+		if op == "in" {
+			return in, nil
+		}
+		return !in, nil
+	case "like", "not like", "ilike", "not ilike", "=like", "=ilike":
+		pattern := fmt.Sprint(value)
+		if !strings.HasPrefix(op, "=") {
+			pattern = "%" + pattern + "%"
+		}
+		rx, err := likePattern(pattern, strings.Contains(op, "ilike"))
+		if err != nil {
+			return false, err
+		}
+		matched := field != nil && rx.MatchString(fmt.Sprint(field))
+		if strings.HasPrefix(op, "not") {
+			return !matched, nil
+		}
+		return matched, nil
+	}
+	return false, newError(excValueError, "Invalid operator %q in leaf %v", op, term)
+}
+
+// equal compares a stored value with a domain value. A list value matches
+// when it contains the domain value, as for x2many fields.
+func equal(field, value any) bool {
+	if list, ok := field.([]any); ok {
+		if value == nil {
+			return len(list) == 0
+		}
+		return slices.ContainsFunc(list, func(v any) bool { return compare(v, value) == 0 })
+	}
+	if field == nil || value == nil {
+		return field == nil && value == nil
+	}
+	return compare(field, value) == 0
+}
+
+// likePattern compiles an SQL LIKE pattern.
+func likePattern(pattern string, insensitive bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	if insensitive {
+		b.WriteString("(?is)")
+	} else {
+		b.WriteString("(?s)")
+	}
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
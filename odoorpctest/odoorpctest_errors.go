@@ -0,0 +1,105 @@
+package odoorpctest
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Exception classes raised by the fake server. They mirror the classes a real
+// Odoo server reports so client-side error handling can be exercised.
+const (
+	excUserError       = "odoo.exceptions.UserError"
+	excValidationError = "odoo.exceptions.ValidationError"
+	excMissingError    = "odoo.exceptions.MissingError"
+	excAccessError     = "odoo.exceptions.AccessError"
+	excAccessDenied    = "odoo.exceptions.AccessDenied"
+	excValueError      = "builtins.ValueError"
+	excTypeError       = "builtins.TypeError"
+)
+
+// odooError is a server-side exception raised while dispatching a call.
+type odooError struct {
+	name    string
+	message string
+	method  string
+}
+
+func (e *odooError) Error() string {
+	return fmt.Sprintf("%s: %s", e.name, e.message)
+}
+
+func newError(name, format string, args ...any) *odooError {
+	return &odooError{name: name, message: fmt.Sprintf(format, args...)}
+}
+
+// isUserError reports whether the exception derives from UserError, which
+// Odoo reports to RPC clients without a traceback.
+func (e *odooError) isUserError() bool {
+	switch e.name {
+	case excUserError, excValidationError, excMissingError:
+		return true
+	}
+	return false
+}
+
+// traceback renders the exception as a Python traceback, the form Odoo uses
+// for unexpected exceptions in XML-RPC faults and JSON-RPC error.data.debug.
+func (e *odooError) traceback() string {
+	method := e.method
+	if method == "" {
+		method = "dispatch"
+	}
+	return "Traceback (most recent call last):\n" +
+		"  File \"odoorpctest\", line 1, in " + method + "\n" +
+		"    raise " + e.name + "\n" +
+		e.name + ": " + e.message + "\n"
+}
+
+// faultCode returns the XML-RPC fault code Odoo uses for the exception, see
+// odoo/addons/base/controllers/rpc.py.
+func (e *odooError) faultCode() int {
+	switch {
+	case e.name == excAccessError:
+		return 4
+	case e.name == excAccessDenied:
+		return 3
+	case e.isUserError():
+		return 2
+	}
+	return 1
+}
+
+// faultString returns the XML-RPC faultString for the exception.
+func (e *odooError) faultString() string {
+	if e.faultCode() == 1 {
+		return e.traceback()
+	}
+	return e.message
+}
+
+// httpStatus returns the status code the JSON-2 API uses for the exception.
+func (e *odooError) httpStatus() int {
+	switch {
+	case e.name == excAccessDenied:
+		return http.StatusUnauthorized
+	case e.name == excAccessError:
+		return http.StatusForbidden
+	case e.name == excMissingError:
+		return http.StatusNotFound
+	case e.isUserError():
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// data returns the error payload shared by the JSON-RPC error.data member and
+// the JSON-2 error body.
+func (e *odooError) data() map[string]any {
+	return map[string]any{
+		"name":      e.name,
+		"message":   e.message,
+		"arguments": []any{e.message},
+		"context":   map[string]any{},
+		"debug":     e.traceback(),
+	}
+}
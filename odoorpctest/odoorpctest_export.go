@@ -0,0 +1,149 @@
+package odoorpctest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// exportData implements export_data(fields_to_export). Field paths traverse
+// relations with "/", e.g. "partner_id/name", and "id" exports the external
+// id of the record. Like Odoo, a path through a one2many or many2many field
+// exports the first related record on the record's line and the others on
+// additional lines whose other columns are empty.
+func (s *Server) exportData(m *model, ids []int, kw map[string]any) (any, error) {
+	fields, err := toStrings(kw["fields_to_export"])
+	if err != nil {
+		return nil, err
+	}
+	recs, err := m.browse(ids)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([][]string, 0, len(fields))
+	for _, f := range fields {
+		paths = append(paths, strings.Split(f, "/"))
+	}
+	rows, err := s.exportRows(m, recs, paths)
+	if err != nil {
+		return nil, err
+	}
+	datas := make([]any, 0, len(rows))
+	for _, row := range rows {
+		datas = append(datas, row)
+	}
+	return map[string]any{"datas": datas}, nil
+}
+
+// exportRows renders the export lines of recs for paths.
+func (s *Server) exportRows(m *model, recs []map[string]any, paths [][]string) ([][]any, error) {
+	var lines [][]any
+	for _, rec := range recs {
+		current := make([]any, len(paths))
+		lines = append(lines, current)
+		expanded := map[string]bool{}
+		for i, path := range paths {
+			name := path[0]
+			if name == "id" {
+				current[i] = exportXMLID(m, rec["id"])
+				continue
+			}
+			f, ok := m.fields[name]
+			if !ok {
+				return nil, newError(excValueError, "Invalid field %q on model %q", name, m.name)
+			}
+			relational := f.Type == "many2one" || f.Type == "one2many" || f.Type == "many2many"
+			if len(path) == 1 || !relational {
+				current[i] = s.exportValue(m, rec, name)
+				continue
+			}
+			if expanded[name] {
+				continue
+			}
+			expanded[name] = true
+
+			// Export all the paths through this relation together.
+			var sub [][]string
+			var cols []int
+			for j, p := range paths {
+				if p[0] == name && len(p) > 1 {
+					sub, cols = append(sub, p[1:]), append(cols, j)
+				}
+			}
+			co := s.model(f.Relation)
+			var related []map[string]any
+			for _, id := range relatedIDs(rec[name]) {
+				if r, ok := co.records[id]; ok {
+					related = append(related, r)
+				}
+			}
+			subLines, err := s.exportRows(co, related, sub)
+			if err != nil {
+				return nil, err
+			}
+			for _, j := range cols {
+				current[j] = ""
+			}
+			for n, line := range subLines {
+				target := current
+				if n > 0 {
+					target = make([]any, len(paths))
+					for j := range target {
+						target[j] = ""
+					}
+					lines = append(lines, target)
+				}
+				for k, j := range cols {
+					target[j] = line[k]
+				}
+			}
+		}
+	}
+	return lines, nil
+}
+
+// exportValue renders a field value the way export_data does: relations by
+// display name, empty values as "" except for numbers and booleans.
+func (s *Server) exportValue(m *model, rec map[string]any, name string) any {
+	f := m.fields[name]
+	switch f.Type {
+	case "many2one", "one2many", "many2many":
+		co := s.model(f.Relation)
+		var names []string
+		for _, id := range relatedIDs(rec[name]) {
+			if r, ok := co.records[id]; ok {
+				names = append(names, co.displayName(r))
+			}
+		}
+		return strings.Join(names, ",")
+	case "boolean":
+		return !isFalsy(rec[name])
+	}
+	v := s.readValue(m, rec, name)
+	if v == false {
+		return ""
+	}
+	return v
+}
+
+// relatedIDs returns the ids stored in a relational field.
+func relatedIDs(v any) []int {
+	switch v := v.(type) {
+	case int:
+		return []int{v}
+	case []any:
+		ids := make([]int, 0, len(v))
+		for _, x := range v {
+			if id, ok := x.(int); ok {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+	return nil
+}
+
+// exportXMLID returns the external id export_data generates for records
+// without one.
+func exportXMLID(m *model, id any) string {
+	return fmt.Sprintf("__export__.%s_%v", strings.ReplaceAll(m.name, ".", "_"), id)
+}
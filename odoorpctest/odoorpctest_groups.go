@@ -0,0 +1,353 @@
+package odoorpctest
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// groupSpec is a parsed groupby specification such as "date:month".
+type groupSpec struct {
+	spec        string
+	field       string
+	granularity string
+}
+
+// aggregateSpec is a parsed aggregate specification such as
+// "amount:sum" or "total:sum(amount)".
+type aggregateSpec struct {
+	spec  string
+	name  string
+	field string
+	fn    string
+}
+
+// group is the records sharing the same groupby values.
+type group struct {
+	keys []groupKey
+	recs []map[string]any
+}
+
+// groupKey is the value of a group for one groupby specification.
+type groupKey struct {
+	value any // id, period start or field value; nil for no value
+	label string
+	end   string // period end, for date granularities
+}
+
+// readGroup implements read_group(domain, fields, groupby, offset, limit,
+// orderby, lazy), rendering groups the way Odoo 17 does.
+func (s *Server) readGroup(m *model, _ []int, kw map[string]any) (any, error) {
+	fields, err := toStrings(kw["fields"])
+	if err != nil {
+		return nil, err
+	}
+	var aggs []aggregateSpec
+	for _, spec := range fields {
+		if spec == "__count" || !strings.Contains(spec, ":") {
+			continue
+		}
+		agg, err := m.parseAggregate(spec)
+		if err != nil {
+			return nil, err
+		}
+		aggs = append(aggs, agg)
+	}
+	specs, groups, err := s.groups(m, kw, "orderby")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]any, 0, len(groups))
+	for _, g := range groups {
+		row := map[string]any{"__count": len(g.recs)}
+		ranges := map[string]any{}
+		for i, spec := range specs {
+			k := g.keys[i]
+			switch {
+			case k.value == nil:
+				row[spec.spec] = false
+			case spec.granularity != "":
+				row[spec.spec] = k.label
+				ranges[spec.spec] = map[string]any{"from": k.value, "to": k.end}
+			case m.fields[spec.field].Type == "many2one":
+				row[spec.spec] = []any{k.value, k.label}
+			default:
+				row[spec.spec] = k.value
+			}
+		}
+		if len(ranges) > 0 {
+			row["__range"] = ranges
+		}
+		for _, agg := range aggs {
+			row[agg.name] = aggregate(agg, g.recs)
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// formattedReadGroup implements formatted_read_group(domain, groupby,
+// aggregates, having, offset, limit, order), which replaces read_group from
+// Odoo 19.
+func (s *Server) formattedReadGroup(m *model, _ []int, kw map[string]any) (any, error) {
+	if s.major() < 19 {
+		return nil, newError(excValueError, "The method '%s.formatted_read_group' does not exist", m.name)
+	}
+	specs, err := toStrings(kw["aggregates"])
+	if err != nil {
+		return nil, err
+	}
+	var aggs []aggregateSpec
+	for _, spec := range specs {
+		if spec == "__count" {
+			continue
+		}
+		agg, err := m.parseAggregate(spec)
+		if err != nil {
+			return nil, err
+		}
+		aggs = append(aggs, agg)
+	}
+	gspecs, groups, err := s.groups(m, kw, "order")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]any, 0, len(groups))
+	for _, g := range groups {
+		row := map[string]any{"__count": len(g.recs)}
+		for i, spec := range gspecs {
+			k := g.keys[i]
+			switch {
+			case k.value == nil:
+				row[spec.spec] = false
+			case spec.granularity != "" || m.fields[spec.field].Type == "many2one":
+				row[spec.spec] = []any{k.value, k.label}
+			default:
+				row[spec.spec] = k.value
+			}
+		}
+		for _, agg := range aggs {
+			row[agg.spec] = aggregate(agg, g.recs)
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// groups filters the records by the domain in kw and groups them by its
+// groupby specifications, applying the order, offset and limit of kw.
+func (s *Server) groups(m *model, kw map[string]any, orderParam string) ([]groupSpec, []group, error) {
+	names, err := toStrings(kw["groupby"])
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(names) == 0 {
+		return nil, nil, newError(excValueError, "groupby must not be empty")
+	}
+	specs := make([]groupSpec, 0, len(names))
+	for _, name := range names {
+		field, granularity, _ := strings.Cut(name, ":")
+		f, ok := m.fields[field]
+		if !ok {
+			return nil, nil, newError(excValueError, "Invalid field %q on model %q", field, m.name)
+		}
+		if (f.Type == "date" || f.Type == "datetime") && granularity == "" {
+			granularity = "month"
+		}
+		switch granularity {
+		case "", "day", "week", "month", "quarter", "year":
+		default:
+			return nil, nil, newError(excValueError, "Invalid granularity %q in %q", granularity, name)
+		}
+		specs = append(specs, groupSpec{spec: name, field: field, granularity: granularity})
+	}
+
+	domain, _ := kw["domain"].([]any)
+	recs, err := m.filter(m.activeDomain(domain, kw))
+	if err != nil {
+		return nil, nil, err
+	}
+	var groups []group
+	index := map[string]int{}
+	for _, rec := range recs {
+		keys := make([]groupKey, len(specs))
+		var id strings.Builder
+		for i, spec := range specs {
+			keys[i] = s.groupKey(m, spec, rec[spec.field])
+			fmt.Fprintf(&id, "%v\x00", keys[i].value)
+		}
+		i, ok := index[id.String()]
+		if !ok {
+			i = len(groups)
+			index[id.String()] = i
+			groups = append(groups, group{keys: keys})
+		}
+		groups[i].recs = append(groups[i].recs, rec)
+	}
+
+	desc := false
+	if order, _ := kw[orderParam].(string); order != "" {
+		desc = strings.HasSuffix(strings.ToLower(strings.TrimSpace(order)), " desc")
+	}
+	sortGroups(groups, desc)
+	if offset, _ := kw["offset"].(int); offset > 0 {
+		groups = groups[min(offset, len(groups)):]
+	}
+	if limit, _ := kw["limit"].(int); limit > 0 && limit < len(groups) {
+		groups = groups[:limit]
+	}
+	return specs, groups, nil
+}
+
+// sortGroups orders groups by their keys, groups without a value last.
+func sortGroups(groups []group, desc bool) {
+	slices.SortStableFunc(groups, func(a, b group) int {
+		for i := range a.keys {
+			x, y := a.keys[i].value, b.keys[i].value
+			switch {
+			case x == nil && y == nil:
+				continue
+			case x == nil:
+				return 1
+			case y == nil:
+				return -1
+			}
+			if c := compare(x, y); c != 0 {
+				if desc {
+					return -c
+				}
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// groupKey computes the group of value for spec.
+func (s *Server) groupKey(m *model, spec groupSpec, value any) groupKey {
+	if isFalsy(value) {
+		return groupKey{}
+	}
+	f := m.fields[spec.field]
+	switch {
+	case f.Type == "many2one":
+		id, _ := value.(int)
+		label := fmt.Sprintf("%s,%d", f.Relation, id)
+		if co, ok := s.models[f.Relation]; ok {
+			if rec, ok := co.records[id]; ok {
+				label = co.displayName(rec)
+			}
+		}
+		return groupKey{value: id, label: label}
+	case spec.granularity != "":
+		str, _ := value.(string)
+		t, err := time.Parse(datetimeFormat, str)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, str); err != nil {
+				return groupKey{value: value, label: fmt.Sprint(value)}
+			}
+		}
+		start, end, label := period(t, spec.granularity)
+		layout := time.DateOnly
+		if f.Type == "datetime" {
+			layout = datetimeFormat
+		}
+		return groupKey{value: start.Format(layout), label: label, end: end.Format(layout)}
+	}
+	return groupKey{value: value, label: fmt.Sprint(value)}
+}
+
+// period returns the bounds and label of the period of t.
+func period(t time.Time, granularity string) (start, end time.Time, label string) {
+	y, mo, d := t.Date()
+	switch granularity {
+	case "day":
+		start = time.Date(y, mo, d, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 0, 1), start.Format("02 Jan 2006")
+	case "week":
+		start = time.Date(y, mo, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+		year, week := start.ISOWeek()
+		return start, start.AddDate(0, 0, 7), "W" + strconv.Itoa(week) + " " + strconv.Itoa(year)
+	case "quarter":
+		q := (int(mo) - 1) / 3
+		start = time.Date(y, time.Month(q*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 3, 0), "Q" + strconv.Itoa(q+1) + " " + strconv.Itoa(y)
+	case "year":
+		start = time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, 0), strconv.Itoa(y)
+	}
+	start = time.Date(y, mo, 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0), start.Format("January 2006")
+}
+
+// parseAggregate parses "field:fn" or "name:fn(field)".
+func (m *model) parseAggregate(spec string) (aggregateSpec, error) {
+	name, fn, ok := strings.Cut(spec, ":")
+	if !ok {
+		return aggregateSpec{}, newError(excValueError, "Invalid aggregate %q", spec)
+	}
+	field := name
+	if i := strings.IndexByte(fn, '('); i >= 0 && strings.HasSuffix(fn, ")") {
+		fn, field = fn[:i], fn[i+1:len(fn)-1]
+	}
+	if _, ok := m.fields[field]; !ok {
+		return aggregateSpec{}, newError(excValueError, "Invalid field %q on model %q", field, m.name)
+	}
+	switch fn {
+	case "sum", "avg", "min", "max", "count", "count_distinct":
+	default:
+		return aggregateSpec{}, newError(excValueError, "Invalid aggregate function %q in %q", fn, spec)
+	}
+	return aggregateSpec{spec: spec, name: name, field: field, fn: fn}, nil
+}
+
+// aggregate computes agg over recs.
+func aggregate(agg aggregateSpec, recs []map[string]any) any {
+	switch agg.fn {
+	case "count":
+		n := 0
+		for _, rec := range recs {
+			if !isFalsy(rec[agg.field]) {
+				n++
+			}
+		}
+		return n
+	case "count_distinct":
+		seen := map[string]bool{}
+		for _, rec := range recs {
+			if v := rec[agg.field]; !isFalsy(v) {
+				seen[fmt.Sprint(v)] = true
+			}
+		}
+		return len(seen)
+	}
+	var sum, lo, hi float64
+	n := 0
+	for _, rec := range recs {
+		v, ok := toFloat(rec[agg.field])
+		if !ok {
+			continue
+		}
+		if n == 0 || v < lo {
+			lo = v
+		}
+		if n == 0 || v > hi {
+			hi = v
+		}
+		sum += v
+		n++
+	}
+	switch {
+	case n == 0 && agg.fn != "sum":
+		return false
+	case agg.fn == "avg":
+		return sum / float64(n)
+	case agg.fn == "min":
+		return lo
+	case agg.fn == "max":
+		return hi
+	}
+	return sum
+}
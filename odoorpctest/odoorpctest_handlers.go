@@ -0,0 +1,161 @@
+package odoorpctest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ppreeper/odoorpc/xmlrpc"
+)
+
+// maxRequestBytes bounds the size of request bodies the server reads.
+const maxRequestBytes = 32 << 20 // 32 MiB
+
+// asOdooError converts err into an *odooError, wrapping unexpected errors as
+// generic server exceptions.
+func asOdooError(err error) *odooError {
+	var oe *odooError
+	if errors.As(err, &oe) {
+		return oe
+	}
+	return &odooError{name: excValueError, message: err.Error()}
+}
+
+// handleJSONRPC serves the /jsonrpc endpoint.
+func (s *Server) handleJSONRPC(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     any `json:"id"`
+		Params struct {
+			Service string `json:"service"`
+			Method  string `json:"method"`
+			Args    []any  `json:"args"`
+		} `json:"params"`
+	}
+	resp := map[string]any{"jsonrpc": "2.0"}
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		resp["error"] = map[string]any{"code": -32700, "message": "Parse error", "data": map[string]any{}}
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+	resp["id"] = req.ID
+
+	var res any
+	var err error
+	switch req.Params.Service {
+	case "common":
+		res, err = s.common(req.Params.Method, req.Params.Args)
+	case "object":
+		res, err = s.object(req.Params.Method, req.Params.Args)
+	default:
+		err = newError(excValueError, "Unknown service %q", req.Params.Service)
+	}
+	if err != nil {
+		resp["error"] = map[string]any{
+			"code":    200,
+			"message": "Odoo Server Error",
+			"data":    asOdooError(err).data(),
+		}
+	} else {
+		resp["result"] = res
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleXMLRPC serves the /xmlrpc/2/common and /xmlrpc/2/object endpoints.
+func (s *Server) handleXMLRPC(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/xml")
+
+	var res any
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes))
+	if err == nil {
+		var method string
+		var params []any
+		if method, params, err = xmlrpc.DecodeMethodCall(body); err == nil {
+			switch service := r.PathValue("service"); service {
+			case "common":
+				res, err = s.common(method, params)
+			case "object":
+				res, err = s.object(method, params)
+			default:
+				err = newError(excValueError, "Unknown service %q", service)
+			}
+		}
+	}
+
+	var out []byte
+	if err != nil {
+		oe := asOdooError(err)
+		out, err = xmlrpc.EncodeFault(xmlrpc.FaultError{Code: oe.faultCode(), String: oe.faultString()})
+	} else {
+		out, err = xmlrpc.EncodeMethodResponse(res)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(out)
+}
+
+// handleJSON2 serves the /json/2/<model>/<method> endpoint. Arguments are
+// passed by name in the body; the record ids of multi methods are passed
+// as "ids".
+func (s *Server) handleJSON2(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	fail := func(err error) {
+		oe := asOdooError(err)
+		w.WriteHeader(oe.httpStatus())
+		json.NewEncoder(w).Encode(oe.data())
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token != s.APIKey {
+		fail(newError(excAccessDenied, "Access Denied"))
+		return
+	}
+	if db := r.Header.Get("X-Odoo-Database"); db != "" && db != s.Database {
+		fail(newError(excAccessDenied, "Access Denied"))
+		return
+	}
+
+	kwargs := map[string]any{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestBytes)).Decode(&kwargs); err != nil && err != io.EOF {
+		fail(newError(excValueError, "Invalid JSON body: %v", err))
+		return
+	}
+	var ids []int
+	if v, ok := kwargs["ids"]; ok {
+		delete(kwargs, "ids")
+		var err error
+		if ids, err = toIDs(normalize(v)); err != nil {
+			fail(err)
+			return
+		}
+	}
+
+	name := r.PathValue("method")
+	if meth, ok := methods[name]; ok && meth.multi && ids == nil {
+		ids = []int{}
+	}
+	res, err := s.call(r.PathValue("model"), name, ids, nil, kwargs)
+	if err != nil {
+		fail(err)
+		return
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// handleWebVersion serves the /web/version JSON route, which reports the
+// server version without authentication.
+func (s *Server) handleWebVersion(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID any `json:"id"`
+	}
+	json.NewDecoder(io.LimitReader(r.Body, maxRequestBytes)).Decode(&req)
+	info, _ := s.common("version", []any{})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": info})
+}
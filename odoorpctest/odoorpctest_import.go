@@ -0,0 +1,299 @@
+package odoorpctest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// importModel is the model of the base_import wizard.
+const importModel = "base_import.import"
+
+// loadMethod implements load(fields, data): it creates one record per row of
+// data, converting the values from their import representation. Like Odoo,
+// it creates nothing when any row fails, and then returns ids false with the
+// row-level error messages.
+func (s *Server) loadMethod(m *model, _ []int, kw map[string]any) (any, error) {
+	fields, err := toStrings(kw["fields"])
+	if err != nil {
+		return nil, err
+	}
+	data, ok := kw["data"].([]any)
+	if !ok {
+		return nil, newError(excTypeError, "load() missing required argument 'data'")
+	}
+	ids, messages := s.load(m, fields, data)
+	res := map[string]any{"ids": false, "messages": messages, "nextrow": 0}
+	if ids != nil {
+		res["ids"] = ids
+	}
+	return res, nil
+}
+
+// load creates the records of data and returns their ids, or nil ids and the
+// error messages if any row is invalid.
+func (s *Server) load(m *model, fields []string, data []any) ([]any, []any) {
+	nextID := m.nextID
+	var ids, messages []any
+	for row, r := range data {
+		values, _ := r.([]any)
+		vals := map[string]any{}
+		var failed bool
+		for i, name := range fields {
+			if i >= len(values) || name == "" {
+				continue
+			}
+			if _, ok := magicFields[name]; ok {
+				// External ids are not supported; the column is ignored.
+				continue
+			}
+			v, err := s.importValue(m, name, values[i])
+			if err != nil {
+				messages = append(messages, importMessage(row, name, err))
+				failed = true
+				continue
+			}
+			if v != nil {
+				vals[name] = v
+			}
+		}
+		if failed {
+			continue
+		}
+		id, err := m.create(vals, s.now())
+		if err != nil {
+			messages = append(messages, importMessage(row, "", err))
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if len(messages) > 0 {
+		rollback(m, ids, nextID)
+		return nil, messages
+	}
+	if ids == nil {
+		ids = []any{}
+	}
+	return ids, []any{}
+}
+
+// rollback deletes the records created since nextID was current.
+func rollback(m *model, ids []any, nextID int) {
+	for _, id := range ids {
+		delete(m.records, id.(int))
+	}
+	m.nextID = nextID
+}
+
+// importMessage renders err as a load message about row.
+func importMessage(row int, field string, err error) map[string]any {
+	return map[string]any{
+		"type":    "error",
+		"message": asOdooError(err).message,
+		"record":  row,
+		"field":   field,
+		"rows":    map[string]any{"from": row, "to": row},
+	}
+}
+
+// importValue converts the import representation of a value of field name:
+// numbers and booleans may be strings, and many2one values may be given by
+// display name.
+func (s *Server) importValue(m *model, name string, v any) (any, error) {
+	f, ok := m.fields[name]
+	if !ok {
+		if m.strict {
+			return nil, newError(excValueError, "Unknown field %q", name)
+		}
+		if str, isStr := v.(string); isStr && str == "" {
+			return nil, nil
+		}
+		return v, nil
+	}
+	str, isStr := v.(string)
+	if !isStr {
+		return v, nil
+	}
+	if str == "" {
+		return nil, nil
+	}
+	switch f.Type {
+	case "integer":
+		n, err := strconv.Atoi(str)
+		if err != nil {
+			return nil, newError(excValueError, "'%s' does not seem to be an integer for field '%s'", str, name)
+		}
+		return n, nil
+	case "float", "monetary":
+		x, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, newError(excValueError, "'%s' does not seem to be a number for field '%s'", str, name)
+		}
+		return x, nil
+	case "boolean":
+		switch strings.ToLower(str) {
+		case "1", "true", "yes":
+			return true, nil
+		case "0", "false", "no":
+			return false, nil
+		}
+		return nil, newError(excValueError, "Unknown value '%s' for boolean field '%s'", str, name)
+	case "many2one":
+		co := s.model(f.Relation)
+		for _, id := range co.ids() {
+			if co.displayName(co.records[id]) == str {
+				return id, nil
+			}
+		}
+		return nil, newError(excValueError, "No matching record found for name '%s' in field '%s'", str, name)
+	}
+	return str, nil
+}
+
+// importFile decodes the CSV file of a base_import wizard.
+func (s *Server) importFile(ids []int, options map[string]any) (wizard map[string]any, rows [][]string, err error) {
+	recs, err := s.model(importModel).browse(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(recs) != 1 {
+		return nil, nil, newError(excValueError, "Expected singleton: %s%v", importModel, ids)
+	}
+	wizard = recs[0]
+	encoded, _ := wizard["file"].(string)
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, newError(excValueError, "Invalid file: %v", err)
+	}
+	r := csv.NewReader(bytes.NewReader(raw))
+	r.FieldsPerRecord = -1
+	if sep, _ := options["separator"].(string); sep != "" {
+		r.Comma = []rune(sep)[0]
+	}
+	if rows, err = r.ReadAll(); err != nil {
+		return nil, nil, newError(excValueError, "Invalid CSV file: %v", err)
+	}
+	return wizard, rows, nil
+}
+
+// parsePreview implements base_import.import.parse_preview(options). It
+// returns the headers of the file and matches them to fields of the target
+// model by name or label.
+func (s *Server) parsePreview(m *model, ids []int, kw map[string]any) (any, error) {
+	if m.name != importModel {
+		return nil, newError(excValueError, "The method '%s.parse_preview' does not exist", m.name)
+	}
+	options, _ := kw["options"].(map[string]any)
+	wizard, rows, err := s.importFile(ids, options)
+	if err != nil {
+		return map[string]any{"error": asOdooError(err).message}, nil
+	}
+	target := s.model(fmt.Sprint(wizard["res_model"]))
+	if len(rows) == 0 {
+		return map[string]any{"error": "Import file has no content or is corrupt"}, nil
+	}
+	hasHeaders, _ := options["has_headers"].(bool)
+	var headers []any
+	matches := map[string]any{}
+	if hasHeaders {
+		for i, h := range rows[0] {
+			headers = append(headers, h)
+			if name := matchField(target, h); name != "" {
+				matches[strconv.Itoa(i)] = []any{name}
+			}
+		}
+		rows = rows[1:]
+	}
+	preview := make([]any, 0, len(rows))
+	for _, row := range rows[:min(len(rows), 10)] {
+		cells := make([]any, 0, len(row))
+		for _, c := range row {
+			cells = append(cells, c)
+		}
+		preview = append(preview, cells)
+	}
+	return map[string]any{
+		"headers": headers,
+		"matches": matches,
+		"preview": preview,
+		"options": options,
+	}, nil
+}
+
+// matchField returns the field of m a column header refers to by
+// case-insensitive name or label, or "" if none does.
+func matchField(m *model, header string) string {
+	if _, ok := m.fields[header]; ok {
+		return header
+	}
+	for name, f := range m.fields {
+		if strings.EqualFold(name, header) || f.String != "" && strings.EqualFold(f.String, header) {
+			return name
+		}
+	}
+	return ""
+}
+
+// executeImport implements base_import.import.execute_import(fields,
+// columns, options, dryrun). In dry-run mode the records are validated and
+// rolled back.
+func (s *Server) executeImport(m *model, ids []int, kw map[string]any) (any, error) {
+	if m.name != importModel {
+		return nil, newError(excValueError, "The method '%s.execute_import' does not exist", m.name)
+	}
+	fields, err := toStringsOrFalse(kw["fields"])
+	if err != nil {
+		return nil, err
+	}
+	options, _ := kw["options"].(map[string]any)
+	wizard, rows, err := s.importFile(ids, options)
+	if err != nil {
+		return nil, err
+	}
+	if hasHeaders, _ := options["has_headers"].(bool); hasHeaders && len(rows) > 0 {
+		rows = rows[1:]
+	}
+	data := make([]any, 0, len(rows))
+	for _, row := range rows {
+		cells := make([]any, 0, len(row))
+		for _, c := range row {
+			cells = append(cells, c)
+		}
+		data = append(data, cells)
+	}
+	target := s.model(fmt.Sprint(wizard["res_model"]))
+	nextID := target.nextID
+	created, messages := s.load(target, fields, data)
+	res := map[string]any{"ids": false, "messages": messages, "nextrow": 0, "name": []any{}}
+	if created != nil {
+		res["ids"] = created
+		if dryrun, _ := kw["dryrun"].(bool); dryrun {
+			rollback(target, created, nextID)
+		}
+	}
+	return res, nil
+}
+
+// toStringsOrFalse converts a list whose items are names or false, as in
+// the fields of execute_import where false skips a column.
+func toStringsOrFalse(v any) ([]string, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return toStrings(v)
+	}
+	out := make([]string, 0, len(list))
+	for _, x := range list {
+		switch x := x.(type) {
+		case string:
+			out = append(out, x)
+		case bool, nil:
+			out = append(out, "")
+		default:
+			return nil, newError(excTypeError, "Expected a list of field names, got %v", v)
+		}
+	}
+	return out, nil
+}
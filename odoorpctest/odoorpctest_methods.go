@@ -0,0 +1,351 @@
+package odoorpctest
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// method describes an ORM method the fake server implements.
+type method struct {
+	// multi methods receive the record ids as their first positional
+	// argument, like Odoo's call_kw_multi.
+	multi bool
+	// params lists the Python parameter names in positional order.
+	params []string
+	fn     func(s *Server, m *model, ids []int, kw map[string]any) (any, error)
+}
+
+var methods = map[string]method{
+	"create":        {params: []string{"vals_list"}, fn: (*Server).create},
+	"read":          {multi: true, params: []string{"fields", "load"}, fn: (*Server).readMethod},
+	"search":        {params: []string{"domain", "offset", "limit", "order"}, fn: (*Server).search},
+	"search_read":   {params: []string{"domain", "fields", "offset", "limit", "order"}, fn: (*Server).searchRead},
+	"search_count":  {params: []string{"domain", "limit"}, fn: (*Server).searchCount},
+	"write":         {multi: true, params: []string{"vals"}, fn: (*Server).writeMethod},
+	"unlink":        {multi: true, fn: (*Server).unlinkMethod},
+	"fields_get":    {params: []string{"allfields", "attributes"}, fn: (*Server).fieldsGet},
+	"read_group":    {params: []string{"domain", "fields", "groupby", "offset", "limit", "orderby", "lazy"}, fn: (*Server).readGroup},
+	"export_data":   {multi: true, params: []string{"fields_to_export"}, fn: (*Server).exportData},
+	"name_search":   {params: []string{"name", "domain", "operator", "limit"}, fn: (*Server).nameSearch},
+	"name_get":      {multi: true, fn: (*Server).nameGet},
+	"load":          {params: []string{"fields", "data"}, fn: (*Server).loadMethod},
+	"parse_preview": {multi: true, params: []string{"options"}, fn: (*Server).parsePreview},
+	"execute_import": {
+		multi:  true,
+		params: []string{"fields", "columns", "options", "dryrun"},
+		fn:     (*Server).executeImport,
+	},
+	"formatted_read_group": {
+		params: []string{"domain", "groupby", "aggregates", "having", "offset", "limit", "order"},
+		fn:     (*Server).formattedReadGroup,
+	},
+}
+
+// call dispatches model.method with Python-style positional and keyword
+// arguments. ids is used for multi methods when the transport passes them
+// out of band (JSON-2); otherwise they are taken from args[0].
+func (s *Server) call(modelName, name string, ids []int, args []any, kwargs map[string]any) (any, error) {
+	meth, ok := methods[name]
+	if !ok {
+		return nil, &odooError{name: excValueError, message: fmt.Sprintf("The method '%s.%s' does not exist", modelName, name), method: name}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.model(modelName)
+	args = normalize(args).([]any)
+	if meth.multi && ids == nil {
+		if len(args) == 0 {
+			return nil, &odooError{name: excTypeError, message: fmt.Sprintf("%s() missing record ids", name), method: name}
+		}
+		var err error
+		if ids, err = toIDs(args[0]); err != nil {
+			return nil, err
+		}
+		args = args[1:]
+	}
+	if len(args) > len(meth.params) {
+		return nil, &odooError{name: excTypeError, message: fmt.Sprintf("%s() takes %d positional arguments but %d were given", name, len(meth.params), len(args)), method: name}
+	}
+
+	kw := make(map[string]any, len(meth.params))
+	for i, v := range args {
+		kw[meth.params[i]] = v
+	}
+	for k, v := range kwargs {
+		if k == "context" {
+			kw[k] = normalize(v)
+			continue
+		}
+		if !slices.Contains(meth.params, k) {
+			return nil, &odooError{name: excTypeError, message: fmt.Sprintf("%s() got an unexpected keyword argument '%s'", name, k), method: name}
+		}
+		if _, dup := kw[k]; dup {
+			return nil, &odooError{name: excTypeError, message: fmt.Sprintf("%s() got multiple values for argument '%s'", name, k), method: name}
+		}
+		kw[k] = normalize(v)
+	}
+
+	res, err := meth.fn(s, m, ids, kw)
+	if e, ok := err.(*odooError); ok && e.method == "" {
+		e.method = name
+	}
+	return res, err
+}
+
+func (s *Server) create(m *model, _ []int, kw map[string]any) (any, error) {
+	switch v := kw["vals_list"].(type) {
+	case map[string]any:
+		return m.create(v, s.now())
+	case []any:
+		ids := make([]any, 0, len(v))
+		for _, item := range v {
+			vals, ok := item.(map[string]any)
+			if !ok {
+				return nil, newError(excTypeError, "create() expects a dict or a list of dicts")
+			}
+			id, err := m.create(vals, s.now())
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	}
+	return nil, newError(excTypeError, "create() missing required argument 'vals_list'")
+}
+
+func (s *Server) readMethod(m *model, ids []int, kw map[string]any) (any, error) {
+	recs, err := m.browse(ids)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := toStrings(kw["fields"])
+	if err != nil {
+		return nil, err
+	}
+	return s.read(m, recs, fields)
+}
+
+// query runs the domain/offset/limit/order arguments shared by search,
+// search_read and search_count.
+func (s *Server) query(m *model, kw map[string]any) ([]map[string]any, error) {
+	domain, ok := kw["domain"].([]any)
+	if !ok && kw["domain"] != nil {
+		return nil, newError(excValueError, "Invalid domain: %v", kw["domain"])
+	}
+	recs, err := m.filter(m.activeDomain(domain, kw))
+	if err != nil {
+		return nil, err
+	}
+	if order, _ := kw["order"].(string); order != "" {
+		if err := m.sortRecords(recs, order); err != nil {
+			return nil, err
+		}
+	}
+	if offset, _ := kw["offset"].(int); offset > 0 {
+		recs = recs[min(offset, len(recs)):]
+	}
+	if limit, _ := kw["limit"].(int); limit > 0 && limit < len(recs) {
+		recs = recs[:limit]
+	}
+	return recs, nil
+}
+
+func (s *Server) search(m *model, _ []int, kw map[string]any) (any, error) {
+	recs, err := s.query(m, kw)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]any, 0, len(recs))
+	for _, rec := range recs {
+		ids = append(ids, rec["id"])
+	}
+	return ids, nil
+}
+
+func (s *Server) searchRead(m *model, _ []int, kw map[string]any) (any, error) {
+	recs, err := s.query(m, kw)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := toStrings(kw["fields"])
+	if err != nil {
+		return nil, err
+	}
+	return s.read(m, recs, fields)
+}
+
+func (s *Server) searchCount(m *model, _ []int, kw map[string]any) (any, error) {
+	limit := kw["limit"]
+	delete(kw, "limit")
+	recs, err := s.query(m, kw)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := limit.(int); n > 0 && n < len(recs) {
+		return n, nil
+	}
+	return len(recs), nil
+}
+
+func (s *Server) writeMethod(m *model, ids []int, kw map[string]any) (any, error) {
+	vals, ok := kw["vals"].(map[string]any)
+	if !ok {
+		return nil, newError(excTypeError, "write() missing required argument 'vals'")
+	}
+	if err := m.write(ids, vals, s.now()); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func (s *Server) unlinkMethod(m *model, ids []int, _ map[string]any) (any, error) {
+	if err := m.unlink(ids); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func (s *Server) fieldsGet(m *model, _ []int, kw map[string]any) (any, error) {
+	allfields, err := toStrings(kw["allfields"])
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := toStrings(kw["attributes"])
+	if err != nil {
+		return nil, err
+	}
+	if len(allfields) == 0 {
+		allfields = slices.Sorted(maps.Keys(m.fields))
+	}
+	out := map[string]any{}
+	for _, name := range allfields {
+		f, ok := m.fields[name]
+		if !ok {
+			continue
+		}
+		desc := f.describe(name)
+		if len(attributes) > 0 {
+			for k := range desc {
+				if !slices.Contains(attributes, k) {
+					delete(desc, k)
+				}
+			}
+		}
+		out[name] = desc
+	}
+	return out, nil
+}
+
+// describe renders the field as a fields_get entry.
+func (f Field) describe(name string) map[string]any {
+	label := f.String
+	if label == "" {
+		label = name
+	}
+	desc := map[string]any{
+		"type":     f.Type,
+		"string":   label,
+		"required": f.Required,
+		"readonly": f.Readonly,
+		"store":    name != "display_name",
+	}
+	if f.Relation != "" {
+		desc["relation"] = f.Relation
+	}
+	if f.Type == "selection" {
+		sel := make([]any, 0, len(f.Selection))
+		for _, pair := range f.Selection {
+			sel = append(sel, []any{pair[0], pair[1]})
+		}
+		desc["selection"] = sel
+	}
+	return desc
+}
+
+// toIDs converts an id or list of ids argument.
+func toIDs(v any) ([]int, error) {
+	switch v := v.(type) {
+	case int:
+		return []int{v}, nil
+	case []any:
+		ids := make([]int, 0, len(v))
+		for _, x := range v {
+			id, ok := x.(int)
+			if !ok {
+				return nil, newError(excTypeError, "Invalid record id %v", x)
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	case nil:
+		return []int{}, nil
+	}
+	return nil, newError(excTypeError, "Invalid record ids %v", v)
+}
+
+// toStrings converts a list of names argument; false and None mean empty.
+func toStrings(v any) ([]string, error) {
+	switch v := v.(type) {
+	case nil, bool:
+		return nil, nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, x := range v {
+			s, ok := x.(string)
+			if !ok {
+				return nil, newError(excTypeError, "Expected a list of strings, got %v", v)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	}
+	return nil, newError(excTypeError, "Expected a list of strings, got %v", v)
+}
+
+func (s *Server) nameSearch(m *model, _ []int, kw map[string]any) (any, error) {
+	domain, ok := kw["domain"].([]any)
+	if !ok && kw["domain"] != nil {
+		return nil, newError(excValueError, "Invalid domain: %v", kw["domain"])
+	}
+	if name, _ := kw["name"].(string); name != "" {
+		operator, _ := kw["operator"].(string)
+		if operator == "" {
+			operator = "ilike"
+		}
+		domain = append(slices.Clone(domain), []any{"name", operator, name})
+	}
+	recs, err := m.filter(m.activeDomain(domain, kw))
+	if err != nil {
+		return nil, err
+	}
+	limit := 100
+	if n, ok := kw["limit"].(int); ok && n > 0 {
+		limit = n
+	}
+	out := make([]any, 0, min(limit, len(recs)))
+	for _, rec := range recs[:min(limit, len(recs))] {
+		out = append(out, []any{rec["id"], m.displayName(rec)})
+	}
+	return out, nil
+}
+
+// nameGet implements name_get, which Odoo 17 replaced with the display_name
+// field.
+func (s *Server) nameGet(m *model, ids []int, _ map[string]any) (any, error) {
+	if s.major() >= 17 {
+		return nil, newError(excValueError, "The method '%s.name_get' does not exist", m.name)
+	}
+	recs, err := m.browse(ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]any, 0, len(recs))
+	for _, rec := range recs {
+		out = append(out, []any{rec["id"], m.displayName(rec)})
+	}
+	return out, nil
+}
@@ -0,0 +1,255 @@
+// Package odoorpctest provides an in-memory fake Odoo server for testing code
+// built on the odoojrpc, odooxmlrpc and odoojson clients.
+//
+// The server speaks the JSON-RPC (/jsonrpc), XML-RPC (/xmlrpc/2/common and
+// /xmlrpc/2/object) and JSON-2 (/json/2/<model>/<method>) protocols against a
+// shared record store, so the same scenario can be run over every transport:
+//
+//	srv := odoorpctest.NewServer()
+//	defer srv.Close()
+//	srv.Seed("res.partner", map[string]any{"name": "Alice"})
+//
+//	o := odoojrpc.NewOdoo().
+//		WithHostname(srv.Hostname()).WithPort(srv.Port()).
+//		WithDatabase(srv.Database).
+//		WithUsername(srv.Username).WithPassword(srv.Password)
+//
+// The store implements create, read, search, search_read, search_count,
+// write, unlink, fields_get, export_data, load, name_search, name_get,
+// read_group and formatted_read_group, including domain evaluation with the &,
+// | and ! operators and the usual comparison operators. CSV imports through
+// the parse_preview and execute_import methods of base_import.import are
+// supported as well, and external ids live in the predefined ir.model.data
+// model. Records of models with an active field are created active, and
+// archived ones are hidden from searches, as with Odoo's active_test, unless
+// the domain mentions active or the context sets active_test to false.
+// Dotted field paths and the hierarchy operators are not supported.
+// Like a real server of that Version, name_get is only served before 17.0
+// and formatted_read_group from 19.0.
+package odoorpctest
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a fake Odoo server backed by an in-memory record store.
+//
+// The credential fields may be changed after NewServer returns but must not be
+// modified while requests are in flight.
+type Server struct {
+	*httptest.Server
+
+	// Database is the only database the server accepts.
+	Database string
+	// Username and Password are accepted by the login and authenticate
+	// methods of the common service.
+	Username string
+	Password string
+	// APIKey is accepted as a Bearer token by the JSON-2 API and in place
+	// of Password by the object service.
+	APIKey string
+	// UID is the user id returned on successful login.
+	UID int
+	// Version is reported by the version method of the common service.
+	Version string
+
+	mu     sync.Mutex
+	models map[string]*model
+	clock  func() time.Time
+}
+
+// NewServer starts and returns a new fake Odoo server. The caller should call
+// Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Database: "odoo",
+		Username: "admin",
+		Password: "admin",
+		APIKey:   "odoorpctest-api-key",
+		UID:      2,
+		Version:  "18.0",
+		models:   make(map[string]*model),
+		clock:    time.Now,
+	}
+	s.models["ir.model.data"] = newModel("ir.model.data", irModelDataFields)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jsonrpc", s.handleJSONRPC)
+	mux.HandleFunc("POST /jsonrpc/", s.handleJSONRPC)
+	mux.HandleFunc("POST /xmlrpc/2/{service}", s.handleXMLRPC)
+	mux.HandleFunc("POST /json/2/{model}/{method}", s.handleJSON2)
+	mux.HandleFunc("POST /web/version", s.handleWebVersion)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Hostname returns the host the server listens on.
+func (s *Server) Hostname() string {
+	host, _, _ := net.SplitHostPort(s.Listener.Addr().String())
+	return host
+}
+
+// Port returns the port the server listens on.
+func (s *Server) Port() int {
+	_, port, _ := net.SplitHostPort(s.Listener.Addr().String())
+	n, _ := strconv.Atoi(port)
+	return n
+}
+
+// SetClock replaces the function used to stamp create_date and write_date.
+// Like Odoo, the server keeps the microseconds of the stamps, compares and
+// sorts on them, and reads them in whole seconds.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = now
+}
+
+// now returns the current server time. Callers must hold s.mu.
+func (s *Server) now() time.Time {
+	return s.clock()
+}
+
+// DefineModel declares a model and its fields. Writing to fields that were
+// not declared fails, as on a real server. Models that are not declared are
+// created on first use and learn their fields from the values written.
+func (s *Server) DefineModel(name string, fields map[string]Field) {
+	if fields == nil {
+		fields = map[string]Field{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models[name] = newModel(name, fields)
+}
+
+// Seed inserts records directly into the store and returns their ids.
+func (s *Server) Seed(modelName string, records ...map[string]any) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.model(modelName)
+	ids := make([]int, 0, len(records))
+	for _, vals := range records {
+		id, err := m.create(vals, s.now())
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Records returns a copy of every record of a model, ordered by id, with
+// values in their stored form (many2one fields hold the bare id).
+func (s *Server) Records(modelName string) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.models[modelName]
+	if !ok {
+		return nil
+	}
+	out := make([]map[string]any, 0, len(m.records))
+	for _, id := range m.ids() {
+		rec := make(map[string]any, len(m.records[id]))
+		for k, v := range m.records[id] {
+			rec[k] = v
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+// model returns the named model, creating it if needed. Callers must hold
+// s.mu.
+func (s *Server) model(name string) *model {
+	m, ok := s.models[name]
+	if !ok {
+		m = newModel(name, nil)
+		s.models[name] = m
+	}
+	return m
+}
+
+// major returns the major number of Version, or 0 if it is not numeric.
+func (s *Server) major() int {
+	major, _, _ := strings.Cut(strings.TrimPrefix(s.Version, "saas~"), ".")
+	n, _ := strconv.Atoi(major)
+	return n
+}
+
+// checkCredentials validates the database, uid and password sent with an
+// object service call.
+func (s *Server) checkCredentials(db string, uid int, password string) error {
+	if db != s.Database || uid != s.UID || (password != s.Password && password != s.APIKey) {
+		return newError(excAccessDenied, "Access Denied")
+	}
+	return nil
+}
+
+// common dispatches a call to the common service.
+func (s *Server) common(method string, args []any) (any, error) {
+	args = normalize(args).([]any)
+	str := func(i int) string {
+		if i < len(args) {
+			v, _ := args[i].(string)
+			return v
+		}
+		return ""
+	}
+	switch method {
+	case "login", "authenticate":
+		if str(0) == s.Database && str(1) == s.Username && (str(2) == s.Password || str(2) == s.APIKey) {
+			return s.UID, nil
+		}
+		return false, nil
+	case "version":
+		return map[string]any{
+			"server_version":   s.Version,
+			"server_serie":     s.Version,
+			"protocol_version": 1,
+		}, nil
+	}
+	return nil, &odooError{name: excValueError, message: "Unknown method " + method, method: method}
+}
+
+// object dispatches a call to the object service: execute(db, uid, password,
+// model, method, *args) or execute_kw(db, uid, password, model, method, args,
+// kwargs).
+func (s *Server) object(method string, args []any) (any, error) {
+	args = normalize(args).([]any)
+	if method != "execute" && method != "execute_kw" {
+		return nil, &odooError{name: excValueError, message: "Unknown method " + method, method: method}
+	}
+	if len(args) < 5 {
+		return nil, &odooError{name: excTypeError, message: method + "() missing required arguments", method: method}
+	}
+	db, _ := args[0].(string)
+	uid, _ := args[1].(int)
+	password, _ := args[2].(string)
+	if err := s.checkCredentials(db, uid, password); err != nil {
+		return nil, err
+	}
+	modelName, _ := args[3].(string)
+	name, _ := args[4].(string)
+
+	if method == "execute" {
+		return s.call(modelName, name, nil, args[5:], nil)
+	}
+	var callArgs []any
+	var kwargs map[string]any
+	if len(args) > 5 {
+		var ok bool
+		if callArgs, ok = args[5].([]any); !ok {
+			return nil, &odooError{name: excTypeError, message: "execute_kw() args must be a list", method: name}
+		}
+	}
+	if len(args) > 6 {
+		kwargs, _ = args[6].(map[string]any)
+	}
+	return s.call(modelName, name, nil, callArgs, kwargs)
+}
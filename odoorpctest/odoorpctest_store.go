@@ -0,0 +1,511 @@
+package odoorpctest
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// datetimeFormat is the server-side string format Odoo uses for datetimes.
+const datetimeFormat = "2006-01-02 15:04:05"
+
+// stampFormat is the format of create_date and write_date in the store: like
+// PostgreSQL, it keeps the microseconds that read drops.
+const stampFormat = "2006-01-02 15:04:05.999999"
+
+// Field describes a model field as reported by fields_get.
+type Field struct {
+	// Type is the Odoo field type: char, text, html, integer, float,
+	// monetary, boolean, date, datetime, selection, binary, many2one,
+	// one2many or many2many.
+	Type string
+	// String is the field label. It defaults to the field name.
+	String string
+	// Relation is the comodel name of relational fields.
+	Relation string
+	// Required rejects creates that do not provide a value.
+	Required bool
+	// Readonly is reported by fields_get but not enforced.
+	Readonly bool
+	// Selection lists the (value, label) pairs of selection fields.
+	Selection [][2]string
+}
+
+// magicFields are present on every model.
+var magicFields = map[string]Field{
+	"id":           {Type: "integer", String: "ID", Readonly: true},
+	"display_name": {Type: "char", String: "Display Name", Readonly: true},
+	"create_date":  {Type: "datetime", String: "Created on", Readonly: true},
+	"write_date":   {Type: "datetime", String: "Last Updated on", Readonly: true},
+}
+
+// irModelDataFields are the fields of ir.model.data, which holds the
+// external ids of the records.
+var irModelDataFields = map[string]Field{
+	"module":   {Type: "char", String: "Module", Required: true},
+	"name":     {Type: "char", String: "External Identifier", Required: true},
+	"model":    {Type: "char", String: "Model Name", Required: true},
+	"res_id":   {Type: "many2one_reference", String: "Record ID"},
+	"noupdate": {Type: "boolean", String: "Non Updatable"},
+}
+
+// model is the in-memory table of a single Odoo model.
+type model struct {
+	name    string
+	fields  map[string]Field
+	strict  bool
+	records map[int]map[string]any
+	nextID  int
+}
+
+func newModel(name string, fields map[string]Field) *model {
+	m := &model{
+		name:    name,
+		fields:  maps.Clone(magicFields),
+		strict:  fields != nil,
+		records: make(map[int]map[string]any),
+		nextID:  1,
+	}
+	for k, f := range fields {
+		m.fields[k] = f
+	}
+	return m
+}
+
+// field returns the definition of name. Models created without an explicit
+// field list learn fields from the values written to them.
+func (m *model) field(name string, value any) (Field, error) {
+	if f, ok := m.fields[name]; ok {
+		return f, nil
+	}
+	if m.strict {
+		return Field{}, newError(excValueError, "Invalid field %q on model %q", name, m.name)
+	}
+	f := Field{Type: inferType(value)}
+	m.fields[name] = f
+	return f, nil
+}
+
+// inferType guesses the field type of a value.
+func inferType(v any) string {
+	switch v := v.(type) {
+	case bool:
+		return "boolean"
+	case int:
+		return "integer"
+	case float64:
+		return "float"
+	case []any:
+		return "many2many"
+	case string:
+		if _, err := time.Parse(datetimeFormat, v); err == nil {
+			return "datetime"
+		}
+		if _, err := time.Parse(time.DateOnly, v); err == nil {
+			return "date"
+		}
+	}
+	return "char"
+}
+
+// ids returns the ids of all records in ascending order.
+func (m *model) ids() []int {
+	ids := slices.Collect(maps.Keys(m.records))
+	slices.Sort(ids)
+	return ids
+}
+
+// browse returns the records for ids or a MissingError if any is absent.
+func (m *model) browse(ids []int) ([]map[string]any, error) {
+	recs := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		rec, ok := m.records[id]
+		if !ok {
+			return nil, newError(excMissingError,
+				"Record does not exist or has been deleted.\n(Record: %s(%d,), User: 2)", m.name, id)
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// create inserts a record and returns its id.
+func (m *model) create(vals map[string]any, now time.Time) (int, error) {
+	rec := map[string]any{}
+	for k, v := range vals {
+		if _, ok := magicFields[k]; ok {
+			continue
+		}
+		f, err := m.field(k, normalize(v))
+		if err != nil {
+			return 0, err
+		}
+		if rec[k], err = convert(f, v, nil); err != nil {
+			return 0, err
+		}
+	}
+	if f, ok := m.fields["active"]; ok && f.Type == "boolean" && rec["active"] == nil {
+		// Records are created active.
+		rec["active"] = true
+	}
+	for k, f := range m.fields {
+		if f.Required && isFalsy(rec[k]) {
+			return 0, newError(excValidationError, "The field %q on model %q is required.", k, m.name)
+		}
+	}
+	id := m.nextID
+	m.nextID++
+	ts := now.UTC().Format(stampFormat)
+	rec["id"] = id
+	rec["create_date"] = ts
+	rec["write_date"] = ts
+	m.records[id] = rec
+	return id, nil
+}
+
+// write updates the records with vals.
+func (m *model) write(ids []int, vals map[string]any, now time.Time) error {
+	recs, err := m.browse(ids)
+	if err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		for k, v := range vals {
+			if _, ok := magicFields[k]; ok {
+				continue
+			}
+			f, err := m.field(k, normalize(v))
+			if err != nil {
+				return err
+			}
+			if rec[k], err = convert(f, v, rec[k]); err != nil {
+				return err
+			}
+		}
+		rec["write_date"] = now.UTC().Format(stampFormat)
+	}
+	return nil
+}
+
+// unlink deletes the records.
+func (m *model) unlink(ids []int) error {
+	if _, err := m.browse(ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		delete(m.records, id)
+	}
+	return nil
+}
+
+// displayName returns the display_name of a record.
+func (m *model) displayName(rec map[string]any) string {
+	if name, ok := rec["name"].(string); ok && name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s,%d", m.name, rec["id"])
+}
+
+// sortRecords orders recs by an Odoo order specification such as
+// "name asc, id desc". Records are always finally ordered by id.
+func (m *model) sortRecords(recs []map[string]any, order string) error {
+	type key struct {
+		field string
+		desc  bool
+	}
+	var keys []key
+	for _, part := range strings.Split(order, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 {
+			continue
+		}
+		if _, ok := m.fields[words[0]]; !ok {
+			return newError(excValueError, "Invalid field %q in order %q", words[0], order)
+		}
+		k := key{field: words[0]}
+		if len(words) > 1 {
+			switch strings.ToLower(words[1]) {
+			case "asc":
+			case "desc":
+				k.desc = true
+			default:
+				return newError(excValueError, "Invalid order %q", order)
+			}
+		}
+		keys = append(keys, k)
+	}
+	keys = append(keys, key{field: "id"})
+
+	sort.SliceStable(recs, func(i, j int) bool {
+		for _, k := range keys {
+			c := compare(recs[i][k.field], recs[j][k.field])
+			if c == 0 {
+				continue
+			}
+			if k.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+	return nil
+}
+
+// read renders recs for the wire, limited to fields when non-empty.
+func (s *Server) read(m *model, recs []map[string]any, fields []string) ([]any, error) {
+	if len(fields) == 0 {
+		fields = slices.Sorted(maps.Keys(m.fields))
+	}
+	for _, name := range fields {
+		if _, ok := m.fields[name]; !ok {
+			return nil, newError(excValueError, "Invalid field %q on model %q", name, m.name)
+		}
+	}
+	out := make([]any, 0, len(recs))
+	for _, rec := range recs {
+		row := map[string]any{"id": rec["id"]}
+		for _, name := range fields {
+			row[name] = s.readValue(m, rec, name)
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// readValue renders a single field value the way Odoo's read does: empty
+// values become false, many2one values become [id, display_name] pairs and
+// datetimes lose their microseconds.
+func (s *Server) readValue(m *model, rec map[string]any, name string) any {
+	if name == "display_name" {
+		return m.displayName(rec)
+	}
+	v := rec[name]
+	f := m.fields[name]
+	switch f.Type {
+	case "many2one":
+		id, ok := v.(int)
+		if !ok {
+			return false
+		}
+		label := fmt.Sprintf("%s,%d", f.Relation, id)
+		if co, ok := s.models[f.Relation]; ok {
+			if corec, ok := co.records[id]; ok {
+				label = co.displayName(corec)
+			}
+		}
+		return []any{id, label}
+	case "one2many", "many2many":
+		if v == nil {
+			return []any{}
+		}
+		return v
+	case "integer", "float", "monetary":
+		if v == nil {
+			return 0
+		}
+		return v
+	case "datetime":
+		if str, ok := v.(string); ok && len(str) > len(datetimeFormat) {
+			return str[:len(datetimeFormat)]
+		}
+	}
+	if v == nil {
+		return false
+	}
+	return v
+}
+
+// normalize converts wire values into the canonical types used by the
+// store: integral numbers become int, lists become []any and maps become
+// map[string]any, regardless of the transport they arrived on.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int(v)
+		}
+		return v
+	case []int:
+		out := make([]any, len(v))
+		for i, x := range v {
+			out[i] = x
+		}
+		return out
+	case []string:
+		out := make([]any, len(v))
+		for i, x := range v {
+			out[i] = x
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, x := range v {
+			out[i] = normalize(x)
+		}
+		return out
+	case []map[string]any:
+		out := make([]any, len(v))
+		for i, x := range v {
+			out[i] = normalize(x)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, x := range v {
+			out[k] = normalize(x)
+		}
+		return out
+	}
+	return v
+}
+
+// convert coerces a written value to the storage type of f. old is the
+// current value, used to apply x2many commands.
+func convert(f Field, v any, old any) (any, error) {
+	v = normalize(v)
+	if b, ok := v.(bool); ok && !b && f.Type != "boolean" {
+		return nil, nil
+	}
+	switch f.Type {
+	case "float", "monetary":
+		if i, ok := v.(int); ok {
+			return float64(i), nil
+		}
+	case "many2one":
+		if pair, ok := v.([]any); ok && len(pair) > 0 {
+			v = pair[0]
+		}
+		if _, ok := v.(int); !ok && v != nil {
+			return nil, newError(excValueError, "Wrong value for many2one: %v", v)
+		}
+	case "one2many", "many2many":
+		return applyCommands(old, v)
+	}
+	return v, nil
+}
+
+// applyCommands applies a list of x2many commands, or a plain list of ids,
+// to the current id list. Only the link/unlink/clear/set commands are
+// supported; creating or updating related records inline is not.
+func applyCommands(old any, v any) (any, error) {
+	cmds, ok := v.([]any)
+	if !ok {
+		return nil, newError(excValueError, "Wrong value for x2many: %v", v)
+	}
+	current, _ := old.([]any)
+	ids := slices.Clone(current)
+	for _, c := range cmds {
+		if id, ok := c.(int); ok {
+			if !slices.Contains(ids, any(id)) {
+				ids = append(ids, id)
+			}
+			continue
+		}
+		cmd, ok := c.([]any)
+		if !ok || len(cmd) == 0 {
+			return nil, newError(excValueError, "Invalid x2many command: %v", c)
+		}
+		op, _ := cmd[0].(int)
+		arg := func(i int) any {
+			if i < len(cmd) {
+				return cmd[i]
+			}
+			return nil
+		}
+		switch op {
+		case 3: // unlink
+			ids = slices.DeleteFunc(ids, func(x any) bool { return x == arg(1) })
+		case 4: // link
+			if !slices.Contains(ids, arg(1)) {
+				ids = append(ids, arg(1))
+			}
+		case 5: // clear
+			ids = nil
+		case 6: // set
+			list, _ := arg(2).([]any)
+			ids = slices.Clone(list)
+		default:
+			return nil, newError(excValueError, "Unsupported x2many command %d", op)
+		}
+	}
+	if ids == nil {
+		ids = []any{}
+	}
+	return ids, nil
+}
+
+// isFalsy reports whether v is empty in the Python sense.
+func isFalsy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case int:
+		return v == 0
+	case float64:
+		return v == 0
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// compare orders two stored values. Empty values sort last, like NULLs in
+// PostgreSQL ascending order.
+func compare(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if x, ok := a.(bool); ok {
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,227 @@
+package odoorpctest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoojrpc"
+	"github.com/ppreeper/odoorpc/odoojson"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+	"github.com/ppreeper/odoorpc/odooxmlrpc"
+)
+
+// transports returns a logged-in client for every transport, all pointed at
+// srv.
+func transports(t *testing.T, srv *odoorpctest.Server) map[string]odoorpc.Odoo {
+	t.Helper()
+	clients := map[string]odoorpc.Odoo{
+		"jsonrpc": odoojrpc.NewOdoo().
+			WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).
+			WithUsername(srv.Username).WithPassword(srv.Password),
+		"xmlrpc": odooxmlrpc.NewOdoo().
+			WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).
+			WithUsername(srv.Username).WithPassword(srv.Password),
+		"json2": odoojson.NewOdoo().
+			WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).
+			WithAPIKey(srv.APIKey),
+	}
+	for name, c := range clients {
+		if err := c.Login(context.Background()); err != nil {
+			t.Fatalf("%s login: %v", name, err)
+		}
+	}
+	return clients
+}
+
+func TestCRUDAcrossTransports(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+
+	for name, o := range transports(t, srv) {
+		t.Run(name, func(t *testing.T) {
+			model := "x." + name
+			id, err := o.Create(ctx, model, map[string]any{"name": "Alice", "email": "alice@example.com"})
+			if err != nil {
+				t.Fatalf("create: %v", err)
+			}
+			if id < 1 {
+				t.Fatalf("create returned id %d", id)
+			}
+			if _, err := o.Create(ctx, model, map[string]any{"name": "Bob", "email": "bob@example.com"}); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+
+			count, err := o.Count(ctx, model, []any{[]any{"email", "ilike", "EXAMPLE.COM"}})
+			if err != nil || count != 2 {
+				t.Fatalf("count: got %d, %v; want 2", count, err)
+			}
+
+			ids, err := o.Search(ctx, model, []any{[]any{"name", "=", "Bob"}})
+			if err != nil || len(ids) != 1 || ids[0] != id+1 {
+				t.Fatalf("search: got %v, %v", ids, err)
+			}
+
+			gotID, err := o.GetID(ctx, model, []any{[]any{"name", "=", "Alice"}})
+			if err != nil || gotID != id {
+				t.Fatalf("get_id: got %d, %v; want %d", gotID, err, id)
+			}
+
+			ok, err := o.Write(ctx, model, id, map[string]any{"name": "Alicia"})
+			if err != nil || !ok {
+				t.Fatalf("write: got %v, %v", ok, err)
+			}
+
+			recs, err := o.Read(ctx, model, []int{id}, "name", "email")
+			if err != nil || len(recs) != 1 || recs[0]["name"] != "Alicia" {
+				t.Fatalf("read: got %v, %v", recs, err)
+			}
+
+			recs, err = o.SearchRead(ctx, model, 0, 10, []string{"name"},
+				[]any{"|", []any{"name", "=like", "Ali%"}, []any{"name", "=", "Nobody"}})
+			if err != nil || len(recs) != 1 || recs[0]["name"] != "Alicia" {
+				t.Fatalf("search_read: got %v, %v", recs, err)
+			}
+
+			fields, err := o.FieldsGet(ctx, model, []string{"name"}, "type")
+			if err != nil {
+				t.Fatalf("fields_get: %v", err)
+			}
+			desc, _ := fields["name"].(map[string]any)
+			if desc["type"] != "char" {
+				t.Fatalf("fields_get: got %v", fields)
+			}
+
+			ok, err = o.Unlink(ctx, model, []int{id})
+			if err != nil || !ok {
+				t.Fatalf("unlink: got %v, %v", ok, err)
+			}
+			if n := len(srv.Records(model)); n != 1 {
+				t.Fatalf("expected 1 record left, got %d", n)
+			}
+		})
+	}
+}
+
+func TestSeededRecordsVisibleToAllTransports(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name":      {Type: "char", Required: true},
+		"parent_id": {Type: "many2one", Relation: "res.partner"},
+		"active":    {Type: "boolean"},
+	})
+	ids, err := srv.Seed("res.partner",
+		map[string]any{"name": "Acme", "active": true},
+		map[string]any{"name": "Jane", "active": true},
+		map[string]any{"name": "Old", "active": false},
+	)
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "John", "parent_id": ids[0], "active": true}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	for name, o := range transports(t, srv) {
+		t.Run(name, func(t *testing.T) {
+			recs, err := o.SearchRead(ctx, "res.partner", 0, 0, []string{"name", "parent_id"},
+				[]any{[]any{"active", "=", true}, []any{"parent_id", "!=", false}})
+			if err != nil {
+				t.Fatalf("search_read: %v", err)
+			}
+			if len(recs) != 1 {
+				t.Fatalf("expected 1 record, got %v", recs)
+			}
+			parent, ok := recs[0]["parent_id"].([]any)
+			if !ok || len(parent) != 2 || parent[1] != "Acme" {
+				t.Errorf("parent_id: got %#v, want [id, \"Acme\"]", recs[0]["parent_id"])
+			}
+
+			count, err := o.Count(ctx, "res.partner", []any{"!", []any{"name", "in", []any{"Acme", "Old"}}})
+			if err != nil || count != 2 {
+				t.Errorf("count: got %d, %v; want 2", count, err)
+			}
+
+			// Archived records are hidden unless active_test is false.
+			if count, err := o.Count(ctx, "res.partner"); err != nil || count != 3 {
+				t.Errorf("count of active records: got %d, %v; want 3", count, err)
+			}
+			all, err := o.CallMethod(ctx, "res.partner", "search", nil, map[string]any{
+				"domain":  []any{},
+				"context": map[string]any{"active_test": false},
+			})
+			if list, ok := all.([]any); err != nil || !ok || len(list) != 4 {
+				t.Errorf("search without active_test: got %v, %v; want 4 ids", all, err)
+			}
+
+			if _, err := o.Create(ctx, "res.partner", map[string]any{"email": "x@example.com"}); err == nil {
+				t.Error("expected error writing an undeclared field")
+			}
+		})
+	}
+}
+
+func TestServerErrorsReachClients(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name": {Type: "char", Required: true},
+	})
+
+	for name, o := range transports(t, srv) {
+		t.Run(name, func(t *testing.T) {
+			_, err := o.Create(ctx, "res.partner", map[string]any{})
+			if err == nil || !strings.Contains(err.Error(), "required") {
+				t.Errorf("create: expected required-field error, got %v", err)
+			}
+			_, err = o.Read(ctx, "res.partner", []int{999}, "name")
+			if err == nil || !strings.Contains(err.Error(), "does not exist") {
+				t.Errorf("read: expected missing record error, got %v", err)
+			}
+		})
+	}
+
+	// The XML-RPC client decodes the fault into a typed error.
+	o := transports(t, srv)["xmlrpc"]
+	_, err := o.Read(ctx, "res.partner", []int{999}, "name")
+	var oe *odooxmlrpc.Error
+	if !errors.As(err, &oe) || oe.Name != "odoo.exceptions.UserError" {
+		t.Errorf("expected *odooxmlrpc.Error, got %T: %v", err, err)
+	}
+}
+
+func TestBadCredentialsRejected(t *testing.T) {
+	t.Parallel()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+
+	jrpc := odoojrpc.NewOdoo().
+		WithHostname(srv.Hostname()).WithPort(srv.Port()).
+		WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword("wrong")
+	if err := jrpc.Login(context.Background()); err == nil {
+		t.Error("jsonrpc: expected login failure")
+	}
+
+	json2 := odoojson.NewOdoo().
+		WithHostname(srv.Hostname()).WithPort(srv.Port()).
+		WithDatabase(srv.Database).WithAPIKey("wrong")
+	_, err := json2.Search(context.Background(), "res.partner")
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("json2: expected 401, got %v", err)
+	}
+}
@@ -3,8 +3,9 @@ package odooxmlrpc
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"reflect"
 
+	"github.com/ppreeper/odoorpc"
 	"github.com/ppreeper/odoorpc/xmlrpc"
 	"github.com/ppreeper/odoosearchdomain"
 )
@@ -12,36 +13,151 @@ import (
 // Login
 // Login to the server and return the uid
 func (o *OdooXML) Login(ctx context.Context) (err error) {
+	if err := o.connect(); err != nil {
+		return err
+	}
+
+	// Logging in
+	var uid int
+	if err := o.invoke(ctx, odoorpc.CallInfo{
+		Transport: odoorpc.TransportXMLRPC,
+		Service:   "common",
+		Method:    "authenticate",
+		Args:      []any{o.database, o.username, o.password, map[string]any{}},
+	}, &uid); err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	if uid == 0 {
+		return fmt.Errorf("login failed: invalid credentials")
+	}
+	mu := o.lock()
+	mu.Lock()
+	o.uid = uid
+	mu.Unlock()
+	return nil
+}
+
+// connect builds the URL and the rpc clients if needed. The rpc clients are
+// created once and reused, with their connection pool, by later logins.
+func (o *OdooXML) connect() error {
+	mu := o.lock()
+	mu.Lock()
+	defer mu.Unlock()
 	if o.url == "" {
-		if err = o.genURL(); err != nil {
+		if err := o.genURL(); err != nil {
 			return fmt.Errorf("genURL failed in login: %w", err)
 		}
+		o.closeClients()
 	}
-	// rpc clients — use a transport with the configured timeout so hung
-	// servers cannot block indefinitely.
-	transport := &http.Transport{ResponseHeaderTimeout: o.timeout}
-	o.common, err = xmlrpc.NewClient(o.url+"common", transport)
-	if err != nil {
-		return fmt.Errorf("failed to create common client: %w", err)
+	if o.common == nil || o.models == nil {
+		client, err := o.httpClient()
+		if err != nil {
+			return fmt.Errorf("failed to create http client: %w", err)
+		}
+		common, err := xmlrpc.NewClientWithHTTPClient(o.url+"common", client)
+		if err != nil {
+			return fmt.Errorf("failed to create common client: %w", err)
+		}
+		models, err := xmlrpc.NewClientWithHTTPClient(o.url+"object", client)
+		if err != nil {
+			return fmt.Errorf("failed to create models client: %w", err)
+		}
+		o.common, o.models = common, models
+	}
+	return nil
+}
+
+// closeClients closes and forgets the rpc clients of a previous Login, if
+// any.
+func (o *OdooXML) closeClients() {
+	if o.common != nil {
+		o.common.Close()
+	}
+	if o.models != nil {
+		o.models.Close()
+	}
+	o.common, o.models = nil, nil
+}
+
+// execute invokes execute_kw on the object endpoint for model.method and
+// decodes the result into reply. Server faults are returned as *Error.
+func (o *OdooXML) execute(ctx context.Context, reply any, model, method string, args []any, kwargs map[string]any) error {
+	return o.invoke(ctx, odoorpc.CallInfo{
+		Transport: odoorpc.TransportXMLRPC,
+		Service:   "object",
+		Model:     model,
+		Method:    method,
+		Args:      args,
+		Kwargs:    kwargs,
+	}, reply)
+}
+
+// invoke runs info through the interceptor chain. The final invoker sends the
+// request and decodes the response into reply; a result returned by an
+// interceptor instead is assigned to reply.
+func (o *OdooXML) invoke(ctx context.Context, info odoorpc.CallInfo, reply any) error {
+	final := func(ctx context.Context, info odoorpc.CallInfo) (any, error) {
+		mu := o.lock()
+		mu.RLock()
+		common, models, uid := o.common, o.models, o.uid
+		mu.RUnlock()
+		client, method, params := common, info.Method, info.Args
+		if info.Service == "object" {
+			args := info.Args
+			if args == nil {
+				args = []any{}
+			}
+			client, method = models, "execute_kw"
+			params = []any{o.database, uid, o.password, info.Model, info.Method, args}
+			if info.Kwargs != nil {
+				params = append(params, info.Kwargs)
+			}
+		}
+		if client == nil {
+			return nil, fmt.Errorf("not connected: call Login first")
+		}
+		if err := client.CallContext(ctx, method, params, reply); err != nil {
+			return nil, wrapFault(err)
+		}
+		return reflect.ValueOf(reply).Elem().Interface(), nil
+	}
+	var res any
+	var err error
+	if info.Service == "common" {
+		res, err = odoorpc.Invoke(ctx, info, final, o.interceptors...)
+	} else {
+		res, err = o.relogin.Invoke(ctx, o.Login, info, final, o.interceptors...)
 	}
-	o.models, err = xmlrpc.NewClient(o.url+"object", transport)
 	if err != nil {
-		return fmt.Errorf("failed to create models client: %w", err)
+		return err
 	}
+	return assign(reply, res)
+}
 
-	// Logging in
-	if err := o.common.CallContext(ctx, "authenticate", []any{
-		o.database, o.username, o.password,
-		map[string]any{},
-	}, &o.uid); err != nil {
-		return fmt.Errorf("login failed: %w", err)
+// assign stores res in the value reply points to. Numeric results are
+// converted to the reply's numeric type.
+func assign(reply any, res any) error {
+	rv := reflect.ValueOf(reply).Elem()
+	if res == nil {
+		rv.SetZero()
+		return nil
 	}
-	if o.uid == 0 {
-		return fmt.Errorf("login failed: invalid credentials")
+	v := reflect.ValueOf(res)
+	switch {
+	case v.Type().AssignableTo(rv.Type()):
+		rv.Set(v)
+	case isNumeric(v.Kind()) && isNumeric(rv.Kind()):
+		rv.Set(v.Convert(rv.Type()))
+	default:
+		return fmt.Errorf("cannot assign result of type %T to %s", res, rv.Type())
 	}
 	return nil
 }
 
+func isNumeric(k reflect.Kind) bool {
+	return reflect.Int <= k && k <= reflect.Float64
+}
+
 // Create
 // Create a single record for the model and return its id
 // model: model name
@@ -53,18 +169,17 @@ func (o *OdooXML) Login(ctx context.Context) (err error) {
 //		"email": "zexample1@   example.com",
 //	}
 func (o *OdooXML) Create(ctx context.Context, model string, values map[string]any) (row int, err error) {
-	if err := o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, "create",
-		[]any{values},
-	}, &row); err != nil {
+	if err := o.execute(ctx, &row, model, "create", []any{values}, nil); err != nil {
 		return -1, fmt.Errorf("create failed: %w", err)
 	}
 	return row, nil
 }
 
 // Load
-// Create multiple records using a datamatrix and return their ids
+// Create or update multiple records using a datamatrix and return their ids
+// with the messages of the server. When the server rejects the data, nothing
+// is saved and the error is a *odoorpc.LoadError holding the row-level
+// messages
 // model: model name
 // header: list of field names
 // values: list of lists of field values
@@ -77,38 +192,18 @@ func (o *OdooXML) Create(ctx context.Context, model string, values map[string]an
 //	["ZExample1", "zexample1@example.com"],
 //	["ZExample2", "zexample1@example.com"],
 //	]
-func (o *OdooXML) Load(ctx context.Context, model string, header []string, values [][]any) (ids []int, err error) {
-	var results any
+func (o *OdooXML) Load(ctx context.Context, model string, header []string, values [][]any) (result odoorpc.LoadResult, err error) {
+	var res any
 	// Use execute_kw with the method args provided as a single positional
 	// argument (a list) containing header and values. This matches the
 	// execute_kw signature: execute_kw(db, uid, pwd, model, method, args, kwargs).
-	err = o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, "load",
-		[]any{header, values},
-	}, &results)
-	if err != nil {
-		return nil, fmt.Errorf("load failed: %w", err)
+	if err = o.execute(ctx, &res, model, "load", []any{header, values}, nil); err != nil {
+		return result, fmt.Errorf("load failed: %w", err)
 	}
-	switch restype := results.(type) {
-	case map[string]any:
-		idmap, ok := restype["ids"].([]any)
-		if !ok {
-			return nil, fmt.Errorf("load failed: ids not found in response")
-		}
-		for _, id := range idmap {
-			v, ok := id.(int64)
-			if !ok {
-				return nil, fmt.Errorf("load failed: unexpected id type %T in response", id)
-			}
-			ids = append(ids, int(v))
-		}
-	case int64:
-		ids = []int{int(restype)}
-	default:
-		return nil, fmt.Errorf("load failed: unexpected response type %T", results)
+	if result, err = odoorpc.ParseLoadResult(res); err != nil {
+		return result, fmt.Errorf("load failed: %w", err)
 	}
-	return ids, nil
+	return result, result.Err()
 }
 
 // Count record
@@ -122,11 +217,7 @@ func (o *OdooXML) Load(ctx context.Context, model string, header []string, value
 // domain = [[["name", "=", "ZExample1"]]]
 // limit = 1
 func (o *OdooXML) Count(ctx context.Context, model string, domains ...any) (count int, err error) {
-	if err := o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, "search_count",
-		[]any{odoosearchdomain.DomainList(domains...)},
-	}, &count); err != nil {
+	if err := o.execute(ctx, &count, model, "search_count", []any{odoosearchdomain.DomainList(domains...)}, nil); err != nil {
 		return -1, fmt.Errorf("count failed: %w", err)
 	}
 	return count, nil
@@ -140,11 +231,7 @@ func (o *OdooXML) Count(ctx context.Context, model string, domains ...any) (coun
 // attributes = ["string", "help", "type"]
 func (o *OdooXML) FieldsGet(ctx context.Context, model string, fields []string, fieldAttributes ...string) (recordFields map[string]any, err error) {
 	// Call fields_get using execute_kw and pass the method args as a list.
-	if err := o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, "fields_get",
-		[]any{fields, odoosearchdomain.DomainString(fieldAttributes...)},
-	}, &recordFields); err != nil {
+	if err := o.execute(ctx, &recordFields, model, "fields_get", []any{fields, odoosearchdomain.DomainString(fieldAttributes...)}, nil); err != nil {
 		return nil, fmt.Errorf("fields_get failed: %w", err)
 	}
 	return
@@ -158,12 +245,7 @@ func (o *OdooXML) FieldsGet(ctx context.Context, model string, fields []string,
 // domain = [[["name", "=", "ZExample1"]]]
 func (o *OdooXML) GetID(ctx context.Context, model string, domains ...any) (id int, err error) {
 	var ids []int
-	if err := o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, "search",
-		odoosearchdomain.DomainList(domains...),
-		map[string]any{"limit": 1},
-	}, &ids); err != nil {
+	if err := o.execute(ctx, &ids, model, "search", []any{odoosearchdomain.DomainList(domains...)}, map[string]any{"limit": 1}); err != nil {
 		return -1, fmt.Errorf("get_id failed: %w", err)
 	}
 	if len(ids) == 0 {
@@ -179,11 +261,7 @@ func (o *OdooXML) GetID(ctx context.Context, model string, domains ...any) (id i
 // Example:
 // domain = [[["name", "=", "ZExample1"]]]
 func (o *OdooXML) Search(ctx context.Context, model string, domains ...any) (ids []int, err error) {
-	if err := o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, "search",
-		[]any{odoosearchdomain.DomainList(domains...)},
-	}, &ids); err != nil {
+	if err := o.execute(ctx, &ids, model, "search", []any{odoosearchdomain.DomainList(domains...)}, nil); err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 	return ids, nil
@@ -198,11 +276,7 @@ func (o *OdooXML) Search(ctx context.Context, model string, domains ...any) (ids
 // ids = [1, 2, 3]
 // fields = ["name", "email"]
 func (o *OdooXML) Read(ctx context.Context, model string, ids []int, fields ...string) (records []map[string]any, err error) {
-	if err := o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, "read",
-		[]any{ids, odoosearchdomain.DomainString(fields...)},
-	}, &records); err != nil {
+	if err := o.execute(ctx, &records, model, "read", []any{ids, odoosearchdomain.DomainString(fields...)}, nil); err != nil {
 		return records, fmt.Errorf("read failed: %w", err)
 	}
 	return records, nil
@@ -227,11 +301,7 @@ func (o *OdooXML) SearchRead(ctx context.Context, model string, offset int, limi
 		"fields": odoosearchdomain.DomainString(fields...),
 	}
 
-	if err := o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, "search_read",
-		[]any{odoosearchdomain.DomainList(domains...), options},
-	}, &records); err != nil {
+	if err := o.execute(ctx, &records, model, "search_read", []any{odoosearchdomain.DomainList(domains...)}, options); err != nil {
 		return nil, fmt.Errorf("search_read failed: %w", err)
 	}
 	return records, nil
@@ -250,11 +320,7 @@ func (o *OdooXML) SearchRead(ctx context.Context, model string, offset int, limi
 //		"email": "zexample1_1@example.com",
 //	}
 func (o *OdooXML) Write(ctx context.Context, model string, recordID int, values map[string]any) (result bool, err error) {
-	if err := o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, "write",
-		[]any{[]int{recordID}, map[string]any{"vals": values}},
-	}, &result); err != nil {
+	if err := o.execute(ctx, &result, model, "write", []any{[]int{recordID}}, map[string]any{"vals": values}); err != nil {
 		return result, fmt.Errorf("write failed: %w", err)
 	}
 	return result, nil
@@ -267,11 +333,7 @@ func (o *OdooXML) Write(ctx context.Context, model string, recordID int, values
 // Example:
 // ids = [1, 2, 3]
 func (o *OdooXML) Unlink(ctx context.Context, model string, recordIDs []int) (result bool, err error) {
-	if err := o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, "unlink",
-		[]any{recordIDs},
-	}, &result); err != nil {
+	if err := o.execute(ctx, &result, model, "unlink", []any{recordIDs}, nil); err != nil {
 		return result, fmt.Errorf("unlink failed: %w", err)
 	}
 	return result, nil
@@ -289,10 +351,7 @@ func (o *OdooXML) Execute(ctx context.Context, model string, method string, args
 	// execute should call execute_kw for consistency with the XML-RPC
 	// transport's expectations. The args are provided as the single positional
 	// argument to execute_kw.
-	if err := o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, method, []any{args},
-	}, &result); err != nil {
+	if err := o.execute(ctx, &result, model, method, []any{args}, nil); err != nil {
 		return false, fmt.Errorf("execute failed: %w", err)
 	}
 	return result, nil
@@ -308,17 +367,207 @@ func (o *OdooXML) Execute(ctx context.Context, model string, method string, args
 // model = "res.partner"
 // method = "search_read"
 func (o *OdooXML) ExecuteKw(ctx context.Context, model string, method string, args []any, kwargs []map[string]any) (result bool, err error) {
-	var kw any
+	kw := map[string]any{}
 	if len(kwargs) > 0 && kwargs[0] != nil {
 		kw = kwargs[0]
-	} else {
-		kw = map[string]any{}
 	}
-	if err := o.models.CallContext(ctx, "execute_kw", []any{
-		o.database, o.uid, o.password,
-		model, method, args, kw,
-	}, &result); err != nil {
+	if err := o.execute(ctx, &result, model, method, args, kw); err != nil {
 		return false, fmt.Errorf("execute_kw failed: %w", err)
 	}
 	return result, nil
 }
+
+// ServerVersion returns the version of the server. It is queried once and
+// cached until the connection settings change.
+func (o *OdooXML) ServerVersion(ctx context.Context) (version odoorpc.Version, err error) {
+	mu := o.lock()
+	mu.RLock()
+	cached := o.version
+	mu.RUnlock()
+	if cached != nil {
+		return *cached, nil
+	}
+	if err := o.connect(); err != nil {
+		return version, err
+	}
+	var info map[string]any
+	if err := o.invoke(ctx, odoorpc.CallInfo{
+		Transport: odoorpc.TransportXMLRPC,
+		Service:   "common",
+		Method:    "version",
+	}, &info); err != nil {
+		return version, fmt.Errorf("version failed: %w", err)
+	}
+	if version, err = odoorpc.VersionFromInfo(info); err != nil {
+		return version, err
+	}
+	mu.Lock()
+	o.version = &version
+	mu.Unlock()
+	return version, nil
+}
+
+// ReadGroup
+// Group the records matching the domain and aggregate their values, using
+// formatted_read_group or read_group depending on the server version
+// model: model name
+// domain: list of search criteria
+// groupBy: list of groupby specifications
+// aggregates: list of aggregate specifications
+// Example:
+// groupBy = ["partner_id", "date_order:month"]
+// aggregates = ["amount_total:sum"]
+func (o *OdooXML) ReadGroup(ctx context.Context, model string, domain []any, groupBy []string, aggregates []string, opts odoorpc.ReadGroupOptions) (groups []odoorpc.Group, err error) {
+	version, err := o.ServerVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read_group failed: %w", err)
+	}
+	method := odoorpc.ReadGroupMethod(version)
+	var res any
+	if err := o.execute(ctx, &res, model, method, []any{}, odoorpc.ReadGroupKwargs(method, domain, groupBy, aggregates, opts)); err != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, err)
+	}
+	return odoorpc.ParseGroups(method, groupBy, aggregates, res)
+}
+
+// NameSearch
+// Return the records whose display name matches name, as (id, name) pairs
+// model: model name
+// name: text to match; an empty name matches all records
+// domain: list of search criteria restricting the records
+// operator: comparison operator, "ilike" when empty
+// limit: maximum number of results, 100 when 0
+// Example:
+// name = "acme"
+// domain = [["customer_rank", ">", 0]]
+func (o *OdooXML) NameSearch(ctx context.Context, model string, name string, domain []any, operator string, limit int) (records []odoorpc.Many2One, err error) {
+	if domain == nil {
+		domain = []any{}
+	}
+	if operator == "" {
+		operator = "ilike"
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	var res any
+	if err := o.execute(ctx, &res, model, "name_search", []any{name, domain, operator, limit}, nil); err != nil {
+		return nil, fmt.Errorf("name_search failed: %w", err)
+	}
+	if records, err = odoorpc.ParseMany2Ones(res); err != nil {
+		return nil, fmt.Errorf("name_search failed: %w", err)
+	}
+	return records, nil
+}
+
+// DisplayNames
+// Return the display names of the records with the given ids, read from
+// display_name or, before Odoo 17, with name_get
+// model: model name
+// ids: list of record ids
+func (o *OdooXML) DisplayNames(ctx context.Context, model string, ids []int) (records []odoorpc.Many2One, err error) {
+	version, err := o.ServerVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("display_names failed: %w", err)
+	}
+	if odoorpc.UsesDisplayName(version) {
+		rows, err := o.Read(ctx, model, ids, "display_name")
+		if err != nil {
+			return nil, err
+		}
+		return odoorpc.DisplayNames(rows), nil
+	}
+	var res any
+	if err := o.execute(ctx, &res, model, "name_get", []any{ids}, nil); err != nil {
+		return nil, fmt.Errorf("name_get failed: %w", err)
+	}
+	if records, err = odoorpc.ParseMany2Ones(res); err != nil {
+		return nil, fmt.Errorf("name_get failed: %w", err)
+	}
+	return records, nil
+}
+
+// ExportData
+// Export the field paths of the records with the given ids as rows, with
+// relations resolved by the server
+// model: model name
+// ids: list of record ids
+// fields: list of field paths
+// Example:
+// fields = ["name", "partner_id/name", "order_line/product_id/id"]
+func (o *OdooXML) ExportData(ctx context.Context, model string, ids []int, fields []string) (rows [][]any, err error) {
+	var res any
+	if err := o.execute(ctx, &res, model, "export_data", []any{ids, fields}, nil); err != nil {
+		return nil, fmt.Errorf("export_data failed: %w", err)
+	}
+	if rows, err = odoorpc.ParseExportData(res); err != nil {
+		return nil, fmt.Errorf("export_data failed: %w", err)
+	}
+	return rows, nil
+}
+
+// CallMethod
+// Call any method of the model with keyword arguments and return its raw
+// result
+// model: model name
+// method: method name
+// ids: record ids the method is called on, nil for model methods
+// kwargs: dictionary of keyword arguments
+// Example:
+// method = "parse_preview"
+// ids = [1]
+// kwargs = {"options": {"has_headers": true}}
+func (o *OdooXML) CallMethod(ctx context.Context, model string, method string, ids []int, kwargs map[string]any) (result any, err error) {
+	args := []any{}
+	if ids != nil {
+		args = []any{ids}
+	}
+	if kwargs == nil {
+		kwargs = map[string]any{}
+	}
+	if err = o.execute(ctx, &result, model, method, args, kwargs); err != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, err)
+	}
+	return result, nil
+}
+
+// RefID
+// Return the model and id of the record an external id refers to
+// xmlid: external id, "module.name"
+// Example:
+// xmlid = "base.main_company"
+func (o *OdooXML) RefID(ctx context.Context, xmlid string) (model string, id int, err error) {
+	return odoorpc.RefID(ctx, o, xmlid)
+}
+
+// RefIDs
+// Return the records the external ids refer to, looked up in a single call;
+// external ids that do not exist are absent from the result
+// xmlids: list of external ids
+func (o *OdooXML) RefIDs(ctx context.Context, xmlids []string) (refs map[string]odoorpc.XMLRef, err error) {
+	return odoorpc.RefIDs(ctx, o, xmlids)
+}
+
+// EnsureXMLID
+// Give a record an external id unless it already has it, failing if the
+// external id refers to another record
+// model: model name
+// id: record id
+// xmlid: external id, "module.name"
+func (o *OdooXML) EnsureXMLID(ctx context.Context, model string, id int, xmlid string) (err error) {
+	return odoorpc.EnsureXMLIDs(ctx, o, model, map[string]int{xmlid: id})
+}
+
+// Upsert
+// Update the record the external id refers to with the values, or create
+// it with the values and the external id, and return its id
+// model: model name
+// xmlid: external id, "module.name"
+// values: dictionary of field values
+func (o *OdooXML) Upsert(ctx context.Context, model string, xmlid string, values map[string]any) (id int, err error) {
+	ids, err := odoorpc.UpsertXMLIDs(ctx, o, model, []string{xmlid}, []map[string]any{values})
+	if err != nil {
+		return 0, err
+	}
+	return ids[0], nil
+}
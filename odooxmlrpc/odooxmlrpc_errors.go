@@ -0,0 +1,92 @@
+package odooxmlrpc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ppreeper/odoorpc/xmlrpc"
+)
+
+// Odoo XML-RPC fault codes, see odoo/addons/base/controllers/rpc.py.
+const (
+	faultCodeApplicationError = 1
+	faultCodeWarning          = 2
+	faultCodeAccessDenied     = 3
+	faultCodeAccessError      = 4
+)
+
+// faultCodeNames maps the fault codes Odoo emits without a traceback to the
+// exception class that produced them.
+var faultCodeNames = map[int]string{
+	faultCodeWarning:      "odoo.exceptions.UserError",
+	faultCodeAccessDenied: "odoo.exceptions.AccessDenied",
+	faultCodeAccessError:  "odoo.exceptions.AccessError",
+}
+
+// Error is an Odoo server exception decoded from an XML-RPC fault. It exposes
+// the same detail the JSON-RPC transport returns in error.data: the exception
+// class, its message and the server-side traceback.
+type Error struct {
+	// Code is the XML-RPC faultCode.
+	Code int
+	// Name is the exception class, e.g. "odoo.exceptions.ValidationError".
+	Name string
+	// Message is the exception message without the traceback.
+	Message string
+	// Frames is the server-side stack, outermost call first. It is empty for
+	// user-facing exceptions, which Odoo reports without a traceback.
+	Frames []xmlrpc.Frame
+	// Debug is the raw faultString.
+	Debug string
+}
+
+func (e *Error) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("fault %d: %s: %s", e.Code, e.Name, e.Message)
+	}
+	return fmt.Sprintf("fault %d: %s", e.Code, e.Message)
+}
+
+// OdooException returns the exception class, e.g.
+// "odoo.exceptions.AccessError".
+func (e *Error) OdooException() string {
+	return e.Name
+}
+
+// AuthFailure reports whether the server rejected the credentials or the
+// session expired.
+func (e *Error) AuthFailure() bool {
+	return e.Code == faultCodeAccessDenied || e.Name == "odoo.exceptions.AccessDenied" ||
+		e.Name == "odoo.http.SessionExpiredException"
+}
+
+// Unwrap returns the original xmlrpc.FaultError.
+func (e *Error) Unwrap() error {
+	return xmlrpc.FaultError{Code: e.Code, String: e.Debug}
+}
+
+// newError builds an Error from a fault.
+func newError(fault xmlrpc.FaultError) *Error {
+	tb := fault.Traceback()
+	name := tb.Exception
+	if name == "" {
+		name = faultCodeNames[fault.Code]
+	}
+	return &Error{
+		Code:    fault.Code,
+		Name:    name,
+		Message: tb.Message,
+		Frames:  tb.Frames,
+		Debug:   fault.String,
+	}
+}
+
+// wrapFault converts an xmlrpc.FaultError into *Error and returns any other
+// error unchanged.
+func wrapFault(err error) error {
+	var fault xmlrpc.FaultError
+	if errors.As(err, &fault) {
+		return newError(fault)
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package odooxmlrpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ppreeper/odoorpc/xmlrpc"
+)
+
+// xmlrpcFault builds an XML-RPC fault response body.
+func xmlrpcFault(code int, msg string) string {
+	return `<?xml version="1.0"?><methodResponse><fault><value><struct>` +
+		`<member><name>faultCode</name><value><int>` + strconv.Itoa(code) + `</int></value></member>` +
+		`<member><name>faultString</name><value><string>` + msg + `</string></value></member>` +
+		`</struct></value></fault></methodResponse>`
+}
+
+func TestCallReturnsParsedTraceback(t *testing.T) {
+	t.Parallel()
+	tb := "Traceback (most recent call last):\n" +
+		"  File \"/odoo/models.py\", line 42, in _check\n" +
+		"    raise ValidationError(msg)\n" +
+		"odoo.exceptions.ValidationError: Name is required\n"
+	ts, _ := newQueueServer(t, []string{xmlrpcFault(1, tb)})
+	defer ts.Close()
+
+	o := newXMLCRUDClient(t, ts)
+	_, err := o.Create(context.Background(), "res.partner", map[string]any{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	var oe *Error
+	if !errors.As(err, &oe) {
+		t.Fatalf("expected *Error in chain, got %T: %v", err, err)
+	}
+	if oe.Name != "odoo.exceptions.ValidationError" {
+		t.Errorf("Name: got %q", oe.Name)
+	}
+	if oe.Message != "Name is required" {
+		t.Errorf("Message: got %q", oe.Message)
+	}
+	if len(oe.Frames) != 1 || oe.Frames[0].Line != 42 || oe.Frames[0].Function != "_check" {
+		t.Errorf("Frames: got %+v", oe.Frames)
+	}
+	if oe.Debug != tb {
+		t.Errorf("Debug should hold the raw fault string, got %q", oe.Debug)
+	}
+	if !strings.Contains(err.Error(), "ValidationError: Name is required") {
+		t.Errorf("error message should be concise, got %q", err.Error())
+	}
+
+	var fe xmlrpc.FaultError
+	if !errors.As(err, &fe) || fe.Code != 1 {
+		t.Errorf("expected xmlrpc.FaultError with code 1 in chain, got %v", fe)
+	}
+}
+
+func TestCallFaultCodeWithoutTraceback(t *testing.T) {
+	t.Parallel()
+	ts, _ := newQueueServer(t, []string{xmlrpcFault(4, "You are not allowed to modify this document")})
+	defer ts.Close()
+
+	o := newXMLCRUDClient(t, ts)
+	_, err := o.Write(context.Background(), "res.partner", 1, map[string]any{"name": "x"})
+
+	var oe *Error
+	if !errors.As(err, &oe) {
+		t.Fatalf("expected *Error, got %T: %v", err, err)
+	}
+	if oe.Name != "odoo.exceptions.AccessError" {
+		t.Errorf("Name: got %q, want odoo.exceptions.AccessError", oe.Name)
+	}
+	if oe.Message != "You are not allowed to modify this document" {
+		t.Errorf("Message: got %q", oe.Message)
+	}
+	if len(oe.Frames) != 0 {
+		t.Errorf("expected no frames, got %+v", oe.Frames)
+	}
+}
+
+func TestLoginFaultIsWrapped(t *testing.T) {
+	t.Parallel()
+	ts, o := newXMLTestServer(t, xmlrpcFault(3, "Access Denied"))
+	defer ts.Close()
+
+	err := o.Login(context.Background())
+	var oe *Error
+	if !errors.As(err, &oe) {
+		t.Fatalf("expected *Error, got %T: %v", err, err)
+	}
+	if oe.Name != "odoo.exceptions.AccessDenied" {
+		t.Errorf("Name: got %q", oe.Name)
+	}
+}
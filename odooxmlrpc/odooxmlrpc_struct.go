@@ -1,8 +1,14 @@
 package odooxmlrpc
 
 import (
+	"crypto/tls"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/ppreeper/odoorpc"
 	"github.com/ppreeper/odoorpc/xmlrpc"
@@ -13,18 +19,33 @@ var _ odoorpc.Odoo = (*OdooXML)(nil)
 
 // OdooXML connection
 // Return a new instance of the OdooXML class
+// Configure it with the With methods before use; after that it is safe for
+// concurrent use, including Login while other calls are in flight.
 type OdooXML struct {
-	hostname string
-	port     int
-	schema   string
-	database string
-	username string
-	password string
-	timeout  time.Duration
-	url      string
-	uid      int
-	common   *xmlrpc.Client
-	models   *xmlrpc.Client
+	hostname     string
+	port         int
+	schema       string
+	database     string
+	username     string
+	password     string
+	timeout      time.Duration
+	url          string
+	uid          int
+	version      *odoorpc.Version
+	client       *http.Client
+	transport    http.RoundTripper
+	baseURL      string
+	host         string
+	tls          *odoorpc.TLSOptions
+	tlsConfig    *tls.Config
+	interceptors []odoorpc.Interceptor
+	relogin      *odoorpc.LoginGroup
+	common       *xmlrpc.Client
+	models       *xmlrpc.Client
+
+	// mu guards url, tlsConfig, uid, version, common and models, which
+	// Login and ServerVersion set while other calls may be in flight.
+	mu *sync.RWMutex
 }
 
 func (o *OdooXML) WithHostname(hostname string) *OdooXML {
@@ -57,11 +78,97 @@ func (o *OdooXML) WithSchema(schema string) *OdooXML {
 	return o
 }
 
+// WithBaseURL sets the server URL, e.g. "https://erp.example.com/odoo/" for
+// Odoo served under a path prefix behind a reverse proxy. It takes precedence
+// over the schema, hostname and port; the port defaults to 80 for http and
+// 443 for https.
+func (o *OdooXML) WithBaseURL(baseURL string) *OdooXML {
+	o.baseURL = baseURL
+	o.url = ""
+	return o
+}
+
+// WithHost sets the Host header sent with every request, e.g. to select a
+// database through Odoo's dbfilter while connecting by IP address.
+func (o *OdooXML) WithHost(host string) *OdooXML {
+	o.host = host
+	o.url = ""
+	return o
+}
+
 func (o *OdooXML) WithTimeout(timeout time.Duration) *OdooXML {
 	o.timeout = timeout
 	return o
 }
 
+// WithHTTPClient sets the http.Client used for requests, e.g. one with a
+// corporate proxy, a custom dialer or connection pool settings. The client is
+// copied; its Timeout is used instead of the one set by WithTimeout. A nil
+// client restores the default. It takes effect at the next Login.
+func (o *OdooXML) WithHTTPClient(client *http.Client) *OdooXML {
+	o.client = client
+	o.url = ""
+	return o
+}
+
+// WithTransport sets the http.RoundTripper used for requests, e.g. a
+// recording transport or a test double. It takes precedence over the
+// transport of the client set by WithHTTPClient. A nil transport restores the
+// default. It takes effect at the next Login.
+func (o *OdooXML) WithTransport(transport http.RoundTripper) *OdooXML {
+	o.transport = transport
+	o.url = ""
+	return o
+}
+
+// WithTLS sets the TLS options for https connections: trusted CAs, a client
+// certificate, the minimum version and SPKI pins. The options are validated
+// when the URL is built. It takes effect at the next Login.
+func (o *OdooXML) WithTLS(options odoorpc.TLSOptions) *OdooXML {
+	o.tls = &options
+	o.url = ""
+	return o
+}
+
+// WithInterceptors appends interceptors that wrap every call made by the
+// client, in the order given.
+func (o *OdooXML) WithInterceptors(interceptors ...odoorpc.Interceptor) *OdooXML {
+	o.interceptors = append(o.interceptors, interceptors...)
+	return o
+}
+
+// WithLogger logs every call made by the client to logger, with credentials
+// and binary values redacted. Successful calls are logged at debug level and
+// failures at error level; use odoorpc.LogInterceptor with WithInterceptors
+// for other levels. A nil logger logs to slog.Default().
+func (o *OdooXML) WithLogger(logger *slog.Logger) *OdooXML {
+	return o.WithInterceptors(odoorpc.LogInterceptor(logger, odoorpc.LogOptions{}))
+}
+
+// WithTracer wraps every call made by the client in a span started by tracer
+// and sends the span's W3C traceparent header with the request.
+func (o *OdooXML) WithTracer(tracer odoorpc.Tracer) *OdooXML {
+	return o.WithInterceptors(odoorpc.TraceInterceptor(tracer))
+}
+
+// WithMetrics reports the latency, sizes and outcome of every call made by
+// the client to m.
+func (o *OdooXML) WithMetrics(m odoorpc.Metrics) *OdooXML {
+	return o.WithInterceptors(odoorpc.MetricsInterceptor(m))
+}
+
+// WithAutoRelogin makes the client log in again and retry a call once when
+// the server rejects it as unauthenticated, e.g. after a server restart or
+// the expiry of a session. Concurrent failures share a single Login. The
+// retry is passed to interceptors with CallInfo.Attempt set to 1.
+func (o *OdooXML) WithAutoRelogin(enabled bool) *OdooXML {
+	o.relogin = nil
+	if enabled {
+		o.relogin = new(odoorpc.LoginGroup)
+	}
+	return o
+}
+
 func NewOdoo() *OdooXML {
 	return &OdooXML{
 		hostname: "localhost",
@@ -71,25 +178,94 @@ func NewOdoo() *OdooXML {
 		username: "odoo",
 		password: "odoo",
 		timeout:  30 * time.Second,
+		mu:       new(sync.RWMutex),
 	}
 }
 
 func NewOdooWithConfig(config OdooXML) *OdooXML {
 	c := config
+	c.mu = new(sync.RWMutex)
+	if c.relogin != nil {
+		c.relogin = new(odoorpc.LoginGroup)
+	}
 	return &c
 }
 
+// lock returns the mutex guarding the connection state, created on first
+// use for clients not built by the constructors.
+func (o *OdooXML) lock() *sync.RWMutex {
+	p := (*unsafe.Pointer)(unsafe.Pointer(&o.mu))
+	if mu := atomic.LoadPointer(p); mu != nil {
+		return (*sync.RWMutex)(mu)
+	}
+	atomic.CompareAndSwapPointer(p, nil, unsafe.Pointer(new(sync.RWMutex)))
+	return (*sync.RWMutex)(atomic.LoadPointer(p))
+}
+
 // genURL returns url string
 func (o *OdooXML) genURL() error {
-	if o.schema != "http" && o.schema != "https" {
+	schema, hostname, port, prefix := o.schema, o.hostname, o.port, ""
+	if o.baseURL != "" {
+		var err error
+		if schema, hostname, port, prefix, err = odoorpc.ParseBaseURL(o.baseURL); err != nil {
+			return err
+		}
+	}
+	if schema != "http" && schema != "https" {
 		return fmt.Errorf("invalid schema: http or https")
 	}
-	if o.port < 1 || o.port > 65535 {
+	if port < 1 || port > 65535 {
 		return fmt.Errorf("invalid port: 1-65535")
 	}
-	if len(o.hostname) == 0 || len(o.hostname) > 2048 {
+	if len(hostname) == 0 || len(hostname) > 2048 {
 		return fmt.Errorf("invalid hostname length: 1-2048")
 	}
-	o.url = fmt.Sprintf("%s://%s:%d/xmlrpc/2/", o.schema, o.hostname, o.port)
+	o.tlsConfig = nil
+	if o.tls != nil {
+		if schema != "https" {
+			return fmt.Errorf("invalid tls: options require the https schema")
+		}
+		cfg, err := o.tls.Config()
+		if err != nil {
+			return fmt.Errorf("invalid tls: %w", err)
+		}
+		o.tlsConfig = cfg
+	}
+	o.version = nil
+	o.url = odoorpc.JoinURL(schema, hostname, port, prefix, "xmlrpc/2/")
 	return nil
 }
+
+// httpClient returns the client requests are sent with: a copy of the client
+// set by WithHTTPClient, or a new one whose transport waits at most the
+// configured timeout for response headers. The transport set by
+// WithTransport replaces the client's own, and is wrapped by
+// odoorpc.InstrumentTransport. The TLS options validated by genURL are
+// applied to the transport.
+func (o *OdooXML) httpClient() (*http.Client, error) {
+	var client *http.Client
+	if o.client != nil {
+		clientCopy := *o.client
+		client = &clientCopy
+	} else {
+		// Hung servers must not block indefinitely.
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.ResponseHeaderTimeout = o.timeout
+		client = &http.Client{Transport: transport}
+	}
+	if o.transport != nil {
+		client.Transport = o.transport
+	}
+	if o.tlsConfig != nil {
+		transport, err := odoorpc.TransportWithTLS(client.Transport, o.tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls: %w", err)
+		}
+		client.Transport = transport
+	}
+	if o.host != "" {
+		client.Transport = odoorpc.HostTransport(client.Transport, o.host)
+	}
+	client.Transport = odoorpc.InstrumentTransport(client.Transport)
+	return client, nil
+}
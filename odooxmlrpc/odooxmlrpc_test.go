@@ -31,7 +31,7 @@ var genURLTests = []struct {
 	{"port too large", "https", "localhost", 65536, "", errors.New("invalid port: 1-65535")},
 	{"empty hostname", "http", "", 8069, "", errors.New("invalid hostname length: 1-2048")},
 	{"http ok", "http", "localhost", 8069, "http://localhost:8069/xmlrpc/2/", nil},
-	{"https ok", "https", "myhost", 443, "https://myhost:443/xmlrpc/2/", nil},
+	{"https ok", "https", "myhost", 443, "https://myhost/xmlrpc/2/", nil},
 }
 
 func TestGenURL(t *testing.T) {
@@ -127,6 +127,23 @@ func TestLoginBadCredentials(t *testing.T) {
 	}
 }
 
+func TestLoginReusesClients(t *testing.T) {
+	t.Parallel()
+	ts, o := newXMLTestServer(t, xmlrpcResponse("<int>7</int>"))
+	defer ts.Close()
+
+	if err := o.Login(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	common, models := o.common, o.models
+	if err := o.Login(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.common != common || o.models != models {
+		t.Error("second login created new rpc clients")
+	}
+}
+
 func TestLoginGenURLFailure(t *testing.T) {
 	t.Parallel()
 	o := &OdooXML{
@@ -209,10 +226,10 @@ func TestLoadUnexpectedResponseReturnsError(t *testing.T) {
 	defer ts.Close()
 
 	o := newXMLCRUDClient(t, ts)
-	ids, err := o.Load(context.Background(), "res.partner",
+	res, err := o.Load(context.Background(), "res.partner",
 		[]string{"name"}, [][]any{{"Alice"}})
 	if err == nil {
-		t.Fatalf("expected error for unexpected response type, got ids=%v", ids)
+		t.Fatalf("expected error for unexpected response type, got %+v", res)
 	}
 	if !strings.Contains(err.Error(), "unexpected response type") {
 		t.Errorf("unexpected error message: %v", err)
@@ -0,0 +1,167 @@
+package odoorpc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReadGroupOptions are the optional parameters of ReadGroup.
+type ReadGroupOptions struct {
+	// Offset is the number of groups to skip.
+	Offset int
+	// Limit is the maximum number of groups returned; 0 means no limit.
+	Limit int
+	// Order sorts the groups, e.g. "date:month desc". Defaults to the
+	// groupBy order.
+	Order string
+}
+
+// GroupKey is the value of a group for one groupBy specification.
+type GroupKey struct {
+	// Value is the record id for many2one fields, the first day of the
+	// period for date and datetime fields (e.g. "2024-01-01" for
+	// "date:month") and the field value otherwise. It is nil for the group
+	// of records without a value.
+	Value any
+	// Label is the value as displayed: the display name of the record, the
+	// period (e.g. "January 2024") or the formatted value.
+	Label string
+}
+
+// Group is one row of a ReadGroup result.
+type Group struct {
+	// Keys holds the value of the group for each groupBy specification,
+	// e.g. "partner_id" or "date:month".
+	Keys map[string]GroupKey
+	// Count is the number of records in the group.
+	Count int
+	// Aggregates holds the value of each aggregate specification, e.g.
+	// "amount_total:sum". Aggregates of groups without values are 0.
+	Aggregates map[string]float64
+}
+
+// formattedReadGroupMajor is the first major version serving
+// formatted_read_group, which replaces read_group.
+const formattedReadGroupMajor = 19
+
+// ReadGroupMethod returns the method grouped reads use on a server of
+// version v: formatted_read_group from Odoo 19 and read_group before.
+func ReadGroupMethod(v Version) string {
+	if v.Major >= formattedReadGroupMajor {
+		return "formatted_read_group"
+	}
+	return "read_group"
+}
+
+// ReadGroupKwargs returns the keyword arguments of a grouped read with
+// method. Records are grouped by the groupBy specifications, e.g.
+// "partner_id" or "date:month", and aggregated by the aggregate
+// specifications, e.g. "amount_total:sum"; the count of each group is always
+// requested.
+func ReadGroupKwargs(method string, domain []any, groupBy, aggregates []string, opts ReadGroupOptions) map[string]any {
+	if domain == nil {
+		domain = []any{}
+	}
+	kwargs := map[string]any{
+		"domain":  domain,
+		"groupby": groupBy,
+	}
+	order := "orderby"
+	if method == "formatted_read_group" {
+		kwargs["aggregates"] = append([]string{"__count"}, aggregates...)
+		order = "order"
+	} else {
+		kwargs["fields"] = append([]string{}, aggregates...)
+		kwargs["lazy"] = false
+	}
+	if opts.Offset > 0 {
+		kwargs["offset"] = opts.Offset
+	}
+	if opts.Limit > 0 {
+		kwargs["limit"] = opts.Limit
+	}
+	if opts.Order != "" {
+		kwargs[order] = opts.Order
+	}
+	return kwargs
+}
+
+// ParseGroups converts the result of a grouped read with method into groups.
+func ParseGroups(method string, groupBy, aggregates []string, result any) ([]Group, error) {
+	rows, ok := result.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: unexpected response type %T", method, result)
+	}
+	groups := make([]Group, 0, len(rows))
+	for _, r := range rows {
+		row, ok := r.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: unexpected group type %T", method, r)
+		}
+		count, _ := toFloat(row["__count"])
+		g := Group{
+			Keys:       make(map[string]GroupKey, len(groupBy)),
+			Count:      int(count),
+			Aggregates: make(map[string]float64, len(aggregates)),
+		}
+		ranges, _ := row["__range"].(map[string]any)
+		for _, spec := range groupBy {
+			g.Keys[spec] = groupKey(row[spec], ranges[spec])
+		}
+		for _, spec := range aggregates {
+			key := spec
+			if method != "formatted_read_group" {
+				// read_group names aggregates after the field or alias.
+				key, _, _ = strings.Cut(spec, ":")
+			}
+			g.Aggregates[spec], _ = toFloat(row[key])
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// groupKey converts the value of a groupby in a grouped read result.
+// Many2one and date groups are [value, label] pairs, except in read_group,
+// which returns the label of date groups and their bounds in __range.
+func groupKey(v, dateRange any) GroupKey {
+	switch v := v.(type) {
+	case nil, bool:
+		if v == true {
+			return GroupKey{Value: true, Label: "true"}
+		}
+		return GroupKey{}
+	case []any:
+		if len(v) == 2 {
+			label, _ := v[1].(string)
+			if id, ok := toFloat(v[0]); ok {
+				return GroupKey{Value: int(id), Label: label}
+			}
+			return GroupKey{Value: v[0], Label: label}
+		}
+	case string:
+		if r, ok := dateRange.(map[string]any); ok {
+			return GroupKey{Value: r["from"], Label: v}
+		}
+		return GroupKey{Value: v, Label: v}
+	}
+	if f, ok := toFloat(v); ok && f == float64(int(f)) {
+		v = int(f)
+	}
+	return GroupKey{Value: v, Label: fmt.Sprint(v)}
+}
+
+// toFloat converts a numeric value decoded from JSON or XML-RPC.
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,106 @@
+package odoorpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		raw          string
+		major, minor int
+	}{
+		{"17.0", 17, 0},
+		{"saas~17.2", 17, 2},
+		{"18.0+e", 18, 0},
+		{"16.0-20240101", 16, 0},
+	}
+	for _, tt := range tests {
+		v, err := odoorpc.ParseVersion(tt.raw)
+		if err != nil || v.Major != tt.major || v.Minor != tt.minor || v.String() != tt.raw {
+			t.Errorf("ParseVersion(%q) = %+v, %v", tt.raw, v, err)
+		}
+	}
+	if _, err := odoorpc.ParseVersion("master"); err == nil {
+		t.Error("expected an error for a version without numbers")
+	}
+	if v := (odoorpc.Version{Major: 17, Minor: 2}); !v.AtLeast(17, 0) || v.AtLeast(17, 3) || !v.AtLeast(16, 4) || v.AtLeast(18, 0) {
+		t.Errorf("AtLeast: unexpected results for %+v", v)
+	}
+	for raw, want := range map[string]string{"17.0": "read_group", "18.0": "read_group", "saas~18.3": "read_group", "19.0": "formatted_read_group"} {
+		v, _ := odoorpc.ParseVersion(raw)
+		if got := odoorpc.ReadGroupMethod(v); got != want {
+			t.Errorf("ReadGroupMethod(%s) = %s, want %s", raw, got, want)
+		}
+	}
+}
+
+func TestReadGroup(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	for _, version := range []string{"17.0", "18.0", "19.0"} {
+		srv := odoorpctest.NewServer()
+		t.Cleanup(srv.Close)
+		srv.Version = version
+		srv.DefineModel("sale.order", map[string]odoorpctest.Field{
+			"partner_id":   {Type: "many2one", Relation: "res.partner"},
+			"date_order":   {Type: "date"},
+			"amount_total": {Type: "float"},
+		})
+		partners, err := srv.Seed("res.partner", map[string]any{"name": "Acme"}, map[string]any{"name": "Globex"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.Seed("sale.order",
+			map[string]any{"partner_id": partners[0], "date_order": "2024-01-05", "amount_total": 100.0},
+			map[string]any{"partner_id": partners[0], "date_order": "2024-01-20", "amount_total": 50.0},
+			map[string]any{"partner_id": partners[0], "date_order": "2024-02-01", "amount_total": 25.0},
+			map[string]any{"partner_id": partners[1], "date_order": "2024-02-10", "amount_total": 10.0},
+			map[string]any{"date_order": "2024-03-01", "amount_total": 1.0},
+		); err != nil {
+			t.Fatal(err)
+		}
+
+		for name, o := range interceptedClients(t, srv) {
+			v, err := o.ServerVersion(ctx)
+			if err != nil || v.Raw != version {
+				t.Fatalf("%s/%s: version %+v, %v", version, name, v, err)
+			}
+
+			groups, err := o.ReadGroup(ctx, "sale.order", nil,
+				[]string{"partner_id", "date_order:month"}, []string{"amount_total:sum"}, odoorpc.ReadGroupOptions{})
+			if err != nil {
+				t.Fatalf("%s/%s: %v", version, name, err)
+			}
+			if len(groups) != 4 {
+				t.Fatalf("%s/%s: got %d groups, want 4: %+v", version, name, len(groups), groups)
+			}
+			first := groups[0]
+			if k := first.Keys["partner_id"]; k.Value != partners[0] || k.Label != "Acme" {
+				t.Errorf("%s/%s: partner key %+v", version, name, k)
+			}
+			if k := first.Keys["date_order:month"]; k.Value != "2024-01-01" || k.Label != "January 2024" {
+				t.Errorf("%s/%s: month key %+v", version, name, k)
+			}
+			if first.Count != 2 || first.Aggregates["amount_total:sum"] != 150 {
+				t.Errorf("%s/%s: first group %+v", version, name, first)
+			}
+			if last := groups[3]; last.Keys["partner_id"].Value != nil || last.Count != 1 {
+				t.Errorf("%s/%s: expected the group without partner last, got %+v", version, name, last)
+			}
+
+			limited, err := o.ReadGroup(ctx, "sale.order", []any{[]any{"amount_total", ">", 20}},
+				[]string{"partner_id"}, []string{"amount_total:max"}, odoorpc.ReadGroupOptions{Limit: 1})
+			if err != nil {
+				t.Fatalf("%s/%s: %v", version, name, err)
+			}
+			if len(limited) != 1 || limited[0].Count != 3 || limited[0].Aggregates["amount_total:max"] != 100 {
+				t.Errorf("%s/%s: limited groups %+v", version, name, limited)
+			}
+		}
+	}
+}
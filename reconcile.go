@@ -0,0 +1,599 @@
+package odoorpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultSyncBatchSize is the number of records per call when applying a
+// SyncPlan with no batch size.
+const DefaultSyncBatchSize = 100
+
+// SyncMissing is what PlanSync does with the current records that are not
+// desired.
+type SyncMissing string
+
+const (
+	// KeepMissing leaves them unchanged.
+	KeepMissing SyncMissing = ""
+	// ArchiveMissing archives them, and restores the archived records that
+	// are desired again. The model must have an active field. With the other
+	// modes, archived records stay archived.
+	ArchiveMissing SyncMissing = "archive"
+	// DeleteMissing deletes them.
+	DeleteMissing SyncMissing = "delete"
+)
+
+// SyncOptions configures PlanSync.
+type SyncOptions struct {
+	// Key is the field holding the natural key of the records, e.g. ref or
+	// default_code. When empty, records are keyed by external id, and the
+	// current records are those with an external id in the modules of the
+	// desired keys.
+	Key string
+	// Domain restricts the current records to those it matches; nil
+	// considers all records. Current records without a key are ignored.
+	Domain []any
+	// Missing is what to do with the current records that are not desired.
+	Missing SyncMissing
+}
+
+// SyncOp is the operation of a SyncAction.
+type SyncOp string
+
+const (
+	SyncCreate  SyncOp = "create"
+	SyncUpdate  SyncOp = "update"
+	SyncArchive SyncOp = "archive"
+	SyncDelete  SyncOp = "delete"
+)
+
+// FieldChange is the change of a field value by an update.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// SyncAction is an operation on a record.
+type SyncAction struct {
+	Op  SyncOp `json:"op"`
+	Key string `json:"key"`
+	// ID is the id of the record, except for creates.
+	ID int `json:"id,omitempty"`
+	// Values are the values of a create or the changed values of an update.
+	Values map[string]any `json:"values,omitempty"`
+	// Changes are the field changes of an update, sorted by field.
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// SyncPlan is the set of operations that makes the records of a model match
+// the desired records. It can be reviewed, serialized as JSON and read back
+// with ParseSyncPlan before it is applied.
+type SyncPlan struct {
+	Model string `json:"model"`
+	// Key is the natural key field, or empty when records are keyed by
+	// external id.
+	Key string `json:"key,omitempty"`
+	// Actions are the creates, updates, archives and deletes, in that
+	// order and by key.
+	Actions []SyncAction `json:"actions"`
+}
+
+// SyncResult reports what applying a SyncPlan did.
+type SyncResult struct {
+	Created  int
+	Updated  int
+	Archived int
+	Deleted  int
+	// IDs are the ids of the created and updated records by key.
+	IDs map[string]int
+}
+
+// PlanSync compares the desired records of model, keyed by natural key or
+// external id, with the current records, archived ones included, and returns
+// the plan that creates the missing records, updates the fields that differ
+// and archives or deletes the records that are not desired. Only the fields
+// of the desired records are compared; their values are those of Write, with
+// many2one values as ids and x2many values as lists of ids.
+func PlanSync(ctx context.Context, o Odoo, model string, desired map[string]map[string]any, opts SyncOptions) (*SyncPlan, error) {
+	fieldSet := map[string]bool{}
+	for key, values := range desired {
+		if key == "" {
+			return nil, fmt.Errorf("sync plan failed: empty key")
+		}
+		if opts.Key == "" {
+			if _, _, err := SplitXMLID(key); err != nil {
+				return nil, fmt.Errorf("sync plan failed: %w", err)
+			}
+		}
+		for field := range values {
+			fieldSet[field] = true
+		}
+	}
+	if opts.Key != "" {
+		fieldSet[opts.Key] = true
+	}
+	if opts.Missing == ArchiveMissing {
+		fieldSet["active"] = true
+	}
+	current, err := currentRecords(ctx, o, model, desired, sortedKeys(fieldSet), opts)
+	if err != nil {
+		return nil, fmt.Errorf("sync plan failed: %w", err)
+	}
+
+	plan := &SyncPlan{Model: model, Key: opts.Key}
+	var updates []SyncAction
+	for _, key := range sortedKeys(desired) {
+		values := desired[key]
+		rec, ok := current[key]
+		if !ok {
+			create := make(map[string]any, len(values)+1)
+			for k, v := range values {
+				create[k] = v
+			}
+			if opts.Key != "" {
+				if _, ok := create[opts.Key]; !ok {
+					create[opts.Key] = key
+				}
+			}
+			plan.Actions = append(plan.Actions, SyncAction{Op: SyncCreate, Key: key, Values: create})
+			continue
+		}
+		id, _ := toFloat(rec["id"])
+		update := SyncAction{Op: SyncUpdate, Key: key, ID: int(id), Values: map[string]any{}}
+		want := values
+		if opts.Missing == ArchiveMissing && rec["active"] == false {
+			if _, ok := values["active"]; !ok {
+				want = map[string]any{"active": true}
+				for k, v := range values {
+					want[k] = v
+				}
+			}
+		}
+		for _, field := range sortedKeys(want) {
+			if !sameValue(rec[field], want[field]) {
+				update.Values[field] = want[field]
+				update.Changes = append(update.Changes, FieldChange{Field: field, Old: rec[field], New: want[field]})
+			}
+		}
+		if len(update.Changes) > 0 {
+			updates = append(updates, update)
+		}
+	}
+	plan.Actions = append(plan.Actions, updates...)
+
+	if opts.Missing != KeepMissing {
+		for _, key := range sortedKeys(current) {
+			rec := current[key]
+			if _, ok := desired[key]; ok {
+				continue
+			}
+			id, _ := toFloat(rec["id"])
+			switch {
+			case opts.Missing == DeleteMissing:
+				plan.Actions = append(plan.Actions, SyncAction{Op: SyncDelete, Key: key, ID: int(id)})
+			case rec["active"] != false:
+				plan.Actions = append(plan.Actions, SyncAction{Op: SyncArchive, Key: key, ID: int(id)})
+			}
+		}
+		// Archives and deletes follow the creates and updates.
+		sort.SliceStable(plan.Actions, func(i, j int) bool {
+			return syncOpOrder[plan.Actions[i].Op] < syncOpOrder[plan.Actions[j].Op]
+		})
+	}
+	return plan, nil
+}
+
+var syncOpOrder = map[SyncOp]int{SyncCreate: 0, SyncUpdate: 1, SyncArchive: 2, SyncDelete: 3}
+
+// currentRecords reads the current records by key.
+func currentRecords(ctx context.Context, o Odoo, model string, desired map[string]map[string]any, fields []string, opts SyncOptions) (map[string]map[string]any, error) {
+	// The terms of a domain are implicitly and-ed.
+	domain := append([]any{}, opts.Domain...)
+	current := map[string]map[string]any{}
+	if opts.Key != "" {
+		records, err := searchReadAll(ctx, o, model, fields, domain)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			key := keyString(rec[opts.Key])
+			if key == "" {
+				continue
+			}
+			if prev, ok := current[key]; ok {
+				return nil, fmt.Errorf("records %v and %v of %s have the same %s %s", prev["id"], rec["id"], model, opts.Key, key)
+			}
+			current[key] = rec
+		}
+		return current, nil
+	}
+
+	modules := map[string]bool{}
+	for key := range desired {
+		module, _, _ := SplitXMLID(key)
+		modules[module] = true
+	}
+	if len(modules) == 0 {
+		return current, nil
+	}
+	data, err := o.SearchRead(ctx, "ir.model.data", 0, 0, []string{"module", "name", "res_id"},
+		[]any{[]any{"model", "=", model}, []any{"module", "in", sortedKeys(modules)}})
+	if err != nil {
+		return nil, err
+	}
+	// A record with several external ids is keyed by a desired one.
+	better := func(a, b string) bool {
+		_, da := desired[a]
+		_, db := desired[b]
+		return da && !db || da == db && a < b
+	}
+	xmlids := map[int]string{}
+	ids := []any{}
+	for _, rec := range data {
+		module, _ := rec["module"].(string)
+		name, _ := rec["name"].(string)
+		f, _ := toFloat(rec["res_id"])
+		id, xmlid := int(f), module+"."+name
+		prev, ok := xmlids[id]
+		if !ok {
+			ids = append(ids, id)
+		}
+		if !ok || better(xmlid, prev) {
+			xmlids[id] = xmlid
+		}
+	}
+	if len(ids) == 0 {
+		return current, nil
+	}
+	records, err := searchReadAll(ctx, o, model, fields, append(domain, []any{"id", "in", ids}))
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range records {
+		id, _ := toFloat(rec["id"])
+		current[xmlids[int(id)]] = rec
+	}
+	return current, nil
+}
+
+// searchReadAll is SearchRead including the archived records, which a
+// search only finds with active_test disabled.
+func searchReadAll(ctx context.Context, o Odoo, model string, fields []string, domain []any) ([]map[string]any, error) {
+	result, err := o.CallMethod(ctx, model, "search_read", nil, map[string]any{
+		"domain":  domain,
+		"fields":  fields,
+		"context": map[string]any{"active_test": false},
+	})
+	if err != nil {
+		return nil, err
+	}
+	list, ok := result.([]any)
+	if !ok {
+		return nil, fmt.Errorf("search_read: unexpected response type %T", result)
+	}
+	records := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		rec, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("search_read: unexpected record type %T in response", item)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// keyString returns the natural key of a field value.
+func keyString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool, nil:
+		return ""
+	case []any:
+		if len(v) == 2 {
+			return keyString(v[0])
+		}
+	}
+	if n, ok := toFloat(v); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// sameValue reports whether the current value of a field, as read, equals
+// its desired value, as written.
+func sameValue(current, desired any) bool {
+	if isEmptyValue(current) && isEmptyValue(desired) {
+		return true
+	}
+	if current == false {
+		// Only relational and empty fields read as false: 0 is no record.
+		n, ok := toFloat(desired)
+		return ok && n == 0
+	}
+	if pair, ok := current.([]any); ok && len(pair) == 2 {
+		if _, ok := pair[1].(string); ok {
+			// many2one [id, name]
+			current = pair[0]
+		}
+	}
+	if cur, ok := current.([]any); ok {
+		want, ok := idList(desired)
+		if !ok {
+			return false
+		}
+		have, _ := idList(cur)
+		sort.Ints(have)
+		sort.Ints(want)
+		return slices.Equal(have, want)
+	}
+	if a, ok := toFloat(current); ok {
+		b, ok := toFloat(desired)
+		return ok && a == b
+	}
+	return reflect.DeepEqual(current, desired)
+}
+
+// isEmptyValue reports whether v is an empty field value.
+func isEmptyValue(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case []int:
+		return len(v) == 0
+	}
+	return false
+}
+
+// idList converts a list of ids.
+func idList(v any) ([]int, bool) {
+	switch v := v.(type) {
+	case []int:
+		return append([]int(nil), v...), true
+	case []any:
+		ids := make([]int, 0, len(v))
+		for _, x := range v {
+			id, ok := toFloat(x)
+			if !ok {
+				return nil, false
+			}
+			ids = append(ids, int(id))
+		}
+		return ids, true
+	}
+	return nil, false
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Count returns the number of actions of op.
+func (p *SyncPlan) Count(op SyncOp) int {
+	n := 0
+	for _, a := range p.Actions {
+		if a.Op == op {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary returns the number of actions of each operation, e.g.
+// "2 to create, 1 to update, 0 to archive, 0 to delete".
+func (p *SyncPlan) Summary() string {
+	return fmt.Sprintf("%d to create, %d to update, %d to archive, %d to delete",
+		p.Count(SyncCreate), p.Count(SyncUpdate), p.Count(SyncArchive), p.Count(SyncDelete))
+}
+
+// String returns the plan for review, an action per line with the field
+// changes of updates, e.g.
+//
+//	create C003: name="Gamma"
+//	update C001 (id 4): email "a@example.com" -> "b@example.com"
+//	archive C009 (id 12)
+func (p *SyncPlan) String() string {
+	var b strings.Builder
+	for _, a := range p.Actions {
+		fmt.Fprintf(&b, "%s %s", a.Op, a.Key)
+		if a.ID != 0 {
+			fmt.Fprintf(&b, " (id %d)", a.ID)
+		}
+		switch a.Op {
+		case SyncCreate:
+			for i, field := range sortedKeys(a.Values) {
+				sep := ", "
+				if i == 0 {
+					sep = ": "
+				}
+				fmt.Fprintf(&b, "%s%s=%s", sep, field, reviewValue(a.Values[field]))
+			}
+		case SyncUpdate:
+			for i, c := range a.Changes {
+				sep := ", "
+				if i == 0 {
+					sep = ": "
+				}
+				fmt.Fprintf(&b, "%s%s %s -> %s", sep, c.Field, reviewValue(c.Old), reviewValue(c.New))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// reviewValue formats a field value as JSON.
+func reviewValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// ParseSyncPlan reads a plan serialized as JSON. Integral numbers are read
+// as ints, so that ids are sent as integers.
+func ParseSyncPlan(data []byte) (*SyncPlan, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var plan SyncPlan
+	if err := dec.Decode(&plan); err != nil {
+		return nil, fmt.Errorf("invalid sync plan: %w", err)
+	}
+	for i := range plan.Actions {
+		a := &plan.Actions[i]
+		for k, v := range a.Values {
+			a.Values[k] = jsonNumbers(v)
+		}
+		for j := range a.Changes {
+			a.Changes[j].Old = jsonNumbers(a.Changes[j].Old)
+			a.Changes[j].New = jsonNumbers(a.Changes[j].New)
+		}
+	}
+	return &plan, nil
+}
+
+// jsonNumbers converts the json.Numbers of v to ints and float64s.
+func jsonNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+		f, _ := v.Float64()
+		if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return int(f)
+		}
+		return f
+	case []any:
+		for i := range v {
+			v[i] = jsonNumbers(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = jsonNumbers(v[k])
+		}
+	}
+	return v
+}
+
+// writeValues returns the values of an action for create and write, with
+// lists of ids replacing the x2many ids.
+func writeValues(values map[string]any) map[string]any {
+	out := make(map[string]any, len(values))
+	for k, v := range values {
+		if ids, ok := idList(v); ok {
+			set := make([]any, len(ids))
+			for i, id := range ids {
+				set[i] = id
+			}
+			v = []any{[]any{6, 0, set}}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// Apply applies the plan: it creates the records batchSize at a time,
+// updates them one by one, and archives and deletes them batchSize at a
+// time; a batchSize of 0 means DefaultSyncBatchSize. Records keyed by
+// external id are created with their external id. On error, the result
+// reports what was applied.
+func (p *SyncPlan) Apply(ctx context.Context, o Odoo, batchSize int) (SyncResult, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultSyncBatchSize
+	}
+	res := SyncResult{IDs: map[string]int{}}
+	byOp := map[SyncOp][]SyncAction{}
+	for _, a := range p.Actions {
+		byOp[a.Op] = append(byOp[a.Op], a)
+	}
+
+	creates := byOp[SyncCreate]
+	for start := 0; start < len(creates); start += batchSize {
+		batch := creates[start:min(start+batchSize, len(creates))]
+		keys := make([]string, len(batch))
+		values := make([]map[string]any, len(batch))
+		vals := make([]any, len(batch))
+		for i, a := range batch {
+			keys[i] = a.Key
+			values[i] = writeValues(a.Values)
+			vals[i] = values[i]
+		}
+		var ids []int
+		var err error
+		if p.Key == "" {
+			ids, err = UpsertXMLIDs(ctx, o, p.Model, keys, values)
+		} else {
+			var result any
+			if result, err = o.CallMethod(ctx, p.Model, "create", nil, map[string]any{"vals_list": vals}); err == nil {
+				if ids, err = parseIDList(result); err == nil && len(ids) != len(batch) {
+					err = fmt.Errorf("unexpected create result %v", result)
+				}
+			}
+		}
+		if err != nil {
+			return res, fmt.Errorf("sync failed: create %s: %w", keys[0], err)
+		}
+		for i, key := range keys {
+			res.IDs[key] = ids[i]
+		}
+		res.Created += len(ids)
+	}
+
+	for _, a := range byOp[SyncUpdate] {
+		if _, err := o.Write(ctx, p.Model, a.ID, writeValues(a.Values)); err != nil {
+			return res, fmt.Errorf("sync failed: update %s: %w", a.Key, err)
+		}
+		res.IDs[a.Key] = a.ID
+		res.Updated++
+	}
+
+	for _, op := range []SyncOp{SyncArchive, SyncDelete} {
+		actions := byOp[op]
+		for start := 0; start < len(actions); start += batchSize {
+			batch := actions[start:min(start+batchSize, len(actions))]
+			ids := make([]int, len(batch))
+			for i, a := range batch {
+				ids[i] = a.ID
+			}
+			var err error
+			if op == SyncArchive {
+				_, err = o.CallMethod(ctx, p.Model, "write", ids, map[string]any{"vals": map[string]any{"active": false}})
+			} else {
+				_, err = o.Unlink(ctx, p.Model, ids)
+			}
+			if err != nil {
+				return res, fmt.Errorf("sync failed: %s %s: %w", op, batch[0].Key, err)
+			}
+			if op == SyncArchive {
+				res.Archived += len(ids)
+			} else {
+				res.Deleted += len(ids)
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,209 @@
+package odoorpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+// newSyncServer returns a server with partners keyed by ref and external id.
+func newSyncServer(t *testing.T) (*odoorpctest.Server, []int) {
+	t.Helper()
+	srv := odoorpctest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name":        {Type: "char", Required: true},
+		"ref":         {Type: "char"},
+		"email":       {Type: "char"},
+		"active":      {Type: "boolean"},
+		"parent_id":   {Type: "many2one", Relation: "res.partner"},
+		"category_id": {Type: "many2many", Relation: "res.partner.category"},
+	})
+	srv.DefineModel("res.partner.category", map[string]odoorpctest.Field{"name": {Type: "char"}})
+	tags, err := srv.Seed("res.partner.category", map[string]any{"name": "VIP"}, map[string]any{"name": "New"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := srv.Seed("res.partner",
+		map[string]any{"name": "Alice", "ref": "C1", "email": "alice@old.test", "active": true, "category_id": []any{tags[1], tags[0]}},
+		map[string]any{"name": "Bob", "ref": "C2", "active": true},
+		map[string]any{"name": "Carol", "ref": "C3", "active": false},
+		map[string]any{"name": "Dan", "active": true},
+		map[string]any{"name": "Erin", "ref": "C5", "active": true},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, xmlid := range []string{"sync.alice", "sync.bob", "sync.carol", "base.dan", "sync.erin"} {
+		module, name, _ := odoorpc.SplitXMLID(xmlid)
+		if _, err := srv.Seed("ir.model.data", map[string]any{"module": module, "name": name, "model": "res.partner", "res_id": ids[i]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return srv, append(ids, tags...)
+}
+
+func TestSyncByNaturalKey(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	for _, transport := range []string{odoorpc.TransportJSONRPC, odoorpc.TransportXMLRPC, odoorpc.TransportJSON2} {
+		srv, ids := newSyncServer(t)
+		o := interceptedClients(t, srv)[transport]
+		vip, newTag := ids[5], ids[6]
+		desired := map[string]map[string]any{
+			"C1": {"name": "Alice", "email": "alice@new.test", "category_id": []int{vip, newTag}},
+			"C2": {"name": "Bob", "email": false, "parent_id": 0},
+			"C3": {"name": "Carol"},
+			"C4": {"name": "Gus", "parent_id": ids[0], "active": true},
+		}
+		opts := odoorpc.SyncOptions{Key: "ref", Missing: odoorpc.ArchiveMissing}
+		plan, err := odoorpc.PlanSync(ctx, o, "res.partner", desired, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", transport, err)
+		}
+		want := `create C4: active=true, name="Gus", parent_id=1, ref="C4"
+update C1 (id 1): email "alice@old.test" -> "alice@new.test"
+update C3 (id 3): active false -> true
+archive C5 (id 5)
+`
+		if plan.String() != want {
+			t.Errorf("%s: plan:\n%s\nwant:\n%s", transport, plan, want)
+		}
+		if got := plan.Summary(); got != "1 to create, 2 to update, 1 to archive, 0 to delete" {
+			t.Errorf("%s: summary %q", transport, got)
+		}
+
+		// The plan survives serialization.
+		data, err := json.Marshal(plan)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plan, err = odoorpc.ParseSyncPlan(data); err != nil {
+			t.Fatal(err)
+		}
+		res, err := plan.Apply(ctx, o, 1)
+		if err != nil {
+			t.Fatalf("%s: apply: %v", transport, err)
+		}
+		if res.Created != 1 || res.Updated != 2 || res.Archived != 1 || res.Deleted != 0 || res.IDs["C4"] != 6 || res.IDs["C1"] != 1 {
+			t.Errorf("%s: result %+v", transport, res)
+		}
+		recs, err := o.Read(ctx, "res.partner", []int{1, 3, 5, 6}, "email", "active", "parent_id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recs[0]["email"] != "alice@new.test" || recs[1]["active"] != true || recs[2]["active"] != false {
+			t.Errorf("%s: records after apply: %v", transport, recs)
+		}
+		if parent, _ := recs[3]["parent_id"].([]any); len(parent) != 2 || parent[1] != "Alice" {
+			t.Errorf("%s: created record: %v", transport, recs[3])
+		}
+
+		// Applying the plan converges.
+		plan, err = odoorpc.PlanSync(ctx, o, "res.partner", desired, opts)
+		if err != nil || len(plan.Actions) != 0 {
+			t.Errorf("%s: plan after apply: %v\n%s", transport, err, plan)
+		}
+	}
+}
+
+func TestSyncByXMLID(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv, ids := newSyncServer(t)
+	o := interceptedClients(t, srv)[odoorpc.TransportJSONRPC]
+	desired := map[string]map[string]any{
+		"sync.alice": {"name": "Alice"},
+		"sync.bob":   {"name": "Robert"},
+		"sync.fay":   {"name": "Fay"},
+	}
+	opts := odoorpc.SyncOptions{Missing: odoorpc.DeleteMissing, Domain: []any{[]any{"name", "!=", "Erin"}}}
+	plan, err := odoorpc.PlanSync(ctx, o, "res.partner", desired, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// base.dan is in another module, and Erin is outside the domain.
+	want := []odoorpc.SyncAction{
+		{Op: odoorpc.SyncCreate, Key: "sync.fay", Values: map[string]any{"name": "Fay"}},
+		{Op: odoorpc.SyncUpdate, Key: "sync.bob", ID: ids[1], Values: map[string]any{"name": "Robert"},
+			Changes: []odoorpc.FieldChange{{Field: "name", Old: "Bob", New: "Robert"}}},
+		{Op: odoorpc.SyncDelete, Key: "sync.carol", ID: ids[2]},
+	}
+	if !reflect.DeepEqual(plan.Actions, want) {
+		t.Fatalf("plan:\n%s", plan)
+	}
+	res, err := plan.Apply(ctx, o, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, id, err := o.RefID(ctx, "sync.fay"); err != nil || id != res.IDs["sync.fay"] {
+		t.Errorf("external id of the created record: %d, %v; want %d", id, err, res.IDs["sync.fay"])
+	}
+	if names := partnerNames(srv); names != "Alice,Robert,Dan,Erin,Fay" {
+		t.Errorf("records after apply: %s", names)
+	}
+
+	if _, err := odoorpc.PlanSync(ctx, o, "res.partner", map[string]map[string]any{"nodot": {}}, odoorpc.SyncOptions{}); err == nil {
+		t.Error("invalid external id: expected an error")
+	}
+	if _, err := o.Write(ctx, "res.partner", ids[3], map[string]any{"ref": "C1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := odoorpc.PlanSync(ctx, o, "res.partner", nil, odoorpc.SyncOptions{Key: "ref"}); err == nil {
+		t.Error("duplicate natural keys: expected an error")
+	}
+}
+
+func TestSyncArchived(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv, ids := newSyncServer(t)
+	o := interceptedClients(t, srv)[odoorpc.TransportXMLRPC]
+	// Carol is archived: she is updated, not created again, and stays
+	// archived.
+	desired := map[string]map[string]any{"C3": {"name": "Caroline"}}
+	plan, err := odoorpc.PlanSync(ctx, o, "res.partner", desired, odoorpc.SyncOptions{Key: "ref"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []odoorpc.SyncAction{
+		{Op: odoorpc.SyncUpdate, Key: "C3", ID: ids[2], Values: map[string]any{"name": "Caroline"},
+			Changes: []odoorpc.FieldChange{{Field: "name", Old: "Carol", New: "Caroline"}}},
+	}
+	if !reflect.DeepEqual(plan.Actions, want) {
+		t.Fatalf("plan:\n%s", plan)
+	}
+}
+
+// singleCreator is a client whose server answers create with the id of the
+// first record only.
+type singleCreator struct {
+	odoorpc.Odoo
+}
+
+func (o singleCreator) CallMethod(ctx context.Context, model string, method string, ids []int, kwargs map[string]any) (any, error) {
+	if method == "create" {
+		return 42, nil
+	}
+	return o.Odoo.CallMethod(ctx, model, method, ids, kwargs)
+}
+
+func TestSyncShortCreate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv, _ := newSyncServer(t)
+	o := singleCreator{Odoo: interceptedClients(t, srv)[odoorpc.TransportJSONRPC]}
+	desired := map[string]map[string]any{"C7": {"name": "Gus"}, "C8": {"name": "Hal"}}
+	plan, err := odoorpc.PlanSync(ctx, o, "res.partner", desired, odoorpc.SyncOptions{Key: "ref"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := plan.Apply(ctx, o, 0)
+	if err == nil || res.Created != 0 {
+		t.Errorf("Apply = %+v, %v; want an error", res, err)
+	}
+}
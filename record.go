@@ -0,0 +1,301 @@
+package odoorpc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DateFormat and DatetimeFormat are the formats of date and datetime field
+// values. Datetimes are in UTC.
+const (
+	DateFormat     = "2006-01-02"
+	DatetimeFormat = "2006-01-02 15:04:05"
+)
+
+// recordField is a struct field mapped to an Odoo field by its odoo tag.
+type recordField struct {
+	name     string
+	index    int
+	readonly bool
+	date     bool
+}
+
+// recordFieldsCache maps struct types to their recordFields.
+var recordFieldsCache sync.Map
+
+var (
+	many2OneType = reflect.TypeFor[Many2One]()
+	timeType     = reflect.TypeFor[time.Time]()
+)
+
+// recordFields returns the fields of struct type t that have an odoo tag.
+// The tag holds the Odoo field name followed by options: "readonly" fields
+// are not sent by EncodeRecord unless named, and "date" fields are sent as
+// dates rather than datetimes, e.g.
+//
+//	DateOrder time.Time `odoo:"date_order,date"`
+func recordFields(t reflect.Type) []recordField {
+	if fields, ok := recordFieldsCache.Load(t); ok {
+		return fields.([]recordField)
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []recordField
+	for i := range t.NumField() {
+		tag, ok := t.Field(i).Tag.Lookup("odoo")
+		if !ok || tag == "-" || !t.Field(i).IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		f := recordField{name: name, index: i}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "readonly":
+				f.readonly = true
+			case "date":
+				f.date = true
+			}
+		}
+		fields = append(fields, f)
+	}
+	recordFieldsCache.Store(t, fields)
+	return fields
+}
+
+// structValue returns the struct v is or points to.
+func structValue(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("expected a struct, got %T", v)
+	}
+	return rv, nil
+}
+
+// RecordFields returns the names of the Odoo fields of the struct v, as
+// given by the odoo tags of its fields.
+func RecordFields(v any) []string {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	fields := recordFields(t)
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+// DecodeRecord stores the values of a record, as returned by Read and
+// SearchRead, in the fields of the struct v points to, matching them by
+// their odoo tags. false, Odoo's empty value, sets the zero value. Many2One
+// fields take [id, name] pairs, []int fields x2many id lists, time.Time
+// fields dates and datetimes, and string kinds, including selection types,
+// text values.
+func DecodeRecord(record map[string]any, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode record: expected a pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+	for _, f := range recordFields(rv.Type()) {
+		value, ok := record[f.name]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(rv.Field(f.index), value); err != nil {
+			return fmt.Errorf("decode record: field %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// decodeValue stores the field value v in dst.
+func decodeValue(dst reflect.Value, v any) error {
+	if b, ok := v.(bool); v == nil || ok && !b && dst.Kind() != reflect.Bool {
+		dst.SetZero()
+		return nil
+	}
+	switch dst.Type() {
+	case many2OneType:
+		if pair, ok := v.([]any); ok && len(pair) == 2 {
+			if id, ok := toFloat(pair[0]); ok {
+				name, _ := pair[1].(string)
+				dst.Set(reflect.ValueOf(Many2One{ID: int(id), Name: name}))
+				return nil
+			}
+		}
+		if id, ok := toFloat(v); ok {
+			dst.Set(reflect.ValueOf(Many2One{ID: int(id)}))
+			return nil
+		}
+	case timeType:
+		if s, ok := v.(string); ok {
+			for _, layout := range []string{DatetimeFormat, DateFormat} {
+				if t, err := time.Parse(layout, s); err == nil {
+					dst.Set(reflect.ValueOf(t))
+					return nil
+				}
+			}
+		}
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		if s, ok := v.(string); ok {
+			dst.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		if b, ok := v.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		if n, ok := toFloat(v); ok {
+			dst.SetInt(int64(n))
+			return nil
+		}
+	case reflect.Float64, reflect.Float32:
+		if n, ok := toFloat(v); ok {
+			dst.SetFloat(n)
+			return nil
+		}
+	case reflect.Slice:
+		if list, ok := v.([]any); ok && dst.Type().Elem().Kind() == reflect.Int {
+			ids := reflect.MakeSlice(dst.Type(), len(list), len(list))
+			for i, x := range list {
+				id, ok := toFloat(x)
+				if !ok {
+					return fmt.Errorf("cannot decode %T into %s", x, dst.Type().Elem())
+				}
+				ids.Index(i).SetInt(int64(id))
+			}
+			dst.Set(ids)
+			return nil
+		}
+	case reflect.Interface:
+		if reflect.TypeOf(v).AssignableTo(dst.Type()) {
+			dst.Set(reflect.ValueOf(v))
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot decode %T into %s", v, dst.Type())
+}
+
+// EncodeRecord returns the values of the struct v for Create and Write,
+// keyed by the Odoo field names of its odoo tags. It encodes the named
+// fields, or when none are named the fields that are neither zero, nor
+// readonly, nor id. Zero values encode as false, Many2One values as their
+// id, []int values as a command replacing the x2many ids, and time.Time
+// values as UTC dates or datetimes.
+func EncodeRecord(v any, fields ...string) (map[string]any, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode record: %w", err)
+	}
+	byName := map[string]recordField{}
+	for _, f := range recordFields(rv.Type()) {
+		byName[f.name] = f
+	}
+	values := map[string]any{}
+	if len(fields) > 0 {
+		for _, name := range fields {
+			f, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("encode record: field %s not found in %T", name, v)
+			}
+			values[name] = encodeValue(rv.Field(f.index), f)
+		}
+		return values, nil
+	}
+	for name, f := range byName {
+		if f.readonly || name == "id" || rv.Field(f.index).IsZero() {
+			continue
+		}
+		values[name] = encodeValue(rv.Field(f.index), f)
+	}
+	return values, nil
+}
+
+// encodeValue returns the field value of v.
+func encodeValue(v reflect.Value, f recordField) any {
+	switch t := v.Interface().(type) {
+	case Many2One:
+		if t.ID == 0 {
+			return false
+		}
+		return t.ID
+	case time.Time:
+		switch {
+		case t.IsZero():
+			return false
+		case f.date:
+			return t.Format(DateFormat)
+		}
+		return t.UTC().Format(DatetimeFormat)
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Int {
+			ids := make([]any, v.Len())
+			for i := range ids {
+				ids[i] = int(v.Index(i).Int())
+			}
+			return []any{[]any{6, 0, ids}}
+		}
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return int(v.Int())
+	case reflect.Float64, reflect.Float32:
+		return v.Float()
+	}
+	if v.IsZero() {
+		return false
+	}
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return v.Interface()
+}
+
+// decodeRecords decodes records into values of type T.
+func decodeRecords[T any](model string, records []map[string]any) ([]T, error) {
+	out := make([]T, len(records))
+	for i, rec := range records {
+		if err := DecodeRecord(rec, &out[i]); err != nil {
+			return nil, fmt.Errorf("%s(%v): %w", model, rec["id"], err)
+		}
+	}
+	return out, nil
+}
+
+// SearchReadAs is SearchRead decoding the records into the struct type T,
+// reading the fields its odoo tags name.
+func SearchReadAs[T any](ctx context.Context, o Odoo, model string, offset int, limit int, filters ...any) ([]T, error) {
+	var zero T
+	records, err := o.SearchRead(ctx, model, offset, limit, RecordFields(zero), filters...)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRecords[T](model, records)
+}
+
+// ReadAs is Read decoding the records into the struct type T, reading the
+// fields its odoo tags name.
+func ReadAs[T any](ctx context.Context, o Odoo, model string, ids []int) ([]T, error) {
+	var zero T
+	records, err := o.Read(ctx, model, ids, RecordFields(zero)...)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRecords[T](model, records)
+}
@@ -0,0 +1,128 @@
+package odoorpc_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppreeper/odoorpc"
+)
+
+type state string
+
+type order struct {
+	ID        int              `odoo:"id,readonly"`
+	Name      string           `odoo:"name"`
+	State     state            `odoo:"state"`
+	Amount    float64          `odoo:"amount_total"`
+	Locked    bool             `odoo:"locked"`
+	Partner   odoorpc.Many2One `odoo:"partner_id"`
+	LineIDs   []int            `odoo:"order_line"`
+	DateOrder time.Time        `odoo:"date_order"`
+	Validity  time.Time        `odoo:"validity_date,date"`
+	Ref       string           `odoo:"client_order_ref,readonly"`
+	Extra     any              `odoo:"extra"`
+	Ignored   string
+}
+
+func TestDecodeRecord(t *testing.T) {
+	t.Parallel()
+	want := order{
+		ID:        7,
+		Name:      "S00007",
+		State:     "sale",
+		Amount:    99.5,
+		Locked:    true,
+		Partner:   odoorpc.Many2One{ID: 3, Name: "Acme"},
+		LineIDs:   []int{11, 12},
+		DateOrder: time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC),
+		Validity:  time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		Extra:     map[string]any{"k": "v"},
+	}
+	// JSON-RPC numbers are float64, XML-RPC numbers int64.
+	for _, number := range []func(int) any{
+		func(n int) any { return float64(n) },
+		func(n int) any { return int64(n) },
+	} {
+		rec := map[string]any{
+			"id":               number(7),
+			"name":             "S00007",
+			"state":            "sale",
+			"amount_total":     99.5,
+			"locked":           true,
+			"partner_id":       []any{number(3), "Acme"},
+			"order_line":       []any{number(11), number(12)},
+			"date_order":       "2025-01-02 15:04:05",
+			"validity_date":    "2025-02-01",
+			"client_order_ref": false,
+			"extra":            map[string]any{"k": "v"},
+		}
+		got := order{Ref: "stale", Ignored: "kept"}
+		if err := odoorpc.DecodeRecord(rec, &got); err != nil {
+			t.Fatal(err)
+		}
+		want.Ignored = "kept"
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DecodeRecord = %+v, want %+v", got, want)
+		}
+	}
+
+	var o order
+	if err := odoorpc.DecodeRecord(map[string]any{"partner_id": "Acme"}, &o); err == nil || !strings.Contains(err.Error(), "field partner_id") {
+		t.Errorf("decoding a string into a Many2One: %v", err)
+	}
+	if err := odoorpc.DecodeRecord(map[string]any{}, o); err == nil {
+		t.Error("decoding into a struct value: expected an error")
+	}
+	if got := odoorpc.RecordFields(&o); len(got) != 11 || got[0] != "id" || got[10] != "extra" {
+		t.Errorf("RecordFields = %v", got)
+	}
+}
+
+func TestEncodeRecord(t *testing.T) {
+	t.Parallel()
+	o := order{
+		ID:        7,
+		Name:      "S00007",
+		Partner:   odoorpc.Many2One{ID: 3, Name: "Acme"},
+		LineIDs:   []int{11},
+		DateOrder: time.Date(2025, 1, 2, 16, 4, 5, 0, time.FixedZone("CET", 3600)),
+		Validity:  time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		Ref:       "PO-1",
+	}
+	got, err := odoorpc.EncodeRecord(&o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"name":          "S00007",
+		"partner_id":    3,
+		"order_line":    []any{[]any{6, 0, []any{11}}},
+		"date_order":    "2025-01-02 15:04:05",
+		"validity_date": "2025-02-01",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EncodeRecord = %v, want %v", got, want)
+	}
+
+	// Named fields are encoded even when zero or readonly.
+	got, err = odoorpc.EncodeRecord(order{Ref: "PO-1"}, "state", "partner_id", "locked", "amount_total", "client_order_ref", "date_order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]any{
+		"state":            false,
+		"partner_id":       false,
+		"locked":           false,
+		"amount_total":     0.0,
+		"client_order_ref": "PO-1",
+		"date_order":       false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EncodeRecord with fields = %v, want %v", got, want)
+	}
+	if _, err := odoorpc.EncodeRecord(o, "nope"); err == nil {
+		t.Error("encoding an unknown field: expected an error")
+	}
+}
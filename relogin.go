@@ -0,0 +1,95 @@
+package odoorpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// IsAuthError reports whether err is an authentication failure reported by
+// the server: rejected credentials, a revoked API key or an expired session.
+// Access errors on records, which logging in again cannot fix, are not
+// authentication failures.
+func IsAuthError(err error) bool {
+	var ae interface{ AuthFailure() bool }
+	return errors.As(err, &ae) && ae.AuthFailure()
+}
+
+// LoginGroup coalesces the re-logins of one client, so that many calls
+// failing at once with an authentication error trigger a single Login. The
+// zero value is ready to use.
+type LoginGroup struct {
+	mu       sync.Mutex
+	gen      uint64
+	inflight *loginCall
+	last     error
+}
+
+// loginCall is a Login in progress.
+type loginCall struct {
+	done chan struct{}
+	err  error
+}
+
+// Generation returns a counter incremented by every completed re-login. Take
+// it before a call and pass it to Relogin if the call fails.
+func (g *LoginGroup) Generation() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.gen
+}
+
+// Relogin runs login unless a re-login has completed since generation gen,
+// in which case it returns that re-login's error without running login again.
+// Callers arriving while login is running wait for it and share its result.
+func (g *LoginGroup) Relogin(ctx context.Context, gen uint64, login func(context.Context) error) error {
+	g.mu.Lock()
+	if g.gen != gen {
+		err := g.last
+		g.mu.Unlock()
+		return err
+	}
+	if c := g.inflight; c != nil {
+		g.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	c := &loginCall{done: make(chan struct{})}
+	g.inflight = c
+	g.mu.Unlock()
+
+	c.err = login(ctx)
+
+	g.mu.Lock()
+	g.gen++
+	g.last = c.err
+	g.inflight = nil
+	g.mu.Unlock()
+	close(c.done)
+	return c.err
+}
+
+// Invoke is the package-level Invoke for clients with automatic re-login:
+// when the call fails with an authentication error, it runs login through
+// Relogin and invokes the call once more with info.Attempt incremented. A nil
+// group invokes the call without re-login.
+func (g *LoginGroup) Invoke(ctx context.Context, login func(context.Context) error, info CallInfo, final Invoker, interceptors ...Interceptor) (any, error) {
+	if g == nil {
+		return Invoke(ctx, info, final, interceptors...)
+	}
+	gen := g.Generation()
+	res, err := Invoke(ctx, info, final, interceptors...)
+	if err == nil || !IsAuthError(err) {
+		return res, err
+	}
+	if err := g.Relogin(ctx, gen, login); err != nil {
+		return nil, fmt.Errorf("relogin failed: %w", err)
+	}
+	info.Attempt++
+	return Invoke(ctx, info, final, interceptors...)
+}
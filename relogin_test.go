@@ -0,0 +1,140 @@
+package odoorpc_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	odoojrpc "github.com/ppreeper/odoorpc/odoojrpc"
+	odoojson "github.com/ppreeper/odoorpc/odoojson"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+	odooxmlrpc "github.com/ppreeper/odoorpc/odooxmlrpc"
+)
+
+// callCounter is an interceptor counting logins and retried calls.
+type callCounter struct {
+	logins, retries atomic.Int64
+}
+
+func (c *callCounter) intercept(ctx context.Context, info odoorpc.CallInfo, next odoorpc.Invoker) (any, error) {
+	if info.Method == "login" || info.Method == "authenticate" {
+		c.logins.Add(1)
+	}
+	if info.Attempt > 0 {
+		c.retries.Add(1)
+	}
+	return next(ctx, info)
+}
+
+func TestAutoRelogin(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var jc, xc callCounter
+	clients := map[string]odoorpc.Odoo{
+		odoorpc.TransportJSONRPC: odoojrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+			WithInterceptors(jc.intercept).WithAutoRelogin(true),
+		odoorpc.TransportXMLRPC: odooxmlrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+			WithInterceptors(xc.intercept).WithAutoRelogin(true),
+	}
+	counters := map[string]*callCounter{odoorpc.TransportJSONRPC: &jc, odoorpc.TransportXMLRPC: &xc}
+	plain := odoojrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+		WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password)
+	for _, o := range append([]odoorpc.Odoo{plain}, clients[odoorpc.TransportJSONRPC], clients[odoorpc.TransportXMLRPC]) {
+		if err := o.Login(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The user is recreated with a new id, invalidating the logged-in uid.
+	srv.UID = 7
+
+	if _, err := plain.Count(ctx, "res.partner"); !odoorpc.IsAuthError(err) {
+		t.Errorf("without auto relogin: expected an auth error, got %v", err)
+	}
+	for name, o := range clients {
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if n, err := o.Count(ctx, "res.partner"); err != nil || n != 1 {
+					t.Errorf("%s count: got %d, %v", name, n, err)
+				}
+			}()
+		}
+		wg.Wait()
+		c := counters[name]
+		if got := c.logins.Load(); got != 2 {
+			t.Errorf("%s: got %d logins, want the initial one and a single re-login", name, got)
+		}
+		if c.retries.Load() == 0 {
+			t.Errorf("%s: no call was retried", name)
+		}
+	}
+
+	// Rotated credentials fail the re-login.
+	srv.UID, srv.Password = 8, "rotated"
+	for name, o := range clients {
+		if _, err := o.Count(ctx, "res.partner"); err == nil || !strings.Contains(err.Error(), "relogin failed") {
+			t.Errorf("%s: expected relogin failure, got %v", name, err)
+		}
+	}
+}
+
+// unauthorizedOnce answers the first request with 401 Unauthorized.
+type unauthorizedOnce struct {
+	done atomic.Bool
+}
+
+func (u *unauthorizedOnce) RoundTrip(req *http.Request) (*http.Response, error) {
+	if u.done.CompareAndSwap(false, true) {
+		return &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Status:     "401 Unauthorized",
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"name": "odoo.exceptions.AccessDenied"}`)),
+			Request:    req,
+		}, nil
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestAutoReloginJSON2(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+
+	var c callCounter
+	o := odoojson.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+		WithDatabase(srv.Database).WithAPIKey(srv.APIKey).
+		WithTransport(&unauthorizedOnce{}).WithInterceptors(c.intercept).WithAutoRelogin(true)
+	if err := o.Login(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Count(ctx, "res.partner"); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if c.retries.Load() != 1 {
+		t.Errorf("retries: got %d, want 1", c.retries.Load())
+	}
+
+	o = odoojson.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+		WithDatabase(srv.Database).WithAPIKey("revoked").WithAutoRelogin(true)
+	if _, err := o.Count(ctx, "res.partner"); !odoorpc.IsAuthError(err) {
+		t.Errorf("revoked key: expected an auth error after the retry, got %v", err)
+	}
+}
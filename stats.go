@@ -0,0 +1,137 @@
+package odoorpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+// CallStats holds wire measurements of a single call. Invoke attaches a fresh
+// CallStats to the context of every call; interceptors read it with
+// StatsFromContext after next returns. The counters are filled by
+// InstrumentTransport, which every client installs around its HTTP transport.
+//
+// A nil *CallStats is valid and reports zero.
+type CallStats struct {
+	requestBytes  atomic.Int64
+	responseBytes atomic.Int64
+}
+
+// RequestBytes returns the number of request body bytes sent.
+func (s *CallStats) RequestBytes() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.requestBytes.Load()
+}
+
+// ResponseBytes returns the number of response body bytes read.
+func (s *CallStats) ResponseBytes() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.responseBytes.Load()
+}
+
+type statsKey struct{}
+
+// StatsFromContext returns the CallStats of the call ctx belongs to, or nil.
+func StatsFromContext(ctx context.Context) *CallStats {
+	s, _ := ctx.Value(statsKey{}).(*CallStats)
+	return s
+}
+
+// withStats returns a copy of ctx carrying a fresh CallStats.
+func withStats(ctx context.Context) context.Context {
+	return context.WithValue(ctx, statsKey{}, &CallStats{})
+}
+
+// InstrumentTransport wraps next (http.DefaultTransport when nil) so that
+// request and response body sizes are added to the CallStats of the request
+// context and the W3C traceparent of the context, if any, is sent with the
+// request. Every client installs it around its HTTP transport.
+func InstrumentTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if _, ok := next.(*instrumentTransport); ok {
+		return next
+	}
+	return &instrumentTransport{next: next}
+}
+
+type instrumentTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *instrumentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if tp := TraceParentFromContext(req.Context()); tp != "" && req.Header.Get(traceParentHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(traceParentHeader, tp)
+	}
+	stats := StatsFromContext(req.Context())
+	if stats == nil {
+		return t.next.RoundTrip(req)
+	}
+	if req.ContentLength > 0 {
+		stats.requestBytes.Add(req.ContentLength)
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, n: &stats.responseBytes}
+	return resp, nil
+}
+
+// CloseIdleConnections closes idle connections of the wrapped transport.
+func (t *instrumentTransport) CloseIdleConnections() {
+	if c, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+// countingBody counts the bytes read from a response body.
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+// ErrorClass returns a short classification of err for logs and metrics: the
+// Odoo exception class for server errors (e.g.
+// "odoo.exceptions.AccessError"), "canceled" or "timeout" for context and
+// network deadlines, "network" for other network errors and "error"
+// otherwise. It returns "" for a nil error.
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	var oe interface{ OdooException() string }
+	if errors.As(err, &oe) && oe.OdooException() != "" {
+		return oe.OdooException()
+	}
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	var ne net.Error
+	if errors.As(err, &ne) {
+		if ne.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+	return "error"
+}
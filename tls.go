@@ -0,0 +1,139 @@
+package odoorpc
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+)
+
+// TLSOptions configures TLS for connections to Odoo servers behind internal
+// CAs or mTLS gateways. Set it on a client with WithTLS.
+type TLSOptions struct {
+	// CAFile is a PEM bundle of CA certificates trusted to sign the server
+	// certificate, in addition to RootCAs.
+	CAFile string
+	// RootCAs is a pool of trusted CA certificates. When neither CAFile nor
+	// RootCAs is set the system pool is used.
+	RootCAs *x509.CertPool
+	// CertFile and KeyFile are a PEM client certificate and its private key
+	// presented to the server.
+	CertFile string
+	KeyFile  string
+	// Certificates are client certificates presented to the server, in
+	// addition to CertFile.
+	Certificates []tls.Certificate
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS13.
+	// Defaults to tls.VersionTLS12.
+	MinVersion uint16
+	// ServerName overrides the name the server certificate is verified
+	// against. Defaults to the hostname.
+	ServerName string
+	// PinnedSPKI are base64-encoded SHA-256 hashes of the
+	// SubjectPublicKeyInfo of certificates in the server chain, as in
+	// "openssl x509 -pubkey | openssl pkey -pubin -outform der | openssl
+	// dgst -sha256 -binary | base64". When set, a connection is accepted only
+	// if a certificate in the chain matches one of them.
+	PinnedSPKI []string
+	// InsecureSkipVerify disables verification of the server certificate.
+	// It is meant for development servers with self-signed certificates;
+	// PinnedSPKI is still enforced.
+	InsecureSkipVerify bool
+}
+
+// Config validates the options and returns the tls.Config they describe.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.MinVersion != 0 {
+		switch o.MinVersion {
+		case tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+			cfg.MinVersion = o.MinVersion
+		default:
+			return nil, fmt.Errorf("unknown minimum version %#04x", o.MinVersion)
+		}
+	}
+
+	if o.CAFile != "" || o.RootCAs != nil {
+		pool := x509.NewCertPool()
+		if o.RootCAs != nil {
+			pool = o.RootCAs.Clone()
+		}
+		if o.CAFile != "" {
+			pem, err := os.ReadFile(o.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("read CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("CA file %s contains no PEM certificates", o.CAFile)
+			}
+		}
+		cfg.RootCAs = pool
+	}
+
+	cfg.Certificates = slices.Clone(o.Certificates)
+	switch {
+	case o.CertFile != "" && o.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	case o.CertFile != "":
+		return nil, errors.New("client certificate requires a key file")
+	case o.KeyFile != "":
+		return nil, errors.New("client key requires a certificate file")
+	}
+
+	if len(o.PinnedSPKI) > 0 {
+		pins := make([][]byte, 0, len(o.PinnedSPKI))
+		for _, pin := range o.PinnedSPKI {
+			sum, err := base64.StdEncoding.DecodeString(pin)
+			if err != nil || len(sum) != sha256.Size {
+				return nil, fmt.Errorf("invalid SPKI pin %q: want a base64 SHA-256 hash", pin)
+			}
+			pins = append(pins, sum)
+		}
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			for _, cert := range cs.PeerCertificates {
+				sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+				if slices.ContainsFunc(pins, func(pin []byte) bool { return string(pin) == string(sum[:]) }) {
+					return nil
+				}
+			}
+			return errors.New("odoorpc: server certificate does not match any pinned SPKI hash")
+		}
+	}
+	return cfg, nil
+}
+
+// SPKIHash returns the base64-encoded SHA-256 hash of the
+// SubjectPublicKeyInfo of cert, in the form used by TLSOptions.PinnedSPKI.
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// TransportWithTLS returns a copy of rt (http.DefaultTransport when nil)
+// that uses config for TLS connections. rt must be an *http.Transport.
+func TransportWithTLS(rt http.RoundTripper, config *tls.Config) (http.RoundTripper, error) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("TLS options require an *http.Transport, got %T", rt)
+	}
+	t = t.Clone()
+	t.TLSClientConfig = config
+	return t, nil
+}
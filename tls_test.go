@@ -0,0 +1,182 @@
+package odoorpc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppreeper/odoorpc"
+	odoojrpc "github.com/ppreeper/odoorpc/odoojrpc"
+	odoojson "github.com/ppreeper/odoorpc/odoojson"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+	odooxmlrpc "github.com/ppreeper/odoorpc/odooxmlrpc"
+)
+
+// writePEM writes a PEM block of the given type to a file in dir.
+func writePEM(t *testing.T, dir, name, typ string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// clientCertificate writes a self-signed client certificate and its key to
+// dir and returns their paths.
+func clientCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "odoorpc test client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writePEM(t, dir, "client.crt", "CERTIFICATE", der), writePEM(t, dir, "client.key", "EC PRIVATE KEY", keyDER)
+}
+
+// tlsClients returns one client per transport with schema https and the
+// given TLS options, pointed at ts.
+func tlsClients(t *testing.T, srv *odoorpctest.Server, ts *httptest.Server, opts odoorpc.TLSOptions) map[string]odoorpc.Odoo {
+	t.Helper()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(u.Port())
+	return map[string]odoorpc.Odoo{
+		odoorpc.TransportJSONRPC: odoojrpc.NewOdoo().WithSchema("https").WithHostname(u.Hostname()).WithPort(port).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+			WithTLS(opts),
+		odoorpc.TransportXMLRPC: odooxmlrpc.NewOdoo().WithSchema("https").WithHostname(u.Hostname()).WithPort(port).
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+			WithTLS(opts),
+		odoorpc.TransportJSON2: odoojson.NewOdoo().WithSchema("https").WithHostname(u.Hostname()).WithPort(port).
+			WithDatabase(srv.Database).WithAPIKey(srv.APIKey).
+			WithTLS(opts),
+	}
+}
+
+// loginAndCount logs o in and counts partners.
+func loginAndCount(o odoorpc.Odoo) error {
+	ctx := context.Background()
+	if err := o.Login(ctx); err != nil {
+		return err
+	}
+	_, err := o.Count(ctx, "res.partner")
+	return err
+}
+
+func TestTLSOptions(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+
+	ts := httptest.NewUnstartedServer(srv.Config.Handler)
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0) // expected handshake failures
+	ts.StartTLS()
+	defer ts.Close()
+
+	caFile := writePEM(t, dir, "ca.pem", "CERTIFICATE", ts.Certificate().Raw)
+	certFile, keyFile := clientCertificate(t, dir)
+	pin := odoorpc.SPKIHash(ts.Certificate())
+	wrongPin := odoorpc.SPKIHash(&x509.Certificate{RawSubjectPublicKeyInfo: []byte("other")})
+
+	tests := []struct {
+		name    string
+		opts    odoorpc.TLSOptions
+		wantErr string
+	}{
+		{"ca and client certificate", odoorpc.TLSOptions{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, MinVersion: tls.VersionTLS13}, ""},
+		{"pinned", odoorpc.TLSOptions{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, PinnedSPKI: []string{wrongPin, pin}}, ""},
+		{"insecure skip verify", odoorpc.TLSOptions{InsecureSkipVerify: true, CertFile: certFile, KeyFile: keyFile}, ""},
+		{"unknown authority", odoorpc.TLSOptions{CertFile: certFile, KeyFile: keyFile}, "certificate"},
+		{"pin mismatch", odoorpc.TLSOptions{InsecureSkipVerify: true, CertFile: certFile, KeyFile: keyFile, PinnedSPKI: []string{wrongPin}}, "pinned SPKI"},
+		{"missing client certificate", odoorpc.TLSOptions{CAFile: caFile}, "certificate"},
+	}
+	for _, tt := range tests {
+		for transport, o := range tlsClients(t, srv, ts, tt.opts) {
+			err := loginAndCount(o)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("%s/%s: %v", tt.name, transport, err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("%s/%s: expected error containing %q, got %v", tt.name, transport, tt.wantErr, err)
+			}
+		}
+	}
+}
+
+func TestTLSOptionsValidation(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	certFile, _ := clientCertificate(t, dir)
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    odoorpc.TLSOptions
+		wantErr string
+	}{
+		{"missing CA file", odoorpc.TLSOptions{CAFile: filepath.Join(dir, "missing.pem")}, "read CA file"},
+		{"CA file without certificates", odoorpc.TLSOptions{CAFile: empty}, "no PEM certificates"},
+		{"certificate without key", odoorpc.TLSOptions{CertFile: certFile}, "requires a key file"},
+		{"key without certificate", odoorpc.TLSOptions{KeyFile: certFile}, "requires a certificate file"},
+		{"bad pin", odoorpc.TLSOptions{PinnedSPKI: []string{"abc"}}, "invalid SPKI pin"},
+		{"bad version", odoorpc.TLSOptions{MinVersion: 0x0200}, "unknown minimum version"},
+	}
+	for _, tt := range tests {
+		if _, err := tt.opts.Config(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	// Clients report invalid options, and TLS over plain http, when the URL
+	// is built.
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	for transport, o := range tlsClients(t, srv, srv.Server, odoorpc.TLSOptions{CertFile: certFile}) {
+		if err := o.Login(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid tls") {
+			t.Errorf("%s: expected invalid tls error, got %v", transport, err)
+		}
+	}
+	plain := odoojrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+		WithTLS(odoorpc.TLSOptions{InsecureSkipVerify: true})
+	if err := plain.Login(context.Background()); err == nil || !strings.Contains(err.Error(), "https schema") {
+		t.Errorf("expected https schema error, got %v", err)
+	}
+}
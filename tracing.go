@@ -0,0 +1,89 @@
+package odoorpc
+
+import (
+	"context"
+	"regexp"
+	"time"
+)
+
+// traceParentHeader is the W3C Trace Context request header.
+const traceParentHeader = "traceparent"
+
+// traceParentPattern matches a version 00 W3C traceparent value.
+var traceParentPattern = regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// Tracer starts a span for every RPC call. It is the hook for adapting a
+// tracing library such as OpenTelemetry without the library depending on it.
+type Tracer interface {
+	// Start begins a span for the call described by info. The returned
+	// context is passed down the interceptor chain and to the transport.
+	Start(ctx context.Context, info CallInfo) (context.Context, Span)
+}
+
+// Span is a call span started by a Tracer.
+type Span interface {
+	// TraceParent returns the W3C traceparent header value identifying the
+	// span, e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	// or "" to send none.
+	TraceParent() string
+	// End finishes the span with the outcome of the call.
+	End(result CallResult)
+}
+
+// SpanAttributes returns the attributes of a finished call keyed by their
+// conventional names: rpc.system, rpc.service, rpc.method, odoo.transport,
+// odoo.model, odoo.records, odoo.request_bytes, odoo.response_bytes and
+// error.type. Empty values are omitted.
+func SpanAttributes(info CallInfo, result CallResult) map[string]any {
+	attrs := map[string]any{
+		"rpc.system":          "odoo",
+		"rpc.method":          info.Method,
+		"odoo.transport":      info.Transport,
+		"odoo.records":        result.Records,
+		"odoo.request_bytes":  result.RequestBytes,
+		"odoo.response_bytes": result.ResponseBytes,
+	}
+	if info.Service != "" {
+		attrs["rpc.service"] = info.Service
+	}
+	if info.Model != "" {
+		attrs["odoo.model"] = info.Model
+	}
+	if result.ErrorClass != "" {
+		attrs["error.type"] = result.ErrorClass
+	}
+	return attrs
+}
+
+// TraceInterceptor returns an interceptor that wraps every call in a span
+// started by tracer and propagates the span's traceparent to the server.
+func TraceInterceptor(tracer Tracer) Interceptor {
+	return func(ctx context.Context, info CallInfo, next Invoker) (any, error) {
+		ctx, span := tracer.Start(ctx, info)
+		if tp := span.TraceParent(); tp != "" {
+			ctx = ContextWithTraceParent(ctx, tp)
+		}
+		start := time.Now()
+		res, err := next(ctx, info)
+		span.End(newCallResult(ctx, res, err, time.Since(start)))
+		return res, err
+	}
+}
+
+type traceParentKey struct{}
+
+// ContextWithTraceParent returns a copy of ctx whose RPC requests carry the
+// W3C traceparent header tp. Invalid values are ignored.
+func ContextWithTraceParent(ctx context.Context, tp string) context.Context {
+	if !traceParentPattern.MatchString(tp) {
+		return ctx
+	}
+	return context.WithValue(ctx, traceParentKey{}, tp)
+}
+
+// TraceParentFromContext returns the traceparent set by
+// ContextWithTraceParent, or "".
+func TraceParentFromContext(ctx context.Context) string {
+	tp, _ := ctx.Value(traceParentKey{}).(string)
+	return tp
+}
@@ -0,0 +1,134 @@
+package odoorpc_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	odoojrpc "github.com/ppreeper/odoorpc/odoojrpc"
+	odoojson "github.com/ppreeper/odoorpc/odoojson"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+	odooxmlrpc "github.com/ppreeper/odoorpc/odooxmlrpc"
+)
+
+// fakeTracer records finished spans.
+type fakeTracer struct {
+	mu    sync.Mutex
+	next  int
+	spans []*fakeSpan
+}
+
+type fakeSpan struct {
+	tracer *fakeTracer
+	info   odoorpc.CallInfo
+	tp     string
+	result *odoorpc.CallResult
+}
+
+func (t *fakeTracer) Start(ctx context.Context, info odoorpc.CallInfo) (context.Context, odoorpc.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.next++
+	span := &fakeSpan{tracer: t, info: info, tp: fmt.Sprintf("00-%032x-%016x-01", 1, t.next)}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (s *fakeSpan) TraceParent() string { return s.tp }
+
+func (s *fakeSpan) End(result odoorpc.CallResult) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.result = &result
+}
+
+// headerRecorder records the traceparent header of every request.
+type headerRecorder struct {
+	mu   sync.Mutex
+	seen []string
+}
+
+func (h *headerRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	h.mu.Lock()
+	h.seen = append(h.seen, req.Header.Get("traceparent"))
+	h.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestTracerSpansAndPropagation(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "Alice"}, map[string]any{"name": "Bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, transport := range []string{odoorpc.TransportJSONRPC, odoorpc.TransportXMLRPC, odoorpc.TransportJSON2} {
+		tracer, headers := &fakeTracer{}, &headerRecorder{}
+		var o odoorpc.Odoo
+		switch transport {
+		case odoorpc.TransportJSONRPC:
+			o = odoojrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+				WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+				WithTransport(headers).WithTracer(tracer)
+		case odoorpc.TransportXMLRPC:
+			o = odooxmlrpc.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+				WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password).
+				WithTransport(headers).WithTracer(tracer)
+		case odoorpc.TransportJSON2:
+			o = odoojson.NewOdoo().WithHostname(srv.Hostname()).WithPort(srv.Port()).
+				WithDatabase(srv.Database).WithAPIKey(srv.APIKey).
+				WithTransport(headers).WithTracer(tracer)
+		}
+		if err := o.Login(ctx); err != nil {
+			t.Fatalf("%s login: %v", transport, err)
+		}
+		if _, err := o.Search(ctx, "res.partner"); err != nil {
+			t.Fatalf("%s search: %v", transport, err)
+		}
+		if _, err := o.Read(ctx, "res.partner", []int{999}); err == nil {
+			t.Fatalf("%s read: expected error", transport)
+		}
+
+		if len(tracer.spans) != len(headers.seen) {
+			t.Fatalf("%s: %d spans for %d requests", transport, len(tracer.spans), len(headers.seen))
+		}
+		for i, span := range tracer.spans {
+			if span.result == nil {
+				t.Errorf("%s: span %s was not ended", transport, span.info.Method)
+			}
+			if headers.seen[i] != span.tp {
+				t.Errorf("%s: request %d traceparent %q, want %q", transport, i, headers.seen[i], span.tp)
+			}
+		}
+
+		search, read := tracer.spans[len(tracer.spans)-2], tracer.spans[len(tracer.spans)-1]
+		attrs := odoorpc.SpanAttributes(search.info, *search.result)
+		if attrs["odoo.model"] != "res.partner" || attrs["rpc.method"] != "search" || attrs["odoo.transport"] != transport {
+			t.Errorf("%s search attributes: %v", transport, attrs)
+		}
+		if search.result.Records != 2 || search.result.ResponseBytes == 0 || search.result.RequestBytes == 0 {
+			t.Errorf("%s search result: %+v", transport, *search.result)
+		}
+		if read.result.Err == nil || read.result.ErrorClass == "" {
+			t.Errorf("%s read result: %+v", transport, *read.result)
+		}
+	}
+}
+
+func TestContextWithTraceParent(t *testing.T) {
+	t.Parallel()
+	const tp = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := odoorpc.ContextWithTraceParent(context.Background(), tp)
+	if got := odoorpc.TraceParentFromContext(ctx); got != tp {
+		t.Errorf("got %q, want %q", got, tp)
+	}
+	ctx = odoorpc.ContextWithTraceParent(context.Background(), "not-a-traceparent")
+	if got := odoorpc.TraceParentFromContext(ctx); got != "" {
+		t.Errorf("invalid traceparent stored: %q", got)
+	}
+}
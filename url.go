@@ -0,0 +1,89 @@
+package odoorpc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultPorts are the ports omitted from URLs of each schema.
+var defaultPorts = map[string]int{"http": 80, "https": 443}
+
+// ParseBaseURL splits a server base URL such as
+// "https://erp.example.com/odoo/" into its schema, hostname, port and path
+// prefix. The port defaults to 80 for http and 443 for https. The prefix has
+// no leading or trailing slash and is empty when Odoo is served at the root.
+func ParseBaseURL(raw string) (schema, hostname string, port int, prefix string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", 0, "", fmt.Errorf("invalid base url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", 0, "", fmt.Errorf("invalid base url %q: schema must be http or https", raw)
+	}
+	if u.Hostname() == "" {
+		return "", "", 0, "", fmt.Errorf("invalid base url %q: missing hostname", raw)
+	}
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", "", 0, "", fmt.Errorf("invalid base url %q: must not contain credentials, a query or a fragment", raw)
+	}
+	port = defaultPorts[u.Scheme]
+	if p := u.Port(); p != "" {
+		if port, err = strconv.Atoi(p); err != nil || port < 1 || port > 65535 {
+			return "", "", 0, "", fmt.Errorf("invalid base url %q: port must be 1-65535", raw)
+		}
+	}
+	return u.Scheme, u.Hostname(), port, strings.Trim(u.Path, "/"), nil
+}
+
+// JoinURL builds the URL of endpoint on a server, e.g.
+// JoinURL("https", "erp.example.com", 443, "odoo", "jsonrpc/") returns
+// "https://erp.example.com/odoo/jsonrpc/". The port is omitted when it is
+// the default for schema, and IPv6 hostnames are bracketed.
+func JoinURL(schema, hostname string, port int, prefix, endpoint string) string {
+	host := hostname
+	if port != defaultPorts[schema] {
+		host = net.JoinHostPort(hostname, strconv.Itoa(port))
+	} else if strings.Contains(hostname, ":") {
+		host = "[" + hostname + "]"
+	}
+	path := "/"
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		path += prefix + "/"
+	}
+	return schema + "://" + host + path + strings.TrimPrefix(endpoint, "/")
+}
+
+// HostTransport wraps next (http.DefaultTransport when nil) so that requests
+// are sent with the given Host header, e.g. to select a database through
+// Odoo's dbfilter while connecting to the server by IP address. For https
+// the certificate is still verified against the URL hostname unless
+// TLSOptions.ServerName is set.
+func HostTransport(next http.RoundTripper, host string) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &hostTransport{next: next, host: host}
+}
+
+type hostTransport struct {
+	next http.RoundTripper
+	host string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Host = t.host
+	return t.next.RoundTrip(req)
+}
+
+// CloseIdleConnections closes idle connections of the wrapped transport.
+func (t *hostTransport) CloseIdleConnections() {
+	if c, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,127 @@
+package odoorpc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	odoojrpc "github.com/ppreeper/odoorpc/odoojrpc"
+	odoojson "github.com/ppreeper/odoorpc/odoojson"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+	odooxmlrpc "github.com/ppreeper/odoorpc/odooxmlrpc"
+)
+
+func TestParseBaseURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		raw      string
+		schema   string
+		hostname string
+		port     int
+		prefix   string
+		wantErr  string
+	}{
+		{"https://erp.example.com/odoo/", "https", "erp.example.com", 443, "odoo", ""},
+		{"http://erp.example.com", "http", "erp.example.com", 80, "", ""},
+		{"http://10.0.0.5:8069/a/b", "http", "10.0.0.5", 8069, "a/b", ""},
+		{"https://[::1]:8443/", "https", "::1", 8443, "", ""},
+		{"ftp://erp.example.com", "", "", 0, "", "schema must be http or https"},
+		{"https:///odoo", "", "", 0, "", "missing hostname"},
+		{"https://erp.example.com:0", "", "", 0, "", "port must be 1-65535"},
+		{"https://erp.example.com/?db=x", "", "", 0, "", "must not contain"},
+		{"https://user:pw@erp.example.com", "", "", 0, "", "must not contain"},
+	}
+	for _, tt := range tests {
+		schema, hostname, port, prefix, err := odoorpc.ParseBaseURL(tt.raw)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.raw, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil || schema != tt.schema || hostname != tt.hostname || port != tt.port || prefix != tt.prefix {
+			t.Errorf("%s: got %q %q %d %q %v", tt.raw, schema, hostname, port, prefix, err)
+		}
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		schema, hostname string
+		port             int
+		prefix, endpoint string
+		want             string
+	}{
+		{"https", "erp.example.com", 443, "odoo", "jsonrpc/", "https://erp.example.com/odoo/jsonrpc/"},
+		{"http", "erp.example.com", 80, "", "xmlrpc/2/", "http://erp.example.com/xmlrpc/2/"},
+		{"http", "localhost", 8069, "", "json/2/", "http://localhost:8069/json/2/"},
+		{"https", "erp.example.com", 80, "/a/b/", "jsonrpc/", "https://erp.example.com:80/a/b/jsonrpc/"},
+		{"http", "::1", 8069, "", "jsonrpc/", "http://[::1]:8069/jsonrpc/"},
+		{"http", "::1", 80, "", "jsonrpc/", "http://[::1]/jsonrpc/"},
+	}
+	for _, tt := range tests {
+		if got := odoorpc.JoinURL(tt.schema, tt.hostname, tt.port, tt.prefix, tt.endpoint); got != tt.want {
+			t.Errorf("JoinURL(%q, %q, %d, %q, %q) = %q, want %q", tt.schema, tt.hostname, tt.port, tt.prefix, tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestBaseURLPrefixAndHost(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	if _, err := srv.Seed("res.partner", map[string]any{"name": "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reverse proxy serving Odoo under /odoo/ that records Host headers.
+	var mu sync.Mutex
+	var hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hosts = append(hosts, r.Host)
+		mu.Unlock()
+		http.StripPrefix("/odoo", srv.Config.Handler).ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+	base := proxy.URL + "/odoo/"
+
+	clients := map[string]odoorpc.Odoo{
+		odoorpc.TransportJSONRPC: odoojrpc.NewOdoo().WithBaseURL(base).WithHost("tenant.example.com").
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password),
+		odoorpc.TransportXMLRPC: odooxmlrpc.NewOdoo().WithBaseURL(base).WithHost("tenant.example.com").
+			WithDatabase(srv.Database).WithUsername(srv.Username).WithPassword(srv.Password),
+		odoorpc.TransportJSON2: odoojson.NewOdoo().WithBaseURL(base).WithHost("tenant.example.com").
+			WithDatabase(srv.Database).WithAPIKey(srv.APIKey),
+	}
+	for name, o := range clients {
+		if err := o.Login(ctx); err != nil {
+			t.Fatalf("%s login: %v", name, err)
+		}
+		if n, err := o.Count(ctx, "res.partner"); err != nil || n != 1 {
+			t.Errorf("%s count: got %d, %v", name, n, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hosts) == 0 {
+		t.Fatal("no requests reached the proxy")
+	}
+	for _, h := range hosts {
+		if h != "tenant.example.com" {
+			t.Errorf("Host header: got %q, want tenant.example.com", h)
+		}
+	}
+
+	bad := odoojrpc.NewOdoo().WithBaseURL("ftp://erp.example.com")
+	if err := bad.Login(ctx); err == nil || !strings.Contains(err.Error(), "invalid base url") {
+		t.Errorf("expected invalid base url error, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package odoorpc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Version is an Odoo server version as reported by the version method of
+// the common service, e.g. "17.0", "saas~17.2" or "18.0+e".
+type Version struct {
+	Major int
+	Minor int
+	// Raw is the server_version string the version was parsed from.
+	Raw string
+}
+
+// versionPattern matches the major and minor numbers of a server_version.
+var versionPattern = regexp.MustCompile(`^(?:saas~)?(\d+)\.(\d+)`)
+
+// ParseVersion parses a server_version string.
+func ParseVersion(s string) (Version, error) {
+	m := versionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid server version %q", s)
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return Version{Major: major, Minor: minor, Raw: s}, nil
+}
+
+// AtLeast reports whether v is major.minor or later.
+func (v Version) AtLeast(major, minor int) bool {
+	return v.Major > major || v.Major == major && v.Minor >= minor
+}
+
+func (v Version) String() string {
+	return v.Raw
+}
+
+// VersionFromInfo parses the result of the version method of the common
+// service, or of the /web/version route.
+func VersionFromInfo(info any) (Version, error) {
+	m, ok := info.(map[string]any)
+	if !ok {
+		return Version{}, fmt.Errorf("version: unexpected response type %T", info)
+	}
+	s, ok := m["server_version"].(string)
+	if !ok {
+		return Version{}, fmt.Errorf("version: missing server_version")
+	}
+	return ParseVersion(s)
+}
@@ -0,0 +1,280 @@
+package odoorpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrXMLIDNotFound is returned by RefID for external ids that do not exist.
+var ErrXMLIDNotFound = errors.New("external id not found")
+
+// XMLRef is the record an external id refers to.
+type XMLRef struct {
+	Model string
+	ID    int
+}
+
+// SplitXMLID splits an external id, "module.name", into its module and name.
+func SplitXMLID(xmlid string) (module, name string, err error) {
+	module, name, ok := strings.Cut(xmlid, ".")
+	if !ok || module == "" || name == "" {
+		return "", "", fmt.Errorf("invalid external id %q: expected module.name", xmlid)
+	}
+	return module, name, nil
+}
+
+// xmlidRecord is the ir.model.data record of an external id.
+type xmlidRecord struct {
+	XMLRef
+	dataID int
+}
+
+// resolveXMLIDs reads the ir.model.data records of xmlids with a single
+// search_read.
+func resolveXMLIDs(ctx context.Context, o Odoo, xmlids []string) (map[string]xmlidRecord, error) {
+	names := map[string][]string{}
+	for _, xmlid := range xmlids {
+		module, name, err := SplitXMLID(xmlid)
+		if err != nil {
+			return nil, err
+		}
+		names[module] = append(names[module], name)
+	}
+	modules := make([]string, 0, len(names))
+	for module := range names {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	refs := map[string]xmlidRecord{}
+	if len(modules) == 0 {
+		return refs, nil
+	}
+	domain := []any{}
+	for i, module := range modules {
+		if i > 0 {
+			domain = append([]any{"|"}, domain...)
+		}
+		domain = append(domain, "&", []any{"module", "=", module}, []any{"name", "in", names[module]})
+	}
+	records, err := o.SearchRead(ctx, "ir.model.data", 0, 0, []string{"module", "name", "model", "res_id"}, domain)
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range records {
+		module, _ := rec["module"].(string)
+		name, _ := rec["name"].(string)
+		model, _ := rec["model"].(string)
+		id, _ := toFloat(rec["res_id"])
+		dataID, _ := toFloat(rec["id"])
+		refs[module+"."+name] = xmlidRecord{XMLRef: XMLRef{Model: model, ID: int(id)}, dataID: int(dataID)}
+	}
+	return refs, nil
+}
+
+// RefIDs returns the records the external ids refer to, looked up in
+// ir.model.data with a single call. External ids that do not exist are
+// absent from refs.
+func RefIDs(ctx context.Context, o Odoo, xmlids []string) (refs map[string]XMLRef, err error) {
+	records, err := resolveXMLIDs(ctx, o, xmlids)
+	if err != nil {
+		return nil, fmt.Errorf("xmlid lookup failed: %w", err)
+	}
+	refs = make(map[string]XMLRef, len(records))
+	for xmlid, rec := range records {
+		refs[xmlid] = rec.XMLRef
+	}
+	return refs, nil
+}
+
+// RefID returns the model and id of the record an external id refers to,
+// or an error wrapping ErrXMLIDNotFound.
+func RefID(ctx context.Context, o Odoo, xmlid string) (model string, id int, err error) {
+	refs, err := RefIDs(ctx, o, []string{xmlid})
+	if err != nil {
+		return "", 0, err
+	}
+	ref, ok := refs[xmlid]
+	if !ok {
+		return "", 0, fmt.Errorf("%w: %s", ErrXMLIDNotFound, xmlid)
+	}
+	return ref.Model, ref.ID, nil
+}
+
+// xmlidValues returns the values of the ir.model.data record of xmlid.
+// noupdate keeps module upgrades from touching the record.
+func xmlidValues(xmlid string, model string, id int) map[string]any {
+	module, name, _ := SplitXMLID(xmlid)
+	return map[string]any{"module": module, "name": name, "model": model, "res_id": id, "noupdate": true}
+}
+
+// createXMLIDs creates the ir.model.data records of the external ids of the
+// records of model with a single call.
+func createXMLIDs(ctx context.Context, o Odoo, model string, xmlids []string, ids []int) error {
+	if len(xmlids) == 0 {
+		return nil
+	}
+	vals := make([]any, len(xmlids))
+	for i, xmlid := range xmlids {
+		vals[i] = xmlidValues(xmlid, model, ids[i])
+	}
+	_, err := o.CallMethod(ctx, "ir.model.data", "create", nil, map[string]any{"vals_list": vals})
+	return err
+}
+
+// EnsureXMLIDs gives the records of model the external ids mapped to their
+// ids, creating the missing ones with a single call. It fails without
+// changes if an external id exists and refers to another record. Creating
+// external ids requires access rights on ir.model.data; their module should
+// not be an installed module, e.g. "__import__" or the integration name.
+func EnsureXMLIDs(ctx context.Context, o Odoo, model string, ids map[string]int) error {
+	xmlids := make([]string, 0, len(ids))
+	for xmlid := range ids {
+		xmlids = append(xmlids, xmlid)
+	}
+	sort.Strings(xmlids)
+	existing, err := resolveXMLIDs(ctx, o, xmlids)
+	if err != nil {
+		return fmt.Errorf("ensure xmlid failed: %w", err)
+	}
+	var missing []string
+	var missingIDs []int
+	for _, xmlid := range xmlids {
+		ref, ok := existing[xmlid]
+		switch {
+		case !ok:
+			missing = append(missing, xmlid)
+			missingIDs = append(missingIDs, ids[xmlid])
+		case ref.XMLRef != XMLRef{Model: model, ID: ids[xmlid]}:
+			return fmt.Errorf("ensure xmlid failed: %s refers to %s(%d), not %s(%d)",
+				xmlid, ref.Model, ref.ID, model, ids[xmlid])
+		}
+	}
+	if err := createXMLIDs(ctx, o, model, missing, missingIDs); err != nil {
+		return fmt.Errorf("ensure xmlid failed: %w", err)
+	}
+	return nil
+}
+
+// UpsertXMLIDs creates or updates the records of model identified by the
+// external ids with the corresponding values, and returns their ids in
+// order. The external ids are resolved with a single call, and the missing
+// records and their external ids are created with a call each; existing
+// records, archived ones included, are written one by one. An external id
+// left behind by a deleted record is moved to the record created in its
+// place.
+func UpsertXMLIDs(ctx context.Context, o Odoo, model string, xmlids []string, values []map[string]any) (ids []int, err error) {
+	if len(xmlids) != len(values) {
+		return nil, fmt.Errorf("upsert failed: %d external ids for %d records", len(xmlids), len(values))
+	}
+	seen := map[string]bool{}
+	for _, xmlid := range xmlids {
+		if seen[xmlid] {
+			return nil, fmt.Errorf("upsert failed: duplicate external id %s", xmlid)
+		}
+		seen[xmlid] = true
+	}
+	existing, err := resolveXMLIDs(ctx, o, xmlids)
+	if err != nil {
+		return nil, fmt.Errorf("upsert failed: %w", err)
+	}
+	var refIDs []int
+	for _, xmlid := range xmlids {
+		if ref, ok := existing[xmlid]; ok {
+			if ref.Model != model {
+				return nil, fmt.Errorf("upsert failed: %s refers to a %s record, not %s", xmlid, ref.Model, model)
+			}
+			refIDs = append(refIDs, ref.ID)
+		}
+	}
+	live := map[int]bool{}
+	if len(refIDs) > 0 {
+		// Archived records exist: only a search without active_test finds
+		// them.
+		result, err := o.CallMethod(ctx, model, "search", nil, map[string]any{
+			"domain":  []any{[]any{"id", "in", refIDs}},
+			"context": map[string]any{"active_test": false},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("upsert failed: %w", err)
+		}
+		found, err := parseIDList(result)
+		if err != nil {
+			return nil, fmt.Errorf("upsert failed: %w", err)
+		}
+		for _, id := range found {
+			live[id] = true
+		}
+	}
+
+	ids = make([]int, len(xmlids))
+	var create []int
+	for i, xmlid := range xmlids {
+		ref, ok := existing[xmlid]
+		if !ok || !live[ref.ID] {
+			create = append(create, i)
+			continue
+		}
+		if _, err := o.Write(ctx, model, ref.ID, values[i]); err != nil {
+			return nil, fmt.Errorf("upsert failed: %s: %w", xmlid, err)
+		}
+		ids[i] = ref.ID
+	}
+	if len(create) == 0 {
+		return ids, nil
+	}
+	vals := make([]any, len(create))
+	for j, i := range create {
+		vals[j] = values[i]
+	}
+	result, err := o.CallMethod(ctx, model, "create", nil, map[string]any{"vals_list": vals})
+	if err != nil {
+		return nil, fmt.Errorf("upsert failed: %w", err)
+	}
+	created, err := parseIDList(result)
+	if err != nil || len(created) != len(create) {
+		return nil, fmt.Errorf("upsert failed: unexpected create result %v", result)
+	}
+	var newXMLIDs []string
+	var newIDs []int
+	for j, i := range create {
+		ids[i] = created[j]
+		if ref, ok := existing[xmlids[i]]; ok {
+			// The record of the external id was deleted.
+			_, err := o.Write(ctx, "ir.model.data", ref.dataID, map[string]any{"res_id": created[j]})
+			if err != nil {
+				return nil, fmt.Errorf("upsert failed: %s: %w", xmlids[i], err)
+			}
+			continue
+		}
+		newXMLIDs = append(newXMLIDs, xmlids[i])
+		newIDs = append(newIDs, created[j])
+	}
+	if err := createXMLIDs(ctx, o, model, newXMLIDs, newIDs); err != nil {
+		return nil, fmt.Errorf("upsert failed: %w", err)
+	}
+	return ids, nil
+}
+
+// parseIDList converts the result of a create with a list of values, or of
+// search.
+func parseIDList(result any) ([]int, error) {
+	if id, ok := toFloat(result); ok {
+		return []int{int(id)}, nil
+	}
+	list, ok := result.([]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", result)
+	}
+	ids := make([]int, 0, len(list))
+	for _, v := range list {
+		id, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("unexpected id type %T in response", v)
+		}
+		ids = append(ids, int(id))
+	}
+	return ids, nil
+}
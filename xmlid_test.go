@@ -0,0 +1,185 @@
+package odoorpc_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ppreeper/odoorpc"
+	"github.com/ppreeper/odoorpc/odoorpctest"
+)
+
+func TestXMLIDs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name": {Type: "char", Required: true},
+		"ref":  {Type: "char"},
+	})
+	srv.DefineModel("res.country", map[string]odoorpctest.Field{"name": {Type: "char"}})
+	be, err := srv.Seed("res.country", map[string]any{"name": "Belgium"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Seed("ir.model.data", map[string]any{"module": "base", "name": "be", "model": "res.country", "res_id": be[0]}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, o := range interceptedClients(t, srv) {
+		model, id, err := o.RefID(ctx, "base.be")
+		if err != nil || model != "res.country" || id != be[0] {
+			t.Errorf("%s: RefID = %s, %d, %v", name, model, id, err)
+		}
+		if _, _, err := o.RefID(ctx, "base.nope"); !errors.Is(err, odoorpc.ErrXMLIDNotFound) {
+			t.Errorf("%s: RefID of a missing xmlid: %v", name, err)
+		}
+		if _, _, err := o.RefID(ctx, "nodot"); err == nil {
+			t.Errorf("%s: RefID of an invalid xmlid: expected an error", name)
+		}
+
+		// Upsert creates, then updates.
+		xmlid := "sync_" + name + ".acme"
+		id, err = o.Upsert(ctx, "res.partner", xmlid, map[string]any{"name": "Acme"})
+		if err != nil {
+			t.Fatalf("%s: Upsert: %v", name, err)
+		}
+		again, err := o.Upsert(ctx, "res.partner", xmlid, map[string]any{"ref": "A1"})
+		if err != nil || again != id {
+			t.Errorf("%s: second Upsert = %d, %v; want %d", name, again, err, id)
+		}
+		recs, err := o.Read(ctx, "res.partner", []int{id}, "name", "ref")
+		if err != nil || len(recs) != 1 || recs[0]["name"] != "Acme" || recs[0]["ref"] != "A1" {
+			t.Errorf("%s: upserted record %v, %v", name, recs, err)
+		}
+		if _, err := o.Upsert(ctx, "res.partner", "base.be", map[string]any{"name": "Belgium"}); err == nil || !strings.Contains(err.Error(), "res.country") {
+			t.Errorf("%s: Upsert of another model's xmlid: %v", name, err)
+		}
+
+		// EnsureXMLID is idempotent and refuses to move an xmlid.
+		other, err := o.Create(ctx, "res.partner", map[string]any{"name": "Globex"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range 2 {
+			if err := o.EnsureXMLID(ctx, "res.partner", other, "sync_"+name+".globex"); err != nil {
+				t.Errorf("%s: EnsureXMLID: %v", name, err)
+			}
+		}
+		if err := o.EnsureXMLID(ctx, "res.partner", id, "sync_"+name+".globex"); err == nil {
+			t.Errorf("%s: EnsureXMLID of a used xmlid: expected an error", name)
+		}
+
+		refs, err := o.RefIDs(ctx, []string{"sync_" + name + ".acme", "sync_" + name + ".globex", "base.be", "base.nope"})
+		want := map[string]odoorpc.XMLRef{
+			"sync_" + name + ".acme":   {Model: "res.partner", ID: id},
+			"sync_" + name + ".globex": {Model: "res.partner", ID: other},
+			"base.be":                  {Model: "res.country", ID: be[0]},
+		}
+		if err != nil || !reflect.DeepEqual(refs, want) {
+			t.Errorf("%s: RefIDs = %v, %v; want %v", name, refs, err, want)
+		}
+
+		// An xmlid of a deleted record moves to the new record.
+		if _, err := o.Unlink(ctx, "res.partner", []int{id}); err != nil {
+			t.Fatal(err)
+		}
+		recreated, err := o.Upsert(ctx, "res.partner", xmlid, map[string]any{"name": "Acme"})
+		if err != nil || recreated == id {
+			t.Errorf("%s: Upsert after unlink = %d, %v", name, recreated, err)
+		}
+		if _, ref, err := o.RefID(ctx, xmlid); err != nil || ref != recreated {
+			t.Errorf("%s: RefID after recreation = %d, %v; want %d", name, ref, err, recreated)
+		}
+	}
+}
+
+func TestUpsertXMLIDs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{"name": {Type: "char", Required: true}})
+	o := &countingOdoo{Odoo: interceptedClients(t, srv)[odoorpc.TransportJSONRPC]}
+
+	xmlids := []string{"crm.p1", "erp.p2", "crm.p3"}
+	values := []map[string]any{{"name": "One"}, {"name": "Two"}, {"name": "Three"}}
+	ids, err := odoorpc.UpsertXMLIDs(ctx, o, "res.partner", xmlids, values)
+	if err != nil || len(ids) != 3 {
+		t.Fatalf("UpsertXMLIDs = %v, %v", ids, err)
+	}
+	// The records and their external ids are created with a call each.
+	if n := o.calls.Load(); n != 2 {
+		t.Errorf("%d create calls, want 2", n)
+	}
+	for i, id := range ids {
+		recs, _ := o.Read(ctx, "res.partner", []int{id}, "name")
+		if len(recs) != 1 || recs[0]["name"] != values[i]["name"] {
+			t.Errorf("record %d: %v", id, recs)
+		}
+	}
+
+	values[1] = map[string]any{"name": "Two bis"}
+	again, err := odoorpc.UpsertXMLIDs(ctx, o, "res.partner", append(xmlids, "crm.p4"), append(values, map[string]any{"name": "Four"}))
+	if err != nil || !reflect.DeepEqual(again[:3], ids) || again[3] == 0 {
+		t.Errorf("second UpsertXMLIDs = %v, %v; want %v and a new id", again, err, ids)
+	}
+	if names := partnerNames(srv); names != "One,Two bis,Three,Four" {
+		t.Errorf("records after upsert: %s", names)
+	}
+
+	if _, err := odoorpc.UpsertXMLIDs(ctx, o, "res.partner", []string{"crm.p1", "crm.p1"}, values[:2]); err == nil {
+		t.Error("duplicate xmlids: expected an error")
+	}
+	if _, err := odoorpc.UpsertXMLIDs(ctx, o, "res.partner", xmlids, values[:1]); err == nil {
+		t.Error("mismatched lengths: expected an error")
+	}
+	if err := odoorpc.EnsureXMLIDs(ctx, o, "res.partner", map[string]int{"crm.p1": ids[1]}); err == nil {
+		t.Error("EnsureXMLIDs moving an xmlid: expected an error")
+	}
+}
+
+func TestUpsertArchived(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	srv := odoorpctest.NewServer()
+	defer srv.Close()
+	srv.DefineModel("res.partner", map[string]odoorpctest.Field{
+		"name":   {Type: "char", Required: true},
+		"active": {Type: "boolean"},
+	})
+	for name, o := range interceptedClients(t, srv) {
+		xmlid := "sync_" + name + ".old"
+		id, err := o.Upsert(ctx, "res.partner", xmlid, map[string]any{"name": "Old"})
+		if err != nil {
+			t.Fatalf("%s: Upsert: %v", name, err)
+		}
+		if _, err := o.Write(ctx, "res.partner", id, map[string]any{"active": false}); err != nil {
+			t.Fatal(err)
+		}
+		// The archived record is updated, not replaced.
+		again, err := o.Upsert(ctx, "res.partner", xmlid, map[string]any{"name": "Older"})
+		if err != nil || again != id {
+			t.Errorf("%s: Upsert of an archived record = %d, %v; want %d", name, again, err, id)
+		}
+		if _, ref, err := o.RefID(ctx, xmlid); err != nil || ref != id {
+			t.Errorf("%s: external id refers to %d, %v; want %d", name, ref, err, id)
+		}
+	}
+	if names := partnerNames(srv); names != "Older,Older,Older" {
+		t.Errorf("records after upsert: %s", names)
+	}
+}
+
+// partnerNames returns the names of the partners of srv in id order.
+func partnerNames(srv *odoorpctest.Server) string {
+	var names []string
+	for _, rec := range srv.Records("res.partner") {
+		name, _ := rec["name"].(string)
+		names = append(names, name)
+	}
+	return strings.Join(names, ",")
+}
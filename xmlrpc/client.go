@@ -43,6 +43,10 @@ type clientCodec struct {
 
 	response Response
 
+	// fault holds the last fault decoded by ReadResponseHeader so CallContext
+	// can return it as a FaultError rather than the flattened rpc.ServerError.
+	fault *FaultError
+
 	// ctx is the context for the current in-flight request. Access is
 	// serialised by Client.callMu — no additional locking is needed here.
 	ctx context.Context
@@ -114,6 +118,11 @@ func (codec *clientCodec) ReadResponseHeader(response *rpc.Response) (err error)
 
 	resp := Response(body)
 	if err := resp.Err(); err != nil {
+		if fault, ok := err.(FaultError); ok {
+			codec.mutex.Lock()
+			codec.fault = &fault
+			codec.mutex.Unlock()
+		}
 		response.Error = err.Error()
 		return nil
 	}
@@ -131,7 +140,7 @@ func (codec *clientCodec) ReadResponseBody(v interface{}) (err error) {
 }
 
 func (codec *clientCodec) Close() error {
-	if transport, ok := codec.httpClient.Transport.(*http.Transport); ok {
+	if transport, ok := codec.httpClient.Transport.(interface{ CloseIdleConnections() }); ok {
 		transport.CloseIdleConnections()
 	}
 
@@ -152,11 +161,24 @@ func (c *Client) Call(serviceMethod string, args interface{}, reply interface{})
 //
 // callMu serialises concurrent calls so that the context stored on the codec
 // is never overwritten by a racing goroutine before WriteRequest reads it.
+//
+// Server faults are returned as FaultError so callers can inspect the fault
+// code and parse the fault string with FaultError.Traceback.
 func (c *Client) CallContext(ctx context.Context, serviceMethod string, args interface{}, reply interface{}) error {
 	c.callMu.Lock()
+	defer c.callMu.Unlock()
 	c.codec.ctx = ctx
 	err := c.Client.Call(serviceMethod, args, reply)
-	c.callMu.Unlock()
+
+	c.codec.mutex.Lock()
+	fault := c.codec.fault
+	c.codec.fault = nil
+	c.codec.mutex.Unlock()
+
+	var serverErr rpc.ServerError
+	if fault != nil && errors.As(err, &serverErr) {
+		return *fault
+	}
 	return err
 }
 
@@ -165,8 +187,16 @@ func NewClient(requrl string, transport http.RoundTripper) (*Client, error) {
 	if transport == nil {
 		transport = http.DefaultTransport
 	}
+	return NewClientWithHTTPClient(requrl, &http.Client{Transport: transport})
+}
 
-	httpClient := &http.Client{Transport: transport}
+// NewClientWithHTTPClient is like NewClient but sends requests with
+// httpClient, e.g. one with a proxy, timeout or custom dialer. Cookies are
+// kept by the Client, not by httpClient.Jar.
+func NewClientWithHTTPClient(requrl string, httpClient *http.Client) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
 
 	jar, err := cookiejar.New(nil)
 	if err != nil {
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"reflect"
 )
 
 func NewRequest(ctx context.Context, url string, method string, args interface{}) (*http.Request, error) {
@@ -60,3 +63,46 @@ func EncodeMethodCall(method string, args ...interface{}) ([]byte, error) {
 
 	return b.Bytes(), nil
 }
+
+// DecodeMethodCall parses a methodCall document and returns the method name
+// and its parameters decoded into generic values. It is the server-side
+// counterpart of EncodeMethodCall.
+func DecodeMethodCall(data []byte) (method string, params []interface{}, err error) {
+	dec := &decoder{xml.NewDecoder(bytes.NewReader(data))}
+	if CharsetReader != nil {
+		dec.CharsetReader = CharsetReader
+	}
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		t, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch t.Name.Local {
+		case "methodName":
+			name, err := dec.readCharData()
+			if err != nil {
+				return "", nil, err
+			}
+			method = string(name)
+		case "value":
+			var v interface{}
+			if err := dec.decodeValue(reflect.ValueOf(&v).Elem()); err != nil {
+				return "", nil, err
+			}
+			params = append(params, v)
+		}
+	}
+
+	if method == "" {
+		return "", nil, errors.New("DecodeMethodCall: missing methodName")
+	}
+	return method, params, nil
+}
@@ -172,3 +172,52 @@ func TestEncodeMethodCallEscapesMethodName(t *testing.T) {
 		t.Errorf("expected escaped method name in output, got:\n%s", body)
 	}
 }
+
+// ─── DecodeMethodCall ─────────────────────────────────────────────────────────
+
+func TestDecodeMethodCallRoundTrip(t *testing.T) {
+	t.Parallel()
+	b, err := EncodeMethodCall("execute_kw", "db", 2, []any{"res.partner", true}, map[string]any{"limit": 5})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	method, params, err := DecodeMethodCall(b)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if method != "execute_kw" {
+		t.Errorf("method: got %q", method)
+	}
+	if len(params) != 4 {
+		t.Fatalf("params: got %d, want 4: %#v", len(params), params)
+	}
+	if params[0] != "db" || params[1] != int64(2) {
+		t.Errorf("scalar params: got %#v", params[:2])
+	}
+	arr, ok := params[2].([]any)
+	if !ok || len(arr) != 2 || arr[0] != "res.partner" || arr[1] != true {
+		t.Errorf("array param: got %#v", params[2])
+	}
+	kw, ok := params[3].(map[string]any)
+	if !ok || kw["limit"] != int64(5) {
+		t.Errorf("struct param: got %#v", params[3])
+	}
+}
+
+func TestDecodeMethodCallNoParams(t *testing.T) {
+	t.Parallel()
+	method, params, err := DecodeMethodCall([]byte(`<?xml version="1.0"?><methodCall><methodName>version</methodName></methodCall>`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if method != "version" || len(params) != 0 {
+		t.Errorf("got method=%q params=%v", method, params)
+	}
+}
+
+func TestDecodeMethodCallMissingName(t *testing.T) {
+	t.Parallel()
+	if _, _, err := DecodeMethodCall([]byte(`<methodCall><params></params></methodCall>`)); err == nil {
+		t.Fatal("expected error for missing methodName, got nil")
+	}
+}
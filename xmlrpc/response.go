@@ -1,6 +1,7 @@
 package xmlrpc
 
 import (
+	"bytes"
 	"fmt"
 	"regexp"
 )
@@ -38,3 +39,37 @@ func (r Response) Unmarshal(v interface{}) error {
 
 	return nil
 }
+
+// EncodeMethodResponse encodes v as a methodResponse document. It is the
+// server-side counterpart of Response.Unmarshal.
+func EncodeMethodResponse(v interface{}) ([]byte, error) {
+	p, err := marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(p) == 0 {
+		p = []byte("<value/>")
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	b.WriteString("<methodResponse><params><param>")
+	b.Write(p)
+	b.WriteString("</param></params></methodResponse>")
+	return b.Bytes(), nil
+}
+
+// EncodeFault encodes fault as a methodResponse fault document.
+func EncodeFault(fault FaultError) ([]byte, error) {
+	p, err := marshal(fault)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+	b.WriteString("<methodResponse><fault>")
+	b.Write(p)
+	b.WriteString("</fault></methodResponse>")
+	return b.Bytes(), nil
+}
@@ -164,3 +164,39 @@ func TestResponseUnmarshalInvalidXML(t *testing.T) {
 		t.Fatal("expected error for invalid XML, got nil")
 	}
 }
+
+// ─── EncodeMethodResponse / EncodeFault ──────────────────────────────────────
+
+func TestEncodeMethodResponseRoundTrip(t *testing.T) {
+	t.Parallel()
+	b, err := EncodeMethodResponse([]any{int64(1), "two"})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	r := Response(b)
+	if err := r.Err(); err != nil {
+		t.Fatalf("unexpected fault: %v", err)
+	}
+	var v []any
+	if err := r.Unmarshal(&v); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(v) != 2 || v[0] != int64(1) || v[1] != "two" {
+		t.Errorf("got %#v", v)
+	}
+}
+
+func TestEncodeFaultRoundTrip(t *testing.T) {
+	t.Parallel()
+	b, err := EncodeFault(FaultError{Code: 3, String: "Access <Denied>"})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	fe, ok := Response(b).Err().(FaultError)
+	if !ok {
+		t.Fatalf("expected FaultError, got %T", Response(b).Err())
+	}
+	if fe.Code != 3 || fe.String != "Access <Denied>" {
+		t.Errorf("got %+v", fe)
+	}
+}
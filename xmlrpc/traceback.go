@@ -0,0 +1,87 @@
+package xmlrpc
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tracebackHeader opens every Python traceback block.
+const tracebackHeader = "Traceback (most recent call last):"
+
+var (
+	frameRx     = regexp.MustCompile(`^\s+File "(.*)", line (\d+), in (.+)$`)
+	exceptionRx = regexp.MustCompile(`^([A-Za-z_][\w.]*)(?::\s?(.*))?$`)
+	caretRx     = regexp.MustCompile(`^\s*[\^~]+\s*$`)
+)
+
+// Frame is a single entry of a Python traceback.
+type Frame struct {
+	File     string
+	Line     int
+	Function string
+	Source   string
+}
+
+// Traceback is the parsed form of a Python traceback as sent by Python
+// XML-RPC servers (Odoo included) in the faultString of a fault response.
+type Traceback struct {
+	// Exception is the fully qualified exception class, e.g.
+	// "odoo.exceptions.AccessError". It is empty when the string does not end
+	// with a recognisable exception line.
+	Exception string
+	// Message is the exception message. It may span several lines.
+	Message string
+	// Frames lists the stack frames, outermost call first.
+	Frames []Frame
+}
+
+// ParseTraceback parses a Python traceback. When the string contains chained
+// exceptions only the last traceback block is returned, as that is the one
+// that reached the client. A string without a traceback header yields a
+// Traceback with no frames whose Message is the trimmed input.
+func ParseTraceback(s string) Traceback {
+	s = strings.TrimSpace(s)
+	idx := strings.LastIndex(s, tracebackHeader)
+	if idx < 0 {
+		return Traceback{Message: s}
+	}
+
+	var tb Traceback
+	lines := strings.Split(s[idx+len(tracebackHeader):], "\n")
+	i := 0
+	for ; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		if strings.TrimSpace(line) == "" || caretRx.MatchString(line) {
+			continue
+		}
+		if m := frameRx.FindStringSubmatch(line); m != nil {
+			n, _ := strconv.Atoi(m[2])
+			tb.Frames = append(tb.Frames, Frame{File: m[1], Line: n, Function: m[3]})
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			// Source line belonging to the previous frame.
+			if n := len(tb.Frames); n > 0 && tb.Frames[n-1].Source == "" {
+				tb.Frames[n-1].Source = strings.TrimSpace(line)
+			}
+			continue
+		}
+		break
+	}
+
+	rest := strings.TrimSpace(strings.Join(lines[i:], "\n"))
+	first, more, _ := strings.Cut(rest, "\n")
+	if m := exceptionRx.FindStringSubmatch(first); m != nil {
+		tb.Exception = m[1]
+		tb.Message = strings.TrimSpace(m[2] + "\n" + more)
+	} else {
+		tb.Message = rest
+	}
+	return tb
+}
+
+// Traceback parses the fault string as a Python traceback.
+func (e FaultError) Traceback() Traceback {
+	return ParseTraceback(e.String)
+}
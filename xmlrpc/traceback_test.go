@@ -0,0 +1,96 @@
+package xmlrpc
+
+import (
+	"testing"
+)
+
+const odooTraceback = `Traceback (most recent call last):
+  File "/opt/odoo/odoo/addons/base/controllers/rpc.py", line 160, in xmlrpc_2
+    response = self._xmlrpc(service)
+  File "/opt/odoo/odoo/models.py", line 3684, in check_access_rights
+    raise AccessError(_("You are not allowed to access records."))
+    ^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^
+odoo.exceptions.AccessError: You are not allowed to access 'Contact' (res.partner) records.
+
+This operation is allowed for the following groups:
+	- User types/Internal User
+`
+
+func TestParseTracebackFrames(t *testing.T) {
+	t.Parallel()
+	tb := ParseTraceback(odooTraceback)
+	if tb.Exception != "odoo.exceptions.AccessError" {
+		t.Errorf("Exception: got %q", tb.Exception)
+	}
+	if len(tb.Frames) != 2 {
+		t.Fatalf("Frames: got %d, want 2: %+v", len(tb.Frames), tb.Frames)
+	}
+	want := Frame{
+		File:     "/opt/odoo/odoo/models.py",
+		Line:     3684,
+		Function: "check_access_rights",
+		Source:   `raise AccessError(_("You are not allowed to access records."))`,
+	}
+	if tb.Frames[1] != want {
+		t.Errorf("Frames[1]: got %+v, want %+v", tb.Frames[1], want)
+	}
+	wantMsg := "You are not allowed to access 'Contact' (res.partner) records.\n\n" +
+		"This operation is allowed for the following groups:\n\t- User types/Internal User"
+	if tb.Message != wantMsg {
+		t.Errorf("Message: got %q, want %q", tb.Message, wantMsg)
+	}
+}
+
+func TestParseTracebackChained(t *testing.T) {
+	t.Parallel()
+	s := `Traceback (most recent call last):
+  File "a.py", line 1, in inner
+    x = {}["k"]
+KeyError: 'k'
+
+During handling of the above exception, another exception occurred:
+
+Traceback (most recent call last):
+  File "b.py", line 2, in outer
+    raise ValueError("bad")
+ValueError: bad
+`
+	tb := ParseTraceback(s)
+	if tb.Exception != "ValueError" || tb.Message != "bad" {
+		t.Errorf("got %q: %q, want ValueError: bad", tb.Exception, tb.Message)
+	}
+	if len(tb.Frames) != 1 || tb.Frames[0].File != "b.py" {
+		t.Errorf("expected only the last block's frame, got %+v", tb.Frames)
+	}
+}
+
+func TestParseTracebackPlainMessage(t *testing.T) {
+	t.Parallel()
+	tb := ParseTraceback("  The record does not exist  ")
+	if tb.Exception != "" || len(tb.Frames) != 0 {
+		t.Errorf("expected no exception or frames, got %+v", tb)
+	}
+	if tb.Message != "The record does not exist" {
+		t.Errorf("Message: got %q", tb.Message)
+	}
+}
+
+func TestParseTracebackExceptionWithoutMessage(t *testing.T) {
+	t.Parallel()
+	tb := ParseTraceback("Traceback (most recent call last):\n  File \"x.py\", line 3, in f\n    g()\nodoo.exceptions.MissingError\n")
+	if tb.Exception != "odoo.exceptions.MissingError" || tb.Message != "" {
+		t.Errorf("got %+v", tb)
+	}
+}
+
+func TestFaultErrorTraceback(t *testing.T) {
+	t.Parallel()
+	r := faultResponse(1, odooTraceback)
+	fe, ok := r.Err().(FaultError)
+	if !ok {
+		t.Fatalf("expected FaultError, got %T", r.Err())
+	}
+	if got := fe.Traceback().Exception; got != "odoo.exceptions.AccessError" {
+		t.Errorf("Exception: got %q", got)
+	}
+}